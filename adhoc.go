@@ -0,0 +1,104 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// WithoutPrepare makes the index run every statement as an ad hoc
+// query, instead of preparing the statements when it is created.
+// The index then keeps no statements prepared on the server, whose
+// caches they fill when many tables with very wide signatures are open,
+// and which some connection poolers do not support.
+// A statement with more parameters than the database accepts, as for
+// very wide signatures, has its integer arguments, such as the hash
+// values, written into the statement as literals instead.
+// The trade-off is that each statement is parsed and planned again
+// every time it is run.
+func WithoutPrepare() Option {
+	return func(lsh *SqlLsh) {
+		lsh.adHoc = true
+	}
+}
+
+// exec runs a statement inside tx.
+// The prepared statement stmt is used unless it is nil, otherwise the
+// query string is run.
+func (lsh *SqlLsh) exec(tx *sql.Tx, stmt *sql.Stmt, query string, args ...interface{}) (sql.Result, error) {
+	if stmt == nil {
+		query, args = lsh.inline(query, args)
+		return tx.Exec(query, args...)
+	}
+	return tx.Stmt(stmt).Exec(args...)
+}
+
+// inline returns query with its integer arguments written as literals
+// if it has more arguments than the database accepts, and the
+// arguments left, whose placeholders are numbered again.
+// A placeholder inside a quoted string is left as is.
+func (lsh *SqlLsh) inline(query string, args []interface{}) (string, []interface{}) {
+	if lsh.dialect.maxParams == 0 || len(args) <= lsh.dialect.maxParams {
+		return query, args
+	}
+	// The placeholders are either all the same, and taken in order, or
+	// numbered from 1 after a prefix
+	numbered := lsh.dialect.varFmt(0) != lsh.dialect.varFmt(1)
+	prefix := strings.TrimSuffix(lsh.dialect.varFmt(0), "1")
+	var b strings.Builder
+	var left []interface{}
+	quoted := false
+	n := 0
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		if c == '\'' {
+			quoted = !quoted
+		}
+		arg, end := -1, i+1
+		switch {
+		case quoted:
+		case !numbered && c == '?':
+			arg = n
+			n++
+		case numbered && strings.HasPrefix(query[i:], prefix):
+			end = i + len(prefix)
+			for end < len(query) && query[end] >= '0' && query[end] <= '9' {
+				end++
+			}
+			if v, err := strconv.Atoi(query[i+len(prefix) : end]); err == nil {
+				arg = v - 1
+			}
+		}
+		if arg < 0 || arg >= len(args) {
+			b.WriteByte(c)
+			continue
+		}
+		if lit, ok := intLiteral(args[arg]); ok {
+			b.WriteString(lit)
+		} else {
+			b.WriteString(lsh.dialect.varFmt(len(left)))
+			left = append(left, args[arg])
+		}
+		i = end - 1
+	}
+	return b.String(), left
+}
+
+// intLiteral returns the SQL literal of v if it is an integer.
+func intLiteral(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case int:
+		return strconv.FormatInt(int64(v), 10), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case int32:
+		return strconv.FormatInt(int64(v), 10), true
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), true
+	}
+	return "", false
+}
@@ -0,0 +1,137 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func Test_WithoutPrepare(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(16, 32, "lshtable", db,
+		WithoutPrepare(), WithSoftDelete(), WithInsertTime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(10, 512)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	found, err := lsh.QueryIDs(sigs[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0] != 3 {
+		t.Errorf("Expected [3], got %v", found)
+	}
+	if err := lsh.Delete(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Insert(3, sigs[4]); err != nil {
+		t.Fatal(err)
+	}
+	found, err = lsh.QueryIDs(sigs[4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 {
+		t.Errorf("Expected 2 IDs, got %v", found)
+	}
+	n, err := lsh.ExpireBefore(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 10 {
+		t.Errorf("Expected 10 entries expired, got %d", n)
+	}
+	removeTempFile(t, f)
+}
+
+func Test_WithoutPrepareWide(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// More hash values than SQLite accepts parameters in a statement
+	lsh, err := NewSqliteLsh(10, 100, "lshtable", db, WithoutPrepare())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lsh.k*lsh.l <= sqliteDialect.maxParams {
+		t.Fatalf("Expected more than %d hash values", sqliteDialect.maxParams)
+	}
+	sigs := randomSigs(2, lsh.k*lsh.l)
+	for i, sig := range sigs {
+		if err := lsh.Insert(i, sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ids, err := lsh.Where("'a' = ?", "a").QueryIDs(sigs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("Expected ID 1, got %v", ids)
+	}
+	if err := lsh.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+	ids, err = lsh.QueryIDs(sigs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 0 {
+		t.Errorf("Expected no IDs, got %v", ids)
+	}
+	// The hash values are written as literals
+	rec := NewRecorder()
+	defer rec.Close()
+	wide, err := NewOracleLsh(11, 100, "lshtable", rec, WithoutPrepare())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Reset()
+	if err := wide.Insert(1, randomSigs(1, 1100)[0]); err != nil {
+		t.Fatal(err)
+	}
+	stmts := rec.Statements()
+	if len(stmts) != 1 {
+		t.Fatalf("Expected one insert, got %d statements", len(stmts))
+	}
+	if len(stmts[0].Args) != 0 {
+		t.Errorf("Expected no arguments, got %d", len(stmts[0].Args))
+	}
+}
+
+func Test_Inline(t *testing.T) {
+	lsh := &SqlLsh{dialect: postgresDialect}
+	lsh.dialect.maxParams = 2
+	query, args := lsh.inline("SELECT id FROM t WHERE hv_0 = $1 AND hv_1 = $2 AND c = '$3' AND d = $3",
+		[]interface{}{uint(7), 8, "x"})
+	expected := "SELECT id FROM t WHERE hv_0 = 7 AND hv_1 = 8 AND c = '$3' AND d = $1"
+	if query != expected {
+		t.Errorf("Expected %q, got %q", expected, query)
+	}
+	if len(args) != 1 || args[0] != "x" {
+		t.Errorf("Expected the string argument to be left, got %v", args)
+	}
+	// Statements within the limit are unchanged
+	query, args = lsh.inline("SELECT $1", []interface{}{1})
+	if query != "SELECT $1" || len(args) != 1 {
+		t.Errorf("Expected the statement to be unchanged, got %q", query)
+	}
+}
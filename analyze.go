@@ -0,0 +1,22 @@
+package sqllsh
+
+import "fmt"
+
+// Analyze refreshes the statistics the database uses for planning
+// queries on the table.
+// It should be run after loading many entries or building the indexes,
+// as planners can pick slow plans for Query on stale statistics.
+func (lsh *SqlLsh) Analyze() error {
+	if lsh.dialect.analyzeFmt == "" {
+		return ErrUnsupported
+	}
+	_, err := lsh.db.Exec(fmt.Sprintf(lsh.dialect.analyzeFmt, lsh.tableName))
+	return wrapErr("analyze", err)
+}
+
+// WithAutoAnalyze makes Index run Analyze after building the indexes.
+func WithAutoAnalyze() Option {
+	return func(lsh *SqlLsh) {
+		lsh.autoAnalyze = true
+	}
+}
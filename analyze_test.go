@@ -0,0 +1,37 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_Analyze(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db, WithAutoAnalyze())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(100, 6)
+	for i, sig := range sigs {
+		lsh.Insert(i, sig)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	err = db.QueryRow("SELECT COUNT(*) FROM sqlite_stat1 WHERE tbl = 'lshtable';").Scan(&n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("Expected statistics of 3 indexes, got %d", n)
+	}
+	if err := lsh.Analyze(); err != nil {
+		t.Error(err)
+	}
+	removeTempFile(t, f)
+}
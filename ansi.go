@@ -0,0 +1,88 @@
+package sqllsh
+
+import (
+	"fmt"
+)
+
+// Placeholder is the style of the query parameters of a driver.
+type Placeholder int
+
+const (
+	// PlaceholderQuestion is ?, as in ODBC and JDBC.
+	PlaceholderQuestion Placeholder = iota
+	// PlaceholderDollar is $1, $2, and so on.
+	PlaceholderDollar
+	// PlaceholderColon is :1, :2, and so on.
+	PlaceholderColon
+	// PlaceholderAt is @p1, @p2, and so on.
+	PlaceholderAt
+)
+
+// placeholderFmts are the placeholder formatters of each style.
+var placeholderFmts = map[Placeholder]func(int) string{
+	PlaceholderQuestion: func(i int) string { return "?" },
+	PlaceholderDollar:   func(i int) string { return fmt.Sprintf("$%d", i+1) },
+	PlaceholderColon:    func(i int) string { return fmt.Sprintf(":%d", i+1) },
+	PlaceholderAt:       func(i int) string { return fmt.Sprintf("@p%d", i+1) },
+}
+
+// WithPlaceholder sets the style of the query parameters of an index
+// created with NewAnsiLsh, which uses PlaceholderQuestion by default.
+// The constructor returns ErrUnsupported for other databases, which
+// already use the style of their drivers.
+func WithPlaceholder(p Placeholder) Option {
+	return func(lsh *SqlLsh) {
+		lsh.placeholder = &p
+	}
+}
+
+var ansiDialect = dialect{
+	varFmt:         placeholderFmts[PlaceholderQuestion],
+	intType:        "BIGINT",
+	blobType:       "BLOB",
+	limitFmt:       " FETCH FIRST %d ROWS ONLY",
+	createIndexFmt: "CREATE INDEX %s ON %s",
+	dropIndexFmt:   "DROP INDEX %[1]s",
+	indexMethods:   map[IndexType]string{IndexBTree: ""},
+	plan:           PlanOr,
+	probeTables:    true,
+	placeholders:   true,
+}
+
+// NewAnsiLsh creates a new LSH index using only standard SQL, for
+// databases without a constructor of their own, so that any
+// database/sql driver has a reasonable chance of working.
+// The tables are created without IF NOT EXISTS after checking that
+// they do not exist, and the query parameters use the style set with
+// WithPlaceholder.
+// Only ConflictError is supported, Analyze and ExplainQuery are not
+// supported, and Validate does not check the indexes.
+// BulkLoad and TypedLsh with string IDs need CREATE TABLE IF NOT EXISTS
+// to be supported.
+// The caller is responsible for closing the database connection
+// object.
+func NewAnsiLsh(k, l int, tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	lsh, err := newSqlLsh(k, l, tableName, db, ansiDialect, opts)
+	return lsh, err
+}
+
+// OpenAnsiLsh opens an existing LSH index created with NewAnsiLsh, with
+// its recorded parameters, like OpenSqliteLsh.
+func OpenAnsiLsh(tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	return openSqlLsh(tableName, db, ansiDialect, opts)
+}
+
+// tableExists returns whether the table name can be read.
+// In dry-run mode, the tables are assumed not to exist so their DDL
+// is recorded.
+func (lsh *SqlLsh) tableExists(name string) bool {
+	if lsh.dryRun {
+		return false
+	}
+	rows, err := lsh.db.Query(fmt.Sprintf("SELECT * FROM %s WHERE 1 = 0", name))
+	if err != nil {
+		return false
+	}
+	rows.Close()
+	return true
+}
@@ -0,0 +1,66 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func Test_AnsiLsh(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewAnsiLsh(2, 3, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(100, 6)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	// The existing tables are found without IF NOT EXISTS
+	lsh, err = NewAnsiLsh(2, 3, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err := lsh.QueryIDs(sigs[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsID(found, 3) {
+		t.Errorf("Expected 3 in %v", found)
+	}
+	if err := lsh.DropIndexes(); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_AnsiPlaceholder(t *testing.T) {
+	lsh := &SqlLsh{k: 2, l: 3, tableName: "lshtable", dialect: ansiDialect}
+	lsh.dialect.varFmt = placeholderFmts[PlaceholderAt]
+	if s := lsh.insertStr(); !strings.Contains(s, "@p7") {
+		t.Errorf("Expected @p7 in %s", s)
+	}
+	if s := lsh.createTableStr(); strings.Contains(s, "IF NOT EXISTS") {
+		t.Errorf("Expected no IF NOT EXISTS in %s", s)
+	}
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := NewSqliteLsh(2, 3, "lshtable", db, WithPlaceholder(PlaceholderAt)); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package sqllsh
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+)
+
+// driverDatabases maps the import paths of database/sql drivers to the
+// database they connect to.
+var driverDatabases = map[string]string{
+	"github.com/mattn/go-sqlite3":          "sqlite",
+	"modernc.org/sqlite":                   "sqlite",
+	"github.com/lib/pq":                    "postgres",
+	"github.com/jackc/pgx/stdlib":          "postgres",
+	"github.com/jackc/pgx/v4/stdlib":       "postgres",
+	"github.com/jackc/pgx/v5/stdlib":       "postgres",
+	"github.com/marcboeker/go-duckdb":      "duckdb",
+	"github.com/marcboeker/go-duckdb/v2":   "duckdb",
+	"github.com/go-sql-driver/mysql":       "mysql",
+	"github.com/godror/godror":             "oracle",
+	"github.com/sijms/go-ora/v2":           "oracle",
+	"github.com/googleapis/go-sql-spanner": "spanner",
+	"github.com/snowflakedb/gosnowflake":   "snowflake",
+}
+
+// NewAutoLsh creates a new LSH index on the database of db, picking the
+// dialect from the driver of db, so that applications supporting
+// several databases need only one constructor.
+// For the MySQL and PostgreSQL protocols, the server version tells
+// MariaDB, TiDB, Vitess, Redshift and YugabyteDB apart.
+// For other drivers the standard SQL of NewAnsiLsh is used.
+// With a Recorder, the dialect of its Driver is used, taking MySQL and
+// PostgreSQL as such since no server answers the version query.
+// The caller is responsible for closing the database connection
+// object.
+func NewAutoLsh(k, l int, tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	d, err := detectDialect(db, &opts)
+	if err != nil {
+		return nil, err
+	}
+	return newSqlLsh(k, l, tableName, db, d, opts)
+}
+
+// detectDialect returns the dialect of the database of db, adding to
+// opts the options it needs.
+func detectDialect(db DB, opts *[]Option) (dialect, error) {
+	var pkg string
+	if rec, ok := db.(*Recorder); ok {
+		pkg = rec.Driver
+	} else {
+		t, err := driverType(db)
+		if err != nil {
+			return dialect{}, err
+		}
+		pkg = t.PkgPath()
+	}
+	switch driverDatabases[pkg] {
+	case "sqlite":
+		return sqliteDialect, nil
+	case "postgres":
+		version := serverVersion(db, "SELECT version()")
+		switch {
+		case strings.Contains(version, "Redshift"):
+			return redshiftDialect, nil
+		case strings.Contains(version, "-YB-"):
+			*opts = append([]Option{WithPostgresVariant(PostgresYugabyte)}, *opts...)
+		}
+		return postgresDialect, nil
+	case "duckdb":
+		return duckdbDialect, nil
+	case "mysql":
+		version := serverVersion(db, "SELECT VERSION()")
+		switch {
+		case strings.Contains(version, "MariaDB"):
+			return mariadbDialect, nil
+		case strings.Contains(version, "TiDB"):
+			return tidbDialect, nil
+		case strings.Contains(version, "Vitess"):
+			return vitessDialect, nil
+		}
+		return mysqlDialect, nil
+	case "oracle":
+		return oracleDialect, nil
+	case "spanner":
+		return spannerDialect, nil
+	case "snowflake":
+		return snowflakeDialect, nil
+	}
+	return ansiDialect, nil
+}
+
+// driverType returns the type of the driver of db, or of its driver
+// connection for a *sql.Conn.
+// The driver of a *sql.Tx cannot be found, so ErrUnsupported is
+// returned.
+func driverType(db DB) (reflect.Type, error) {
+	var t reflect.Type
+	switch db := db.(type) {
+	case *sql.DB:
+		t = reflect.TypeOf(db.Driver())
+	case *sql.Conn:
+		if err := db.Raw(func(driverConn interface{}) error {
+			t = reflect.TypeOf(driverConn)
+			return nil
+		}); err != nil {
+			return nil, wrapErr("open", err)
+		}
+	default:
+		return nil, ErrUnsupported
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t, nil
+}
+
+// serverVersion returns the version string of the database, or an
+// empty string if query fails.
+func serverVersion(db DB, query string) string {
+	var version string
+	if err := db.QueryRowContext(context.Background(), query).Scan(&version); err != nil {
+		return ""
+	}
+	return version
+}
@@ -0,0 +1,72 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+type unknownDriver struct{}
+
+func (unknownDriver) Open(name string) (driver.Conn, error) {
+	return nil, errors.New("not a database")
+}
+
+func init() {
+	sql.Register("sqllsh-unknown", unknownDriver{})
+}
+
+func Test_NewAutoLsh(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	for _, name := range []string{"sqlite3", "sqlite"} {
+		db, err := sql.Open(name, f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		lsh, err := NewAutoLsh(2, 3, "lshtable", db)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if lsh.dialect.indexesQuery != sqliteDialect.indexesQuery {
+			t.Errorf("%s: expected the Sqlite dialect", name)
+		}
+		db.Close()
+	}
+	db, err := sql.Open("sqllsh-unknown", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Unknown drivers fall back to standard SQL, and fail to connect here
+	if _, err := NewAutoLsh(2, 3, "lshtable", db); err == nil || errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected a connection error for an unknown driver, got %v", err)
+	}
+}
+
+func Test_NewAutoLshRecorder(t *testing.T) {
+	for driver, d := range map[string]dialect{
+		"":                  ansiDialect,
+		"github.com/lib/pq": postgresDialect,
+	} {
+		rec := NewRecorder()
+		rec.Driver = driver
+		lsh, err := NewAutoLsh(2, 3, "lshtable", rec)
+		if err != nil {
+			t.Fatalf("%q: %v", driver, err)
+		}
+		if lsh.dialect.indexesQuery != d.indexesQuery || lsh.dialect.intType != d.intType {
+			t.Errorf("%q: expected the dialect of the driver", driver)
+		}
+		if !lsh.dryRun || len(rec.Statements()) == 0 {
+			t.Errorf("%q: expected the statements to be recorded", driver)
+		}
+		m, err := NewManager(rec)
+		if err != nil {
+			t.Fatalf("%q: %v", driver, err)
+		}
+		if _, err := m.Create("other", 2, 3); err != nil {
+			t.Errorf("%q: %v", driver, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// WithAutoID declares the id column so the database generates the IDs
+// of the entries inserted with InsertAuto.
+// Mixing InsertAuto with inserts of explicit IDs can make the database
+// generate an ID that already exists, in which case ErrIDExists is
+// returned.
+// The constructor returns ErrUnsupported if the database does not
+// support it.
+// The option changes the table schema, so it must be used every time
+// the same table is opened.
+func WithAutoID() Option {
+	return func(lsh *SqlLsh) {
+		lsh.autoID = true
+	}
+}
+
+// InsertAuto appends a new Signature to the table, and returns the ID
+// the database generated for it.
+// It requires the index to be created using WithAutoID.
+func (lsh *SqlLsh) InsertAuto(sig Signature) (int, error) {
+	if !lsh.autoID {
+		return 0, ErrUnsupported
+	}
+	sigs, err := lsh.beforeInsert("insert", nil, []Signature{sig})
+	if err != nil {
+		return 0, err
+	}
+	sig = sigs[0]
+	if len(sig) != lsh.k*lsh.l {
+		return 0, ErrSignatureSizeMismatch
+	}
+	lsh.bloom.add(lsh.k, sig)
+	args := lsh.insertArgs(0, sig)[1:]
+	// The notifications of WithNotify are sent in the transaction of the
+	// insert
+	tx, err := lsh.db.Begin()
+	if err != nil {
+		return 0, wrapErr("insert", err)
+	}
+	var id int
+	if lsh.dialect.returning {
+		err = tx.QueryRow(lsh.insertAutoStr()+" RETURNING id", args...).Scan(&id)
+	} else {
+		var res sql.Result
+		res, err = tx.Exec(lsh.insertAutoStr(), args...)
+		if err == nil {
+			var n int64
+			n, err = res.LastInsertId()
+			id = int(n)
+		}
+	}
+	if err == nil {
+		err = lsh.afterInsert(tx, []int{id}, []Signature{sig})
+	}
+	if err != nil {
+		tx.Rollback()
+		return 0, wrapErr("insert", err)
+	}
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return 0, wrapErr("insert", err)
+	}
+	lsh.inserted([]int{id}, []Signature{sig})
+	return id, nil
+}
+
+// insertAutoStr returns the insert leaving out the id column.
+func (lsh *SqlLsh) insertAutoStr() string {
+	cols := lsh.insertCols()[1:]
+	vars := make([]string, len(cols))
+	for i := range vars {
+		vars[i] = lsh.dialect.varFmt(i)
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES(%s)", lsh.tableName,
+		strings.Join(cols, ","), strings.Join(vars, ","))
+}
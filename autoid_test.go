@@ -0,0 +1,79 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_InsertAuto(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db, WithAutoID(), WithInsertTime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(3, 6)
+	for i, sig := range sigs {
+		id, err := lsh.InsertAuto(sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != i+1 {
+			t.Errorf("Expected ID %d, got %d", i+1, id)
+		}
+	}
+	found, err := lsh.QueryIDs(sigs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0] != 2 {
+		t.Errorf("Expected [2], got %v", found)
+	}
+	plain, err := NewSqliteLsh(2, 3, "plaintable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plain.InsertAuto(sigs[0]); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+	removeTempFile(t, f)
+}
+
+func Test_InsertAutoReshape(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db, WithAutoID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	insert := func(expected int) {
+		id, err := lsh.InsertAuto(randomSigs(1, lsh.k*lsh.l)[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != expected {
+			t.Errorf("Expected ID %d, got %d", expected, id)
+		}
+	}
+	insert(1)
+	insert(2)
+	if err := lsh.Reshape(3, 2, nil); err != nil {
+		t.Fatal(err)
+	}
+	insert(3)
+	if err := lsh.AddHashTables(1, func(id int) Signature { return randomSigs(1, 3)[0] }); err != nil {
+		t.Fatal(err)
+	}
+	insert(4)
+	if _, err := OpenSqliteLsh("lshtable", db, WithAutoID()); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,162 @@
+package sqllsh
+
+import (
+	"compress/gzip"
+	"database/sql"
+	"encoding/gob"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// backupFormat is the version of the backup file format.
+const backupFormat = 1
+
+// backupBlockSize is the number of entries in each block of a backup
+// file, and in each batch inserted when restoring it.
+const backupBlockSize = 10000
+
+// backupHeader starts a backup file, followed by blocks of Entries and
+// an empty block.
+type backupHeader struct {
+	Format int
+	K, L   int
+}
+
+// BackupTo writes all the entries of the index to a gzip-compressed
+// file at path, which can be restored with RestoreFrom into an index
+// with the same k and l on any database.
+// Deleted entries, insertion times and namespaces are not saved, except
+// that a view returned by Namespace only saves its own entries.
+// With WithBBit the truncated hash values are saved, and WithBandKeys is
+// not supported, as the Signatures are not stored.
+// The entries are read in one transaction, at the repeatable read level
+// on PostgreSQL, MySQL and MariaDB and serializable on Oracle, so that
+// they are a consistent snapshot of the table, and from the primary
+// database even with WithReadReplicas.
+// The file is written next to path and renamed to path when complete,
+// so that a failed backup leaves no partial file.
+func (lsh *SqlLsh) BackupTo(path string) error {
+	if lsh.keysOnly {
+		return ErrUnsupported
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if err := lsh.backup(f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// backup writes the backup of the index to w.
+func (lsh *SqlLsh) backup(w io.Writer) error {
+	zw := gzip.NewWriter(w)
+	enc := gob.NewEncoder(zw)
+	if err := enc.Encode(backupHeader{Format: backupFormat, K: lsh.k, L: lsh.l}); err != nil {
+		return err
+	}
+	tx, err := lsh.db.BeginTx(&sql.TxOptions{Isolation: lsh.dialect.snapshot})
+	if err != nil {
+		return wrapErr("backup", err)
+	}
+	// Nothing is written, so the transaction is only rolled back
+	defer tx.Rollback()
+	it, err := lsh.txView(tx).ScanIter()
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	block := make([]Entry, 0, backupBlockSize)
+	for it.Next() {
+		block = append(block, it.Value())
+		if len(block) == backupBlockSize {
+			if err := enc.Encode(block); err != nil {
+				return err
+			}
+			block = block[:0]
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	if len(block) > 0 {
+		if err := enc.Encode(block); err != nil {
+			return err
+		}
+	}
+	// The empty block marks the end of the backup
+	if err := enc.Encode([]Entry{}); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// txView returns a view of the index running its statements in tx.
+func (lsh *SqlLsh) txView(tx *txn) *SqlLsh {
+	view := *lsh
+	view.db = dbConn{DB: tx.Tx, ctx: lsh.db.ctx}
+	view.replicas = nil
+	view.adHoc = true
+	view.ownDB = false
+	view.insertStmt = nil
+	view.queryStmt = nil
+	view.countStmt = nil
+	view.scanStmt = nil
+	view.deleteStmt = nil
+	view.purgeStmt = nil
+	return &view
+}
+
+// RestoreFrom inserts the entries of a backup file written by BackupTo
+// into the index, which must have the same k and l as the backed up
+// index, or ErrIncompatible is returned.
+// The entries are inserted with BatchInsert, 10000 at a time, so if
+// an error occurs the entries of the previous batches stay inserted.
+func (lsh *SqlLsh) RestoreFrom(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	dec := gob.NewDecoder(zr)
+	var h backupHeader
+	if err := dec.Decode(&h); err != nil {
+		return err
+	}
+	if h.Format != backupFormat || h.K != lsh.k || h.L != lsh.l {
+		return ErrIncompatible
+	}
+	for {
+		var block []Entry
+		if err := dec.Decode(&block); err != nil {
+			return err
+		}
+		if len(block) == 0 {
+			return nil
+		}
+		ids := make([]int, len(block))
+		sigs := make([]Signature, len(block))
+		for i, e := range block {
+			ids[i], sigs[i] = e.Id, e.Signature
+		}
+		if err := lsh.BatchInsert(ids, sigs); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_BackupRestore(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(backupBlockSize+10, 4)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	dir, err := os.MkdirTemp("", "sqllsh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "backup.gz")
+	if err := lsh.BackupTo(path); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := NewSqliteLsh(2, 2, "restored", db, WithCompactLayout())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.RestoreFrom(path); err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{0, backupBlockSize, len(sigs) - 1} {
+		got, err := restored.Get(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameSig(got, sigs[i]) {
+			t.Errorf("Expected %v for %d, got %v", sigs[i], i, got)
+		}
+	}
+	other, err := NewSqliteLsh(4, 1, "other", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.RestoreFrom(path); err != ErrIncompatible {
+		t.Errorf("Expected ErrIncompatible, got %v", err)
+	}
+}
+
+func Test_BackupFailed(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := os.MkdirTemp("", "sqllsh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "backup.gz")
+	if err := os.WriteFile(path, []byte("previous"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("DROP TABLE lshtable"); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.BackupTo(path); err == nil {
+		t.Fatal("Expected an error")
+	}
+	// The previous file is kept, and the partial one removed
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "previous" {
+		t.Errorf("Expected the previous file, got %q, %v", data, err)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("Expected only the previous file, got %d files", len(files))
+	}
+}
@@ -0,0 +1,87 @@
+package sqllsh
+
+// QueryBands is like Query, but only checks collisions in the given
+// hash tables, numbered from 0 to l-1.
+// Using fewer hash tables makes the query faster at the cost of recall,
+// without changing the indexes.
+// The caller is responsible for closing the channel.
+func (lsh *SqlLsh) QueryBands(sig Signature, bands []int, out chan int) error {
+	sig, err := lsh.beforeQuery("query bands", sig)
+	if err != nil {
+		return err
+	}
+	if len(sig) != lsh.k*lsh.l {
+		return ErrSignatureSizeMismatch
+	}
+	if len(bands) == 0 {
+		return ErrInvalidParameter
+	}
+	for _, band := range bands {
+		if band < 0 || band >= lsh.l {
+			return ErrInvalidParameter
+		}
+	}
+	if err := lsh.ready(); err != nil {
+		return err
+	}
+	if maybe := lsh.maybeBands(sig); maybe != nil {
+		bands = intersectBands(bands, maybe)
+	}
+	ids, err := lsh.queryBands(sig, bands, lsh.k)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		out <- id
+	}
+	return nil
+}
+
+// intersectBands returns the bands in a that are also in b.
+func intersectBands(a, b []int) []int {
+	in := make(map[int]bool, len(b))
+	for _, band := range b {
+		in[band] = true
+	}
+	bands := make([]int, 0, len(a))
+	for _, band := range a {
+		if in[band] {
+			bands = append(bands, band)
+		}
+	}
+	return bands
+}
+
+// queryBands returns the IDs colliding with sig in the given bands,
+// using the first prefix hash values of each hash key.
+func (lsh *SqlLsh) queryBands(sig Signature, bands []int, prefix int) ([]int, error) {
+	ids := make([]int, 0)
+	if len(bands) == 0 {
+		return ids, nil
+	}
+	if err := lsh.ready(); err != nil {
+		return nil, err
+	}
+	s, err := lsh.beginQuery()
+	if err != nil {
+		return nil, err
+	}
+	defer s.end()
+	rows, err := s.query(nil, lsh.bandsQueryStr(bands, prefix),
+		lsh.queryArgs(sig, bands, prefix)...)
+	if err != nil {
+		return nil, wrapErr("query", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, wrapErr("query", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapErr("query", err)
+	}
+	return ids, nil
+}
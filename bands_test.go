@@ -0,0 +1,52 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_QueryBands(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db)
+	if err != nil {
+		t.Error(err)
+	}
+	lsh.Insert(0, Signature{1, 2, 0, 0, 0, 0})
+	lsh.Insert(1, Signature{0, 0, 3, 4, 0, 0})
+	lsh.Insert(2, Signature{0, 0, 0, 0, 5, 6})
+	sig := Signature{1, 2, 3, 4, 5, 6}
+	for _, c := range []struct {
+		bands []int
+		ids   map[int]bool
+	}{
+		{[]int{0}, map[int]bool{0: true}},
+		{[]int{1, 2}, map[int]bool{1: true, 2: true}},
+		{[]int{2, 0, 1}, map[int]bool{0: true, 1: true, 2: true}},
+	} {
+		out := make(chan int)
+		go func() {
+			if err := lsh.QueryBands(sig, c.bands, out); err != nil {
+				t.Error(err)
+			}
+			close(out)
+		}()
+		count := 0
+		for id := range out {
+			if !c.ids[id] {
+				t.Errorf("Unexpected ID %d for bands %v", id, c.bands)
+			}
+			count++
+		}
+		if count != len(c.ids) {
+			t.Errorf("Expected %d IDs for bands %v, got %d", len(c.ids), c.bands, count)
+		}
+	}
+	if err := lsh.QueryBands(sig, []int{3}, nil); err != ErrInvalidParameter {
+		t.Error("Fail to raise error")
+	}
+	removeTempFile(t, f)
+}
@@ -0,0 +1,86 @@
+package sqllsh
+
+// BatchWriter writes rows to table, for databases with a faster way to
+// load many rows than inserting them one at a time.
+// Each row holds the values of cols, and c is the behavior when the ID
+// of a row exists.
+// See the sqllshspanner package for a BatchWriter using Spanner
+// mutations.
+type BatchWriter func(table string, cols []string, rows [][]interface{}, c Conflict) error
+
+// PurgeWriter is a BatchWriter which first runs the statement purge once
+// with each of args, in the same transaction as writing the rows.
+// purge is empty unless the index uses WithSoftDelete, and then removes
+// the deleted entries with the IDs of the rows.
+// See the sqllshpgx package for a PurgeWriter using COPY.
+type PurgeWriter func(table string, cols []string, rows [][]interface{}, c Conflict,
+	purge string, args [][]interface{}) error
+
+// WithBatchWriter makes BatchInsert write the rows using w, instead of
+// inserting them in a transaction.
+// With WithSoftDelete, the deleted entries with the same IDs are purged
+// in a separate transaction before the rows are written, use
+// WithPurgeWriter to purge them in the same one.
+func WithBatchWriter(w BatchWriter) Option {
+	return func(lsh *SqlLsh) {
+		lsh.writer = func(table string, cols []string, rows [][]interface{}, c Conflict,
+			_ string, _ [][]interface{}) error {
+			return w(table, cols, rows, c)
+		}
+		lsh.writerPurges = false
+	}
+}
+
+// WithPurgeWriter makes BatchInsert write the rows using w, instead of
+// inserting them in a transaction, with w purging the deleted entries
+// with the same IDs.
+func WithPurgeWriter(w PurgeWriter) Option {
+	return func(lsh *SqlLsh) {
+		lsh.writer = w
+		lsh.writerPurges = true
+	}
+}
+
+// writeBatch writes the Signatures from position begin to end using the
+// batch writer.
+func (lsh *SqlLsh) writeBatch(ids []int, sigs []Signature, begin, end int) error {
+	var purge string
+	var args [][]interface{}
+	if lsh.softDelete && lsh.writerPurges {
+		purge = lsh.purgeStr()
+		for _, id := range ids[begin:end] {
+			args = append(args, []interface{}{id})
+		}
+	} else if lsh.softDelete {
+		tx, err := lsh.db.Begin()
+		if err != nil {
+			return wrapErr("batch insert", err)
+		}
+		for _, id := range ids[begin:end] {
+			if err := lsh.purge(tx.Tx, id); err != nil {
+				tx.Rollback()
+				return wrapErr("batch insert", err)
+			}
+		}
+		err = tx.Commit()
+		if err != nil {
+			tx.Rollback()
+			return wrapErr("batch insert", err)
+		}
+	}
+	rows := make([][]interface{}, 0, end-begin)
+	for i := begin; i < end; i++ {
+		rows = append(rows, lsh.insertArgs(ids[i], sigs[i]))
+	}
+	if err := lsh.writer(lsh.tableName, lsh.insertCols(), rows, lsh.sqlConflict(),
+		purge, args); err != nil {
+		return wrapErr("batch insert", err)
+	}
+	// ConflictIdempotent is not supported, so only the notifications
+	// are sent
+	if err := lsh.afterInsert(lsh.db, ids[begin:end], sigs[begin:end]); err != nil {
+		return wrapErr("batch insert", err)
+	}
+	lsh.inserted(ids[begin:end], sigs[begin:end])
+	return nil
+}
@@ -0,0 +1,70 @@
+package sqllsh
+
+// WithBBit only stores the lowest b bits of each hash value, as in
+// b-bit MinHash, packing 64/b hash values into each integer column.
+// b must be 1, 2, 4, 8, 16 or 32.
+// With b = 8, for example, the table has 8 times fewer hash value
+// columns, at the cost of more false positive candidates, as hash
+// values that only differ in their higher bits are equal.
+// Queries take the full Signatures, which are truncated the same way.
+// Scan and Get return the truncated hash values, the Bloom filters and
+// the query cache are not supported, QueryPrefix and
+// QueryAtLeast can only match full hash keys, and AddHashTables and
+// Reshape are not supported.
+// The option changes the table schema, so it must be used every time
+// the same table is opened.
+func WithBBit(b int) Option {
+	return func(lsh *SqlLsh) {
+		lsh.bits = b
+	}
+}
+
+// validBits returns whether b divides 64, so hash values never span
+// two columns.
+func validBits(b int) bool {
+	switch b {
+	case 1, 2, 4, 8, 16, 32:
+		return true
+	}
+	return false
+}
+
+// bitWords returns the number of columns of each hash key with WithBBit.
+func (lsh *SqlLsh) bitWords() int {
+	per := 64 / lsh.bits
+	return (lsh.k + per - 1) / per
+}
+
+// packBits returns the column values holding the lowest bits of the
+// hash values of one hash key.
+func (lsh *SqlLsh) packBits(key Signature) []interface{} {
+	per := 64 / lsh.bits
+	mask := uint64(1)<<uint(lsh.bits) - 1
+	words := make([]interface{}, lsh.bitWords())
+	for j := range words {
+		var w uint64
+		for i := j * per; i < (j+1)*per && i < len(key); i++ {
+			w |= (uint64(key[i]) & mask) << uint(lsh.bits*(i-j*per))
+		}
+		// Stored as signed, as not all drivers take uint64 with the high
+		// bit set
+		words[j] = int64(w)
+	}
+	return words
+}
+
+// unpackBits returns the truncated Signature stored in the column
+// values words, which hold all the hash keys.
+func (lsh *SqlLsh) unpackBits(words Signature) Signature {
+	per := 64 / lsh.bits
+	n := lsh.bitWords()
+	mask := uint64(1)<<uint(lsh.bits) - 1
+	sig := make(Signature, lsh.k*lsh.l)
+	for band := 0; band < lsh.l; band++ {
+		for i := 0; i < lsh.k; i++ {
+			w := uint64(words[n*band+i/per])
+			sig[lsh.k*band+i] = uint(w >> uint(lsh.bits*(i%per)) & mask)
+		}
+	}
+	return sig
+}
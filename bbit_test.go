@@ -0,0 +1,75 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func Test_PackBits(t *testing.T) {
+	lsh := &SqlLsh{k: 5, l: 2, bits: 16}
+	sig := Signature{1, 2, 3, 4, 5, 0x10006, 7, 8, 9, 0xffff}
+	var words Signature
+	for band := 0; band < lsh.l; band++ {
+		packed := lsh.packBits(sig[lsh.k*band : lsh.k*(band+1)])
+		if len(packed) != 2 {
+			t.Fatalf("Expected 2 words per hash key, got %d", len(packed))
+		}
+		for _, w := range packed {
+			words = append(words, uint(w.(int64)))
+		}
+	}
+	want := Signature{1, 2, 3, 4, 5, 6, 7, 8, 9, 0xffff}
+	if got := lsh.unpackBits(words); !sameSig(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func Test_BBit(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := NewSqliteLsh(4, 2, "badtable", db, WithBBit(3)); err != ErrInvalidParameter {
+		t.Errorf("Expected ErrInvalidParameter, got %v", err)
+	}
+	lsh, err := NewSqliteLsh(4, 2, "lshtable", db, WithBBit(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(lsh.Statements().CreateTable, "bv_2") {
+		t.Errorf("Expected one column per hash key, got %s", lsh.Statements().CreateTable)
+	}
+	sigs := randomSigs(50, 8)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	for i, sig := range sigs {
+		found, err := lsh.QueryIDs(sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !containsID(found, i) {
+			t.Errorf("Expected %d in the result of its own Signature, got %v", i, found)
+		}
+	}
+	got, err := lsh.Get(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range got {
+		if v != sigs[0][i]&0xff {
+			t.Errorf("Expected the lowest 8 bits of %d, got %d", sigs[0][i], v)
+		}
+	}
+}
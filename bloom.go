@@ -0,0 +1,131 @@
+package sqllsh
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"sync"
+)
+
+// WithBloomFilters keeps one Bloom filter per hash table in memory,
+// containing the hash keys in the table.
+// Before querying the database, the hash keys of the query Signature
+// are checked against the filters, and the hash tables where there can
+// be no collision are left out of the query, or the query is skipped
+// if there is none left.
+// n is the expected number of Signatures and fpRate the false positive
+// rate of each filter at that size.
+// The filters are filled from the table when the index is created,
+// and are local to the SqlLsh, so Signatures inserted through other
+// SqlLsh or connections may not be found.
+// Deleted entries are not removed from the filters.
+func WithBloomFilters(n int, fpRate float64) Option {
+	return func(lsh *SqlLsh) {
+		lsh.bloom = newBandBloom(lsh.l, n, fpRate)
+	}
+}
+
+// bandBloom is a set of Bloom filters, one for each hash table.
+// The methods can be called on a nil bandBloom, which filters nothing.
+type bandBloom struct {
+	mu      sync.RWMutex
+	filters [][]uint64 // Bit sets
+	m       uint64     // Number of bits in each filter
+	h       int        // Number of hash functions
+	n       int        // Expected number of Signatures
+	fpRate  float64    // False positive rate at n Signatures
+}
+
+func newBandBloom(l, n int, fpRate float64) *bandBloom {
+	if n < 1 {
+		n = 1
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	h := int(math.Ceil(float64(m) / float64(n) * math.Ln2))
+	if h < 1 {
+		h = 1
+	}
+	filters := make([][]uint64, l)
+	for i := range filters {
+		filters[i] = make([]uint64, (m+63)/64)
+	}
+	return &bandBloom{filters: filters, m: m, h: h, n: n, fpRate: fpRate}
+}
+
+// bloomHashes returns the two base hashes of the hash key of the band
+// of sig, used for double hashing.
+func bloomHashes(sig Signature, k, band int) (uint64, uint64) {
+	f := fnv.New64a()
+	buf := make([]byte, 8)
+	for _, v := range sig[band*k : (band+1)*k] {
+		binary.LittleEndian.PutUint64(buf, uint64(v))
+		f.Write(buf)
+	}
+	sum := f.Sum64()
+	return sum & 0xffffffff, sum>>32 | 1
+}
+
+// add adds the hash keys of sigs to the filters.
+func (b *bandBloom) add(k int, sigs ...Signature) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, sig := range sigs {
+		for band := range b.filters {
+			h1, h2 := bloomHashes(sig, k, band)
+			for i := 0; i < b.h; i++ {
+				bit := (h1 + uint64(i)*h2) % b.m
+				b.filters[band][bit/64] |= 1 << (bit % 64)
+			}
+		}
+	}
+}
+
+// bands returns the hash tables in which the hash key of sig may
+// collide, or nil if there are no filters.
+func (b *bandBloom) bands(sig Signature, k int) []int {
+	if b == nil {
+		return nil
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	bands := make([]int, 0, len(b.filters))
+	for band := range b.filters {
+		h1, h2 := bloomHashes(sig, k, band)
+		found := true
+		for i := 0; i < b.h && found; i++ {
+			bit := (h1 + uint64(i)*h2) % b.m
+			found = b.filters[band][bit/64]&(1<<(bit%64)) != 0
+		}
+		if found {
+			bands = append(bands, band)
+		}
+	}
+	return bands
+}
+
+// loadBloom fills the Bloom filters with the Signatures already in the
+// table.
+func (lsh *SqlLsh) loadBloom() error {
+	if lsh.bloom == nil {
+		return nil
+	}
+	rows, err := lsh.db.Query(lsh.scanStr())
+	if err != nil {
+		return wrapErr("load bloom filters", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e, err := lsh.scanEntry(rows)
+		if err != nil {
+			return err
+		}
+		lsh.bloom.add(lsh.k, e.Signature)
+	}
+	return wrapErr("load bloom filters", rows.Err())
+}
@@ -0,0 +1,63 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_BloomFilters(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithBloomFilters(100, 0.01))
+	if err != nil {
+		t.Error(err)
+	}
+	sigs := randomSigs(10, 4)
+	for i := range sigs {
+		lsh.Insert(i, sigs[i])
+	}
+	for i := range sigs {
+		ids, err := lsh.QueryIDs(sigs[i])
+		if err != nil {
+			t.Error(err)
+		}
+		if len(ids) != 1 || ids[0] != i {
+			t.Error("Error in query")
+		}
+		// Only the second band collides
+		sig := append(Signature{0, 0}, sigs[i][2:]...)
+		if bands := lsh.bloom.bands(sig, 2); len(bands) != 1 || bands[0] != 1 {
+			t.Errorf("Expected band 1 only, got %v", bands)
+		}
+		ids, err = lsh.QueryIDs(sig)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(ids) != 1 || ids[0] != i {
+			t.Error("Error in query on a subset of bands")
+		}
+	}
+	it, err := lsh.QueryIter(Signature{0, 0, 0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.Next() {
+		t.Error("Expected no result")
+	}
+	// The filters are loaded from an existing table
+	lsh, err = NewSqliteLsh(2, 2, "lshtable", db, WithBloomFilters(100, 0.01))
+	if err != nil {
+		t.Error(err)
+	}
+	ids, err := lsh.QueryIDs(sigs[0])
+	if err != nil {
+		t.Error(err)
+	}
+	if len(ids) != 1 {
+		t.Error("Error in query after reopening")
+	}
+	removeTempFile(t, f)
+}
@@ -0,0 +1,112 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// bulkLoadBatchSize is the number of rows BulkLoad commits at a time,
+// unless WithCommitSize is used.
+const bulkLoadBatchSize = 10000
+
+// BulkLoad is like BatchInsert, but always commits the Signatures in
+// batches, of 10000 rows unless WithCommitSize is used, and records the
+// number of committed rows as a checkpoint in the table
+// <tableName>_checkpoint under the given name.
+// If the load is interrupted, calling BulkLoad again with the same name
+// and input resumes after the last committed batch, instead of
+// inserting everything again.
+// Once the load has finished, calling BulkLoad with the same name and
+// input does nothing, so a different name must be used for each load.
+// Progress is reported after each batch if WithProgress is used.
+func (lsh *SqlLsh) BulkLoad(name string, ids []int, sigs []Signature) error {
+	sigs, err := lsh.beforeInsert("bulk load", ids, sigs)
+	if err != nil {
+		return err
+	}
+	if err := validateBatch(ids, sigs, lsh.k*lsh.l); err != nil {
+		return err
+	}
+	if lsh.dialect.ddl != nil {
+		return ErrUnsupported
+	}
+	start := time.Now()
+	_, err = lsh.db.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (name VARCHAR(255) PRIMARY KEY, loaded %s NOT NULL)",
+		lsh.checkpointTable(), lsh.dialect.intType))
+	if err != nil {
+		return wrapErr("bulk load", err)
+	}
+	if lsh.workers > 1 {
+		return lsh.loadParallel(name, ids, sigs, start)
+	}
+	var loaded int
+	err = lsh.db.QueryRow(fmt.Sprintf("SELECT loaded FROM %s WHERE name = %s",
+		lsh.checkpointTable(), lsh.dialect.varFmt(0)), name).Scan(&loaded)
+	if err != nil && err != sql.ErrNoRows {
+		return wrapErr("bulk load", err)
+	}
+	size := lsh.batchSize(bulkLoadBatchSize)
+	for loaded < len(sigs) {
+		end := loaded + size
+		if end > len(sigs) {
+			end = len(sigs)
+		}
+		if err := lsh.loadBatch(name, ids, sigs, loaded, end); err != nil {
+			return err
+		}
+		loaded = end
+		lsh.report("bulk load", loaded, len(sigs), start)
+	}
+	return nil
+}
+
+// loadBatch inserts the Signatures from position start to end, and
+// updates the checkpoint in the same transaction.
+func (lsh *SqlLsh) loadBatch(name string, ids []int, sigs []Signature, start, end int) error {
+	lsh.bloom.add(lsh.k, sigs[start:end]...)
+	tx, err := lsh.db.Begin()
+	if err != nil {
+		return wrapErr("bulk load", err)
+	}
+	if err := lsh.waitLock(tx.Tx); err != nil {
+		tx.Rollback()
+		return wrapErr("bulk load", err)
+	}
+	for i := start; i < end; i++ {
+		if err := lsh.insertRow(tx.Tx, ids[i], sigs[i]); err != nil {
+			tx.Rollback()
+			return wrapErr("bulk load", err)
+		}
+	}
+	if err := lsh.afterInsert(tx, ids[start:end], sigs[start:end]); err != nil {
+		tx.Rollback()
+		return wrapErr("bulk load", err)
+	}
+	res, err := tx.Exec(fmt.Sprintf("UPDATE %s SET loaded = %s WHERE name = %s",
+		lsh.checkpointTable(), lsh.dialect.varFmt(0), lsh.dialect.varFmt(1)), end, name)
+	if err != nil {
+		tx.Rollback()
+		return wrapErr("bulk load", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		_, err = tx.Exec(fmt.Sprintf("INSERT INTO %s (name, loaded) VALUES (%s, %s)",
+			lsh.checkpointTable(), lsh.dialect.varFmt(0), lsh.dialect.varFmt(1)), name, end)
+		if err != nil {
+			tx.Rollback()
+			return wrapErr("bulk load", err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return wrapErr("bulk load", err)
+	}
+	lsh.inserted(ids[start:end], sigs[start:end])
+	return nil
+}
+
+func (lsh *SqlLsh) checkpointTable() string {
+	return lsh.tableName + "_checkpoint"
+}
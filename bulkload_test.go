@@ -0,0 +1,54 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_BulkLoad(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	var reports []Progress
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithProgress(func(p Progress) {
+		reports = append(reports, p)
+	}))
+	if err != nil {
+		t.Error(err)
+	}
+	sigs := randomSigs(25000, 4)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	if err := lsh.BulkLoad("load", ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 3 || reports[2].Done != len(sigs) {
+		t.Errorf("Expected 3 progress reports, got %v", reports)
+	}
+	// Simulate an interruption after the first batch
+	if _, err := db.Exec("DELETE FROM lshtable WHERE id >= 10000"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("UPDATE lshtable_checkpoint SET loaded = 10000"); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.BulkLoad("load", ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM lshtable").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != len(sigs) {
+		t.Errorf("Expected %d entries, got %d", len(sigs), count)
+	}
+	// A finished load does nothing
+	if err := lsh.BulkLoad("load", ids, sigs); err != nil {
+		t.Error(err)
+	}
+	removeTempFile(t, f)
+}
@@ -0,0 +1,203 @@
+package sqllsh
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithQueryCache keeps the results of the most recently used queries in
+// memory, so repeated queries with the same Signature do not go to the
+// database.
+// At most size results are kept, each for at most ttl, or until
+// evicted if ttl is zero.
+// Inserting a Signature evicts the cached results that share a hash
+// key with it, and deleting an entry evicts the results containing it.
+// The cache is used by Query and QueryIDs, and is local to the SqlLsh,
+// so writes made through other SqlLsh or connections are not seen
+// until the results expire.
+func WithQueryCache(size int, ttl time.Duration) Option {
+	return func(lsh *SqlLsh) {
+		lsh.cache = newQueryCache(size, ttl)
+	}
+}
+
+// queryCache is an LRU cache of query results keyed by the hash keys
+// of the query Signature.
+// The methods can be called on a nil cache, which caches nothing.
+type queryCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	lru   *list.List               // Most recently used first
+	items map[string]*list.Element // Cache key to element of lru
+	bands map[string]map[string]bool
+	gen   uint64 // Incremented on every invalidation
+}
+
+type cacheItem struct {
+	key     string
+	bands   []string
+	ids     []int
+	expires time.Time
+}
+
+func newQueryCache(size int, ttl time.Duration) *queryCache {
+	return &queryCache{
+		size:  size,
+		ttl:   ttl,
+		lru:   list.New(),
+		items: make(map[string]*list.Element),
+		bands: make(map[string]map[string]bool),
+	}
+}
+
+// bandKeys returns the hash keys of sig formatted as strings, each
+// prefixed with the number of its band.
+func bandKeys(sig Signature, k int) []string {
+	keys := make([]string, len(sig)/k)
+	for i := range keys {
+		keys[i] = fmt.Sprint(i, sig[i*k:(i+1)*k])
+	}
+	return keys
+}
+
+// generation returns the current generation, which must be passed to
+// put, so results read before an invalidation are not cached.
+func (c *queryCache) generation() uint64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// get returns the cached result of the query for sig.
+func (c *queryCache) get(sig Signature, k int) ([]int, bool) {
+	if c == nil {
+		return nil, false
+	}
+	key := strings.Join(bandKeys(sig, k), " ")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := e.Value.(*cacheItem)
+	if c.ttl > 0 && time.Now().After(item.expires) {
+		c.remove(e)
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return item.ids, true
+}
+
+// put caches the result of the query for sig, unless the cache was
+// invalidated since gen.
+func (c *queryCache) put(sig Signature, k int, ids []int, gen uint64) {
+	if c == nil || c.size <= 0 {
+		return
+	}
+	bands := bandKeys(sig, k)
+	key := strings.Join(bands, " ")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen != c.gen {
+		return
+	}
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+	item := &cacheItem{
+		key:     key,
+		bands:   bands,
+		ids:     ids,
+		expires: time.Now().Add(c.ttl),
+	}
+	c.items[key] = c.lru.PushFront(item)
+	for _, band := range bands {
+		if c.bands[band] == nil {
+			c.bands[band] = make(map[string]bool)
+		}
+		c.bands[band][key] = true
+	}
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+	}
+}
+
+// invalidateSigs evicts the results sharing a hash key with any of sigs.
+func (c *queryCache) invalidateSigs(k int, sigs ...Signature) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	for _, sig := range sigs {
+		for _, band := range bandKeys(sig, k) {
+			for key := range c.bands[band] {
+				c.remove(c.items[key])
+			}
+		}
+	}
+}
+
+// invalidateID evicts the results containing id.
+func (c *queryCache) invalidateID(id int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	for e := c.lru.Front(); e != nil; {
+		next := e.Next()
+		for _, x := range e.Value.(*cacheItem).ids {
+			if x == id {
+				c.remove(e)
+				break
+			}
+		}
+		e = next
+	}
+}
+
+// sharedCache returns the query cache of lsh, or of the index lsh is a
+// view of, so that the changes made through a view can evict the
+// results cached by its index.
+func (lsh *SqlLsh) sharedCache() *queryCache {
+	if lsh.cache != nil {
+		return lsh.cache
+	}
+	return lsh.parentCache
+}
+
+// clear evicts all results.
+func (c *queryCache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	c.lru.Init()
+	c.items = make(map[string]*list.Element)
+	c.bands = make(map[string]map[string]bool)
+}
+
+// remove evicts a result, the caller must hold the lock.
+func (c *queryCache) remove(e *list.Element) {
+	item := c.lru.Remove(e).(*cacheItem)
+	delete(c.items, item.key)
+	for _, band := range item.bands {
+		delete(c.bands[band], item.key)
+		if len(c.bands[band]) == 0 {
+			delete(c.bands, band)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func Test_QueryCache(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithQueryCache(10, time.Minute))
+	if err != nil {
+		t.Error(err)
+	}
+	lsh.Insert(0, Signature{1, 2, 3, 4})
+	query := func(sig Signature, n int) {
+		ids, err := lsh.QueryIDs(sig)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(ids) != n {
+			t.Errorf("Expected %d IDs, got %d", n, len(ids))
+		}
+	}
+	query(Signature{1, 2, 5, 6}, 1)
+	// The hash keys of a longer Signature are those of the cached one
+	if _, err := lsh.QueryIDs(Signature{1, 2, 5, 6, 7}); err != ErrSignatureSizeMismatch {
+		t.Errorf("Expected ErrSignatureSizeMismatch, got %v", err)
+	}
+	// Served from the cache, even though the table changed
+	if _, err := db.Exec("DELETE FROM lshtable"); err != nil {
+		t.Error(err)
+	}
+	query(Signature{1, 2, 5, 6}, 1)
+	// Inserting a colliding signature evicts the result
+	lsh.Insert(1, Signature{7, 8, 5, 6})
+	query(Signature{1, 2, 5, 6}, 1)
+	lsh.Delete(1)
+	query(Signature{1, 2, 5, 6}, 0)
+	removeTempFile(t, f)
+}
+
+func Test_QueryCacheLRU(t *testing.T) {
+	c := newQueryCache(2, 0)
+	c.put(Signature{1, 2}, 1, []int{1}, c.generation())
+	c.put(Signature{3, 4}, 1, []int{2}, c.generation())
+	c.get(Signature{1, 2}, 1)
+	c.put(Signature{5, 6}, 1, []int{3}, c.generation())
+	if _, ok := c.get(Signature{3, 4}, 1); ok {
+		t.Error("Least recently used result not evicted")
+	}
+	if _, ok := c.get(Signature{1, 2}, 1); !ok {
+		t.Error("Recently used result evicted")
+	}
+	gen := c.generation()
+	c.invalidateID(3)
+	c.put(Signature{7, 8}, 1, []int{4}, gen)
+	if _, ok := c.get(Signature{7, 8}, 1); ok {
+		t.Error("Result read before invalidation was cached")
+	}
+	c.invalidateSigs(1, Signature{0, 2})
+	if _, ok := c.get(Signature{1, 2}, 1); ok {
+		t.Error("Colliding result not evicted")
+	}
+}
+
+func Test_QueryCacheView(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithQueryCache(10, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	view := lsh.Where("id >= ?", 0)
+	query := func(n int) {
+		ids, err := lsh.QueryIDs(Signature{1, 2, 5, 6})
+		if err != nil {
+			t.Error(err)
+		}
+		if len(ids) != n {
+			t.Errorf("Expected %d IDs, got %d", n, len(ids))
+		}
+	}
+	query(0)
+	// Writing through the view evicts the results cached by lsh
+	if err := view.Insert(0, Signature{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	query(1)
+	if err := view.BatchInsert([]int{1}, []Signature{{1, 2, 7, 8}}); err != nil {
+		t.Fatal(err)
+	}
+	query(2)
+	if err := view.Delete(0); err != nil {
+		t.Fatal(err)
+	}
+	query(1)
+	if err := view.BatchDelete([]int{1}); err != nil {
+		t.Fatal(err)
+	}
+	query(0)
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := view.InsertTx(tx, 2, Signature{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	query(1)
+	tx, err = db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := view.DeleteTx(tx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	query(0)
+}
@@ -0,0 +1,73 @@
+package sqllsh
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Candidate is an ID found by QueryCandidates, with the hash tables in
+// which its Signature collides with the query Signature.
+type Candidate struct {
+	Id    int
+	Bands []int // Hash tables with a collision, in ascending order
+}
+
+// QueryCandidates is like Query, but also reports for each ID which hash
+// tables have a collision, which helps estimating the similarity and
+// debugging recall.
+// The Candidates are written in ascending order of ID.
+// The caller is responsible for closing the channel.
+func (lsh *SqlLsh) QueryCandidates(sig Signature, out chan Candidate) error {
+	sig, err := lsh.beforeQuery("query candidates", sig)
+	if err != nil {
+		return err
+	}
+	if len(sig) != lsh.k*lsh.l {
+		return ErrSignatureSizeMismatch
+	}
+	if err := lsh.ready(); err != nil {
+		return err
+	}
+	bands := lsh.maybeBands(sig)
+	if bands == nil {
+		bands = lsh.allBands()
+	}
+	if len(bands) == 0 {
+		return nil
+	}
+	selects := lsh.bandSelects(bands, lsh.k, func(band int) string {
+		return fmt.Sprintf("id, %d AS band", band)
+	})
+	query := strings.Join(selects, " UNION ALL ")
+	if filter := lsh.filterCond(len(bands) * lsh.bandWidth(lsh.k)); filter != "" {
+		query = fmt.Sprintf("SELECT id, band FROM (%s) c WHERE id IN (SELECT id FROM %s WHERE %s1 = 1%s)",
+			query, lsh.tableName, lsh.liveCond(), filter)
+	}
+	rows, err := lsh.readDB().Query(query+" ORDER BY id, band", lsh.queryArgs(sig, bands, lsh.k)...)
+	if err != nil {
+		return wrapErr("query", err)
+	}
+	defer rows.Close()
+	var c *Candidate
+	for rows.Next() {
+		var id, band int
+		if err := rows.Scan(&id, &band); err != nil {
+			return wrapErr("query", err)
+		}
+		if c != nil && c.Id != id {
+			out <- *c
+			c = nil
+		}
+		if c == nil {
+			c = &Candidate{Id: id}
+		}
+		c.Bands = append(c.Bands, band)
+	}
+	if err := rows.Err(); err != nil {
+		return wrapErr("query", err)
+	}
+	if c != nil {
+		out <- *c
+	}
+	return nil
+}
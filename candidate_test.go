@@ -0,0 +1,43 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func Test_QueryCandidates(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db)
+	if err != nil {
+		t.Error(err)
+	}
+	lsh.Insert(0, Signature{1, 2, 3, 4, 5, 6})
+	lsh.Insert(1, Signature{1, 2, 0, 0, 5, 6})
+	lsh.Insert(2, Signature{0, 0, 3, 4, 0, 0})
+	lsh.Insert(3, Signature{0, 0, 0, 0, 0, 0})
+	expected := []Candidate{
+		{Id: 0, Bands: []int{0, 1, 2}},
+		{Id: 1, Bands: []int{0, 2}},
+		{Id: 2, Bands: []int{1}},
+	}
+	out := make(chan Candidate)
+	go func() {
+		if err := lsh.QueryCandidates(Signature{1, 2, 3, 4, 5, 6}, out); err != nil {
+			t.Error(err)
+		}
+		close(out)
+	}()
+	var found []Candidate
+	for c := range out {
+		found = append(found, c)
+	}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("Expected %v, got %v", expected, found)
+	}
+	removeTempFile(t, f)
+}
@@ -0,0 +1,41 @@
+package sqllsh
+
+import "strings"
+
+// keysChunkSize is the number of IDs in an IN list on databases
+// without a known parameter limit.
+const keysChunkSize = 500
+
+// inChunkSize returns the number of IDs in an IN list, as many as the
+// database accepts parameters in a statement.
+func (lsh *SqlLsh) inChunkSize() int {
+	if lsh.dialect.maxParams > 0 {
+		return lsh.dialect.maxParams
+	}
+	return keysChunkSize
+}
+
+// inChunks splits the n arguments given by arg into chunks fitting the
+// parameter limit of the database, and calls f with the IN list of
+// placeholders and the arguments of each chunk, stopping at the first
+// error.
+func (lsh *SqlLsh) inChunks(n int, arg func(i int) interface{},
+	f func(in string, args []interface{}) error) error {
+	size := lsh.inChunkSize()
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		vars := make([]string, end-start)
+		args := make([]interface{}, end-start)
+		for i := range vars {
+			vars[i] = lsh.dialect.varFmt(i)
+			args[i] = arg(start + i)
+		}
+		if err := f(strings.Join(vars, ","), args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
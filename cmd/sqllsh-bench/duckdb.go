@@ -0,0 +1,12 @@
+//go:build duckdb
+
+package main
+
+import (
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+	_ "github.com/marcboeker/go-duckdb"
+)
+
+func init() {
+	backends["duckdb"] = backend{"duckdb", sqllsh.NewDuckdbLsh}
+}
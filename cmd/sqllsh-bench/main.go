@@ -0,0 +1,183 @@
+// Command sqllsh-bench loads Signatures into an index on any supported
+// database, then reports the insert throughput, the index build time
+// and the query latency percentiles, so that numbers are comparable
+// across databases and configurations.
+//
+// For example, with random Signatures on PostgreSQL:
+//
+//	sqllsh-bench -backend postgres -dsn "postgres://localhost/test?sslmode=disable" -k 4 -l 32 -n 100000
+//
+// The Signatures are random unless -input names a file with one
+// Signature per line, as k*l integers separated by spaces; the line
+// numbers, from 0, are the IDs.
+// The DuckDB, Oracle and Spanner backends are only built with the
+// duckdb, oracle and spanner build tags.
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// backend is a database the index can be benchmarked on.
+type backend struct {
+	driver string
+	newLsh func(k, l int, tableName string, db sqllsh.DB, opts ...sqllsh.Option) (*sqllsh.SqlLsh, error)
+}
+
+var backends = map[string]backend{
+	"sqlite":   {"sqlite3", sqllsh.NewSqliteLsh},
+	"postgres": {"postgres", sqllsh.NewPostgresLsh},
+	"redshift": {"postgres", sqllsh.NewRedshiftLsh},
+	"mysql":    {"mysql", sqllsh.NewMysqlLsh},
+	"tidb":     {"mysql", sqllsh.NewTidbLsh},
+	"mariadb":  {"mysql", sqllsh.NewMariadbLsh},
+	"vitess":   {"mysql", sqllsh.NewVitessLsh},
+}
+
+func main() {
+	name := flag.String("backend", "sqlite", "database: "+strings.Join(backendNames(), ", "))
+	dsn := flag.String("dsn", "sqllsh-bench.db", "data source name of the database")
+	table := flag.String("table", "lshbench", "table of the index, dropped first")
+	k := flag.Int("k", 4, "hash key size")
+	l := flag.Int("l", 32, "number of hash tables")
+	n := flag.Int("n", 10000, "number of random Signatures")
+	input := flag.String("input", "", "file of Signatures, instead of random ones")
+	queries := flag.Int("queries", 1000, "number of queries")
+	commit := flag.Int("commit", 0, "rows per transaction, see sqllsh.WithCommitSize")
+	flag.Parse()
+
+	b, ok := backends[*name]
+	if !ok {
+		log.Fatalf("Unknown backend %q", *name)
+	}
+	sigs := randomSigs(*n, *k**l)
+	if *input != "" {
+		var err error
+		if sigs, err = readSigs(*input, *k**l); err != nil {
+			log.Fatal(err)
+		}
+	}
+	db, err := sql.Open(b.driver, *dsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("DROP TABLE IF EXISTS " + *table); err != nil {
+		log.Fatal(err)
+	}
+	if _, err := db.Exec("DROP TABLE IF EXISTS " + *table + "_meta"); err != nil {
+		log.Fatal(err)
+	}
+	var opts []sqllsh.Option
+	if *commit > 0 {
+		opts = append(opts, sqllsh.WithCommitSize(*commit))
+	}
+	lsh, err := b.newLsh(*k, *l, *table, db, opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer lsh.Close()
+	if err := run(lsh, sigs, *queries); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run loads sigs into lsh, builds the indexes, and runs the queries of
+// randomly chosen Signatures, printing the measures.
+func run(lsh *sqllsh.SqlLsh, sigs []sqllsh.Signature, queries int) error {
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	start := time.Now()
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		return err
+	}
+	insert := time.Since(start)
+	fmt.Printf("insert: %d signatures in %v, %.0f signatures/s\n",
+		len(sigs), insert, float64(len(sigs))/insert.Seconds())
+	start = time.Now()
+	if err := lsh.Index(); err != nil {
+		return err
+	}
+	fmt.Printf("index: %v\n", time.Since(start))
+	latencies := make([]time.Duration, queries)
+	for i := range latencies {
+		sig := sigs[rand.Intn(len(sigs))]
+		start = time.Now()
+		if _, err := lsh.QueryIDs(sig); err != nil {
+			return err
+		}
+		latencies[i] = time.Since(start)
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	fmt.Printf("query: %d queries, p50 %v, p95 %v, p99 %v, max %v\n", queries,
+		sqllsh.Percentile(latencies, 50), sqllsh.Percentile(latencies, 95),
+		sqllsh.Percentile(latencies, 99), sqllsh.Percentile(latencies, 100))
+	return nil
+}
+
+func backendNames() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func randomSigs(n, size int) []sqllsh.Signature {
+	sigs := make([]sqllsh.Signature, n)
+	for i := range sigs {
+		sigs[i] = make(sqllsh.Signature, size)
+		for j := range sigs[i] {
+			sigs[i][j] = uint(rand.Int63())
+		}
+	}
+	return sigs
+}
+
+// readSigs reads the Signatures of size size in the file at path, one
+// per line.
+func readSigs(path string, size int) ([]sqllsh.Signature, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var sigs []sqllsh.Signature
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1<<24)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) != size {
+			return nil, fmt.Errorf("line %d: %d hash values, expected %d",
+				len(sigs)+1, len(fields), size)
+		}
+		sig := make(sqllsh.Signature, size)
+		for j, field := range fields {
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", len(sigs)+1, err)
+			}
+			sig[j] = uint(v)
+		}
+		sigs = append(sigs, sig)
+	}
+	return sigs, s.Err()
+}
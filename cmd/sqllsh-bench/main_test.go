@@ -0,0 +1,46 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_ReadSigs(t *testing.T) {
+	dir, err := os.MkdirTemp("", "sqllsh-bench")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sigs.txt")
+	if err := os.WriteFile(path, []byte("1 2 3 4\n5 6 7 8\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sigs, err := readSigs(path, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sigs) != 2 || sigs[1][3] != 8 {
+		t.Errorf("Unexpected Signatures %v", sigs)
+	}
+	if _, err := readSigs(path, 3); err == nil {
+		t.Error("Expected an error for Signatures of the wrong size")
+	}
+}
+
+func Test_Run(t *testing.T) {
+	b := backends["sqlite"]
+	db, err := sql.Open(b.driver, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := b.newLsh(2, 4, "lshbench", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := run(lsh, randomSigs(100, 8), 10); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build oracle
+
+package main
+
+import (
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+	_ "github.com/godror/godror"
+)
+
+func init() {
+	backends["oracle"] = backend{"godror", sqllsh.NewOracleLsh}
+}
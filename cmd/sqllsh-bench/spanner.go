@@ -0,0 +1,12 @@
+//go:build spanner
+
+package main
+
+import (
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+	_ "github.com/googleapis/go-sql-spanner"
+)
+
+func init() {
+	backends["spanner"] = backend{"spanner", sqllsh.NewSpannerLsh}
+}
@@ -0,0 +1,115 @@
+package sqllsh
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Conflict is the behavior when inserting an ID that is already in
+// the table.
+type Conflict int
+
+const (
+	// ConflictError fails the insert with ErrIDExists.
+	ConflictError Conflict = iota
+	// ConflictIgnore keeps the existing Signature and skips the insert.
+	ConflictIgnore
+	// ConflictReplace replaces the existing Signature.
+	ConflictReplace
+	// ConflictIdempotent skips the insert if the existing Signature is
+	// the same, and fails it with an *IDConflictError otherwise, so that
+	// the Signatures delivered more than once by a pipeline can be
+	// inserted again safely. The Signatures are read back after being
+	// inserted, in the same transaction. Use ConflictReplace to update
+	// the different Signatures instead.
+	// It is not supported with WithBandKeys, and with WithBatchWriter
+	// and WithPurgeWriter, whose writer commits the rows before they can
+	// be compared.
+	ConflictIdempotent
+)
+
+// WithConflict sets the behavior when inserting an ID that is already
+// in the table. The default is ConflictError.
+func WithConflict(c Conflict) Option {
+	return func(lsh *SqlLsh) {
+		lsh.conflict = c
+	}
+}
+
+// sqlConflict returns the conflict behavior of the insert statements:
+// ConflictIdempotent inserts like ConflictIgnore, then compares the
+// Signatures with checkInserted.
+func (lsh *SqlLsh) sqlConflict() Conflict {
+	if lsh.conflict == ConflictIdempotent {
+		return ConflictIgnore
+	}
+	return lsh.conflict
+}
+
+// checkInserted returns an *IDConflictError if the Signature stored
+// for one of ids, read with q, is not the one at the same position in
+// sigs, with ConflictIdempotent.
+func (lsh *SqlLsh) checkInserted(q rowQuerier, ids []int, sigs []Signature) error {
+	if lsh.conflict != ConflictIdempotent {
+		return nil
+	}
+	stored, err := lsh.signaturesIn(q, ids)
+	if err != nil {
+		return err
+	}
+	for i, id := range ids {
+		sig := sigs[i]
+		if lsh.bits > 0 {
+			sig = truncateBits(sig, lsh.bits)
+		}
+		if !equalSigs(stored[id], sig) {
+			return &IDConflictError{Id: id}
+		}
+	}
+	return nil
+}
+
+// equalSigs returns whether a and b hold the same hash values.
+func equalSigs(a, b Signature) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// onConflictClause returns the ON CONFLICT clause of an insert,
+// as supported by SQLite and PostgreSQL.
+// keys are the columns of the primary key, and cols are the columns
+// updated by ConflictReplace.
+func onConflictClause(c Conflict, keys, cols []string) string {
+	target := " ON CONFLICT (" + strings.Join(keys, ", ") + ")"
+	switch c {
+	case ConflictIgnore:
+		return target + " DO NOTHING"
+	case ConflictReplace:
+		seg := make([]string, len(cols))
+		for i, col := range cols {
+			seg[i] = fmt.Sprintf("%s = excluded.%s", col, col)
+		}
+		return target + " DO UPDATE SET " + strings.Join(seg, ", ")
+	}
+	return ""
+}
+
+// invalidate evicts the cached query results affected by inserting
+// sigs with ids.
+func (lsh *SqlLsh) invalidate(ids []int, sigs []Signature) {
+	c := lsh.sharedCache()
+	c.invalidateSigs(lsh.k, sigs...)
+	if lsh.conflict == ConflictReplace {
+		// The replaced Signatures may be in other results
+		for _, id := range ids {
+			c.invalidateID(id)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func Test_Conflict(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	oldSig := Signature{1, 2, 3, 4}
+	newSig := Signature{5, 6, 7, 8}
+	for _, c := range []struct {
+		table    string
+		conflict Conflict
+		err      error
+		stored   Signature
+	}{
+		{"errortable", ConflictError, ErrIDExists, oldSig},
+		{"ignoretable", ConflictIgnore, nil, oldSig},
+		{"replacetable", ConflictReplace, nil, newSig},
+	} {
+		lsh, err := NewSqliteLsh(2, 2, c.table, db, WithConflict(c.conflict))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := lsh.Insert(0, oldSig); err != nil {
+			t.Error(err)
+		}
+		err = lsh.Insert(0, newSig)
+		if !errors.Is(err, c.err) || (c.err == nil && err != nil) {
+			t.Errorf("%s: expected error %v, got %v", c.table, c.err, err)
+		}
+		err = lsh.BatchInsert([]int{0, 1}, []Signature{newSig, oldSig})
+		if !errors.Is(err, c.err) || (c.err == nil && err != nil) {
+			t.Errorf("%s: expected error %v, got %v", c.table, c.err, err)
+		}
+		ids, err := lsh.QueryIDs(c.stored)
+		if err != nil {
+			t.Error(err)
+		}
+		found := false
+		for _, id := range ids {
+			if id == 0 {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: stored signature not found", c.table)
+		}
+	}
+	removeTempFile(t, f)
+}
+
+func Test_ConflictIdempotent(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithConflict(ConflictIdempotent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldSig := Signature{1, 2, 3, 4}
+	newSig := Signature{5, 6, 7, 8}
+	if err := lsh.Insert(0, oldSig); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Insert(0, oldSig); err != nil {
+		t.Errorf("Expected re-insert to succeed, got %v", err)
+	}
+	if err := lsh.BatchInsert([]int{0, 1}, []Signature{oldSig, newSig}); err != nil {
+		t.Errorf("Expected re-insert to succeed, got %v", err)
+	}
+	var conflictErr *IDConflictError
+	err = lsh.Insert(1, oldSig)
+	if !errors.As(err, &conflictErr) || conflictErr.Id != 1 || !errors.Is(err, ErrIDExists) {
+		t.Errorf("Expected IDConflictError for ID 1, got %v", err)
+	}
+	// The conflicting batch is rolled back
+	err = lsh.BatchInsert([]int{2, 0}, []Signature{oldSig, newSig})
+	if !errors.As(err, &conflictErr) || conflictErr.Id != 0 {
+		t.Errorf("Expected IDConflictError for ID 0, got %v", err)
+	}
+	sigs, err := lsh.BatchGet([]int{0, 1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sigs) != 2 || !sameSig(sigs[0], oldSig) || !sameSig(sigs[1], newSig) {
+		t.Errorf("Unexpected stored Signatures %v", sigs)
+	}
+	if _, err := NewSqliteLsh(2, 2, "keystable", db, WithBandKeys(),
+		WithConflict(ConflictIdempotent)); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+	// A BatchWriter commits the rows before they can be compared
+	writer := func(table string, cols []string, rows [][]interface{}, c Conflict) error {
+		return nil
+	}
+	if _, err := NewSqliteLsh(2, 2, "writertable", db, WithBatchWriter(writer),
+		WithConflict(ConflictIdempotent)); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported with a BatchWriter, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// CountCandidates returns the number of Signatures that have at least
+// one hash key collison with the query Signature, without fetching
+// their IDs.
+// It can be used to detect query Signatures with too many candidates
+// before running Query.
+func (lsh *SqlLsh) CountCandidates(sig Signature) (int64, error) {
+	sig, err := lsh.beforeQuery("count", sig)
+	if err != nil {
+		return 0, err
+	}
+	if len(sig) != lsh.k*lsh.l {
+		return 0, ErrSignatureSizeMismatch
+	}
+	if err := lsh.ready(); err != nil {
+		return 0, err
+	}
+	bands := lsh.maybeBands(sig)
+	if bands != nil && len(bands) == 0 {
+		return 0, nil
+	}
+	// The prepared statement counts the collisions on all the bands
+	stmt := lsh.countStmt
+	if bands == nil || len(bands) == lsh.l {
+		bands = lsh.allBands()
+	} else {
+		stmt = nil
+	}
+	return lsh.countBands(stmt, sig, bands, lsh.k)
+}
+
+// countBands returns the number of IDs colliding with sig in the given
+// bands, using the first prefix hash values of each hash key, with the
+// prepared statement stmt of the query if it is not nil.
+func (lsh *SqlLsh) countBands(stmt *sql.Stmt, sig Signature, bands []int, prefix int) (int64, error) {
+	s, err := lsh.beginQuery()
+	if err != nil {
+		return 0, err
+	}
+	defer s.end()
+	rows, err := s.query(stmt, lsh.countStr(bands, prefix), lsh.queryArgs(sig, bands, prefix)...)
+	if err != nil {
+		return 0, wrapErr("count", err)
+	}
+	defer rows.Close()
+	var n int64
+	for rows.Next() {
+		if err := rows.Scan(&n); err != nil {
+			return 0, wrapErr("count", err)
+		}
+	}
+	return n, wrapErr("count", rows.Err())
+}
+
+// countStr returns the query counting the collisions on the given
+// bands, using the first prefix hash values of each hash key.
+func (lsh *SqlLsh) countStr(bands []int, prefix int) string {
+	return fmt.Sprintf("SELECT COUNT(DISTINCT id) FROM %s WHERE %s%s",
+		lsh.tableName, lsh.bandsCond(bands, prefix), lsh.filterCond(len(bands)*lsh.bandWidth(prefix)))
+}
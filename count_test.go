@@ -0,0 +1,36 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_CountCandidates(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Error(err)
+	}
+	lsh.Insert(0, Signature{1, 2, 3, 4})
+	lsh.Insert(1, Signature{1, 2, 0, 0})
+	lsh.Insert(2, Signature{0, 0, 3, 4})
+	lsh.Insert(3, Signature{0, 0, 0, 0})
+	for sig, n := range map[[4]uint]int64{
+		{1, 2, 3, 4}: 3,
+		{1, 2, 9, 9}: 2,
+		{9, 9, 9, 9}: 0,
+	} {
+		count, err := lsh.CountCandidates(sig[:])
+		if err != nil {
+			t.Error(err)
+		}
+		if count != n {
+			t.Errorf("Expected %d candidates for %v, got %d", n, sig, count)
+		}
+	}
+	removeTempFile(t, f)
+}
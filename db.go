@@ -0,0 +1,125 @@
+package sqllsh
+
+import (
+	"context"
+	"database/sql"
+	"io"
+)
+
+// DB is the database connection an index runs its statements on.
+// It is satisfied by *sql.DB, by *sql.Conn, to pin the index to one
+// connection, as temporary tables and some connection poolers need,
+// and by *sql.Tx, to run the index inside an existing transaction.
+// Inside a *sql.Tx, the index never commits nor rolls back: its writes
+// are committed or rolled back with the transaction by the caller,
+// which must roll it back if a method of the index fails.
+// With a *sql.Tx or a *sql.Conn, the constructors return ErrUnsupported
+// for WithInsertWorkers, PlanParallel and WithRetry.
+type DB interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// pinned reports whether db runs all its statements on one connection,
+// as a *sql.Tx and a *sql.Conn do.
+func pinned(db DB) bool {
+	switch db.(type) {
+	case *sql.Tx, *sql.Conn:
+		return true
+	}
+	return false
+}
+
+// dbConn runs the statements of an index on its DB.
+type dbConn struct {
+	DB
+	ctx context.Context // Context of the statements, set by WithContext, nil if none
+}
+
+// context returns the context of the statements.
+func (c dbConn) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+func (c dbConn) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return c.ExecContext(c.context(), query, args...)
+}
+
+func (c dbConn) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return c.QueryContext(c.context(), query, args...)
+}
+
+func (c dbConn) QueryRow(query string, args ...interface{}) *sql.Row {
+	return c.QueryRowContext(c.context(), query, args...)
+}
+
+func (c dbConn) Prepare(query string) (*sql.Stmt, error) {
+	return c.PrepareContext(c.context(), query)
+}
+
+// Begin starts a transaction, or joins the transaction of the caller
+// if the DB is a *sql.Tx.
+func (c dbConn) Begin() (*txn, error) {
+	return c.BeginTx(nil)
+}
+
+// BeginTx is like Begin with the options of the transaction, which are
+// ignored when joining the transaction of the caller.
+func (c dbConn) BeginTx(opts *sql.TxOptions) (*txn, error) {
+	switch db := c.DB.(type) {
+	case *sql.Tx:
+		return &txn{Tx: db, joined: true}, nil
+	case interface {
+		BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error)
+	}:
+		tx, err := db.BeginTx(c.context(), opts)
+		if err != nil {
+			return nil, err
+		}
+		return &txn{Tx: tx}, nil
+	}
+	return nil, ErrUnsupported
+}
+
+// Ping checks the connection, if the DB can be pinged.
+func (c dbConn) Ping() error {
+	if p, ok := c.DB.(interface{ PingContext(context.Context) error }); ok {
+		return p.PingContext(c.context())
+	}
+	return nil
+}
+
+// Close closes the DB, if it can be closed.
+func (c dbConn) Close() error {
+	if closer, ok := c.DB.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// txn is a transaction of an index.
+type txn struct {
+	*sql.Tx
+	joined bool // Whether the transaction is the caller's
+}
+
+// Commit commits the transaction, unless it is the caller's.
+func (tx *txn) Commit() error {
+	if tx.joined {
+		return nil
+	}
+	return tx.Tx.Commit()
+}
+
+// Rollback rolls the transaction back, unless it is the caller's.
+func (tx *txn) Rollback() error {
+	if tx.joined {
+		return nil
+	}
+	return tx.Tx.Rollback()
+}
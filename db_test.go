@@ -0,0 +1,88 @@
+package sqllsh
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+func Test_DBConn(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for _, opt := range []Option{WithInsertWorkers(2), WithQueryPlan(PlanParallel), WithRetry(RetryPolicy{MaxAttempts: 2})} {
+		if _, err := NewSqliteLsh(2, 3, "lshtable", conn, opt); err != ErrUnsupported {
+			t.Errorf("Expected ErrUnsupported, got %v", err)
+		}
+	}
+	lsh, err := NewAutoLsh(2, 3, "lshtable", conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lsh.Close()
+	sigs := randomSigs(10, 6)
+	ids := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	found, err := lsh.QueryIDs(sigs[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsID(found, 3) {
+		t.Errorf("Expected 3 in %v", found)
+	}
+}
+
+func Test_DBTx(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewAutoLsh(2, 3, "lshtable", tx); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+	if _, err := NewSqliteLsh(2, 3, "lshtable", tx, WithRetry(RetryPolicy{MaxAttempts: 2})); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(10, 6)
+	ids := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	found, err := lsh.QueryIDs(sigs[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsID(found, 3) {
+		t.Errorf("Expected 3 in %v", found)
+	}
+	lsh.Close()
+	// The table is rolled back with the transaction of the caller
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("SELECT id FROM lshtable"); err == nil {
+		t.Error("Expected the table to be rolled back")
+	}
+}
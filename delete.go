@@ -0,0 +1,224 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Delete removes the Signature with id from the table.
+// With WithSoftDelete the entry is only marked as deleted, and is
+// excluded from Query and Scan until it is purged by Compact.
+// Deleting an id that does not exist is not an error.
+func (lsh *SqlLsh) Delete(id int) error {
+	done := lsh.observe("delete")
+	err := lsh.delete(id)
+	done(1, err)
+	return err
+}
+
+func (lsh *SqlLsh) delete(id int) error {
+	tx, err := lsh.db.Begin()
+	if err != nil {
+		return wrapErr("delete", err)
+	}
+	_, err = lsh.exec(tx.Tx, lsh.deleteStmt, lsh.deleteStr(), id)
+	if err != nil {
+		tx.Rollback()
+		return wrapErr("delete", err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return wrapErr("delete", err)
+	}
+	lsh.sharedCache().invalidateID(id)
+	return nil
+}
+
+// BatchDelete removes the Signatures with ids from the table in one
+// transaction, as Delete does for one id.
+// The IDs are deleted with as few statements as the parameter limit of
+// the database allows.
+func (lsh *SqlLsh) BatchDelete(ids []int) error {
+	done := lsh.observe("batch delete")
+	err := lsh.batchDelete(ids)
+	done(int64(len(ids)), err)
+	return err
+}
+
+func (lsh *SqlLsh) batchDelete(ids []int) error {
+	if len(ids) == 0 {
+		return ErrEmptyBatch
+	}
+	tx, err := lsh.db.Begin()
+	if err != nil {
+		return wrapErr("batch delete", err)
+	}
+	err = lsh.inChunks(len(ids), func(i int) interface{} { return ids[i] },
+		func(in string, args []interface{}) error {
+			_, err := tx.Exec(lsh.batchDeleteStr(in), args...)
+			return err
+		})
+	if err != nil {
+		tx.Rollback()
+		return wrapErr("batch delete", err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return wrapErr("batch delete", err)
+	}
+	for _, id := range ids {
+		lsh.sharedCache().invalidateID(id)
+	}
+	return nil
+}
+
+// DeleteWhere removes the Signatures whose rows match the SQL condition
+// pred, such as "tenant = ?" on a column added to the table by its
+// users, in one statement, and returns the number of Signatures
+// removed.
+// The arguments of pred are given by args, with ? placeholders, as for
+// Where, and pred is written in the SQL as is, so it must not come from
+// untrusted input. On a view returned by Where, the condition of the
+// view is ANDed with pred, and the results cached by lsh are evicted
+// as well.
+// With WithSoftDelete the entries are only marked as deleted, as by
+// Delete.
+func (lsh *SqlLsh) DeleteWhere(pred string, args ...interface{}) (int64, error) {
+	done := lsh.observe("delete where")
+	n, err := lsh.deleteWhere(pred, args)
+	done(n, err)
+	return n, err
+}
+
+func (lsh *SqlLsh) deleteWhere(pred string, args []interface{}) (int64, error) {
+	tx, err := lsh.db.Begin()
+	if err != nil {
+		return 0, wrapErr("delete where", err)
+	}
+	res, err := tx.Exec(lsh.deleteWhereStr(pred, len(args)),
+		append(append([]interface{}(nil), args...), lsh.filterArgs()...)...)
+	if err != nil {
+		tx.Rollback()
+		return 0, wrapErr("delete where", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return 0, wrapErr("delete where", err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return 0, wrapErr("delete where", err)
+	}
+	// The results cached by the index of a view are evicted too
+	lsh.sharedCache().clear()
+	return n, nil
+}
+
+// deleteWhereStr returns the statement of DeleteWhere for pred, which
+// has n arguments before those of the condition of the view.
+func (lsh *SqlLsh) deleteWhereStr(pred string, n int) string {
+	cond := "(" + lsh.numberVars(pred, 0) + ")" + lsh.filterCond(n)
+	if lsh.softDelete {
+		return fmt.Sprintf("UPDATE %s SET deleted = 1 WHERE %sdeleted = 0 AND %s",
+			lsh.tableName, lsh.scopeCond(), cond)
+	}
+	return fmt.Sprintf("DELETE FROM %s WHERE %s%s", lsh.tableName, lsh.scopeCond(), cond)
+}
+
+// Compact purges the entries marked deleted, then rebuilds the
+// indexes of the table.
+// It returns the number of entries purged.
+func (lsh *SqlLsh) Compact() (int64, error) {
+	done := lsh.observe("compact")
+	n, err := lsh.compact()
+	done(n, err)
+	return n, err
+}
+
+func (lsh *SqlLsh) compact() (int64, error) {
+	tx, err := lsh.db.Begin()
+	if err != nil {
+		return 0, wrapErr("compact", err)
+	}
+	var n int64
+	if lsh.softDelete {
+		res, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE deleted = 1", lsh.tableName))
+		if err != nil {
+			tx.Rollback()
+			return 0, wrapErr("compact", err)
+		}
+		n, err = res.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return 0, wrapErr("compact", err)
+		}
+	}
+	if lsh.dialect.reindexFmt != "" && !lsh.dialect.implicitCommit {
+		_, err = tx.Exec(fmt.Sprintf(lsh.dialect.reindexFmt, lsh.tableName))
+		if err != nil {
+			tx.Rollback()
+			return 0, wrapErr("compact", err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return 0, wrapErr("compact", err)
+	}
+	lsh.sharedCache().clear()
+	if lsh.dialect.reindexFmt != "" && lsh.dialect.implicitCommit {
+		// The indexes are rebuilt once the purge is committed, as the
+		// statement would commit it
+		if _, err := lsh.db.Exec(fmt.Sprintf(lsh.dialect.reindexFmt, lsh.tableName)); err != nil {
+			return n, wrapErr("compact", err)
+		}
+	}
+	return n, nil
+}
+
+// purge removes the row of a deleted entry with id inside tx,
+// so the id can be inserted again.
+func (lsh *SqlLsh) purge(tx *sql.Tx, id int) error {
+	if !lsh.softDelete {
+		return nil
+	}
+	_, err := lsh.exec(tx, lsh.purgeStmt, lsh.purgeStr(), id)
+	return err
+}
+
+func (lsh *SqlLsh) createDeleteStmt() (*sql.Stmt, error) {
+	return lsh.db.Prepare(lsh.deleteStr())
+}
+
+func (lsh *SqlLsh) deleteStr() string {
+	if lsh.softDelete {
+		return fmt.Sprintf("UPDATE %s SET deleted = 1 WHERE %sid = %s",
+			lsh.tableName, lsh.scopeCond(), lsh.dialect.varFmt(0))
+	}
+	return fmt.Sprintf("DELETE FROM %s WHERE %sid = %s",
+		lsh.tableName, lsh.scopeCond(), lsh.dialect.varFmt(0))
+}
+
+// batchDeleteStr returns the statement of deleteStr for the IDs in the
+// IN list in.
+func (lsh *SqlLsh) batchDeleteStr(in string) string {
+	if lsh.softDelete {
+		return fmt.Sprintf("UPDATE %s SET deleted = 1 WHERE %sid IN (%s)",
+			lsh.tableName, lsh.scopeCond(), in)
+	}
+	return fmt.Sprintf("DELETE FROM %s WHERE %sid IN (%s)",
+		lsh.tableName, lsh.scopeCond(), in)
+}
+
+func (lsh *SqlLsh) createPurgeStmt() (*sql.Stmt, error) {
+	return lsh.db.Prepare(lsh.purgeStr())
+}
+
+func (lsh *SqlLsh) purgeStr() string {
+	return fmt.Sprintf("DELETE FROM %s WHERE %sid = %s AND deleted = 1",
+		lsh.tableName, lsh.scopeCond(), lsh.dialect.varFmt(0))
+}
@@ -0,0 +1,246 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func Test_Delete(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Error(err)
+	}
+	sig := Signature{0, 1, 2, 3}
+	if err := lsh.Insert(1, sig); err != nil {
+		t.Error(err)
+	}
+	if err := lsh.Delete(1); err != nil {
+		t.Error(err)
+	}
+	ids, err := lsh.QueryIDs(sig)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(ids) != 0 {
+		t.Error("Deleted entry returned by query")
+	}
+	if err := lsh.Insert(1, sig); err != nil {
+		t.Error(err)
+	}
+	removeTempFile(t, f)
+}
+
+func Test_SoftDelete(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithSoftDelete())
+	if err != nil {
+		t.Error(err)
+	}
+	sigs := randomSigs(10, 4)
+	for i := range sigs {
+		if err := lsh.Insert(i, sigs[i]); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := lsh.Index(); err != nil {
+		t.Error(err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := lsh.Delete(i); err != nil {
+			t.Error(err)
+		}
+	}
+	ids, err := lsh.QueryIDs(sigs[0])
+	if err != nil {
+		t.Error(err)
+	}
+	if len(ids) != 0 {
+		t.Error("Deleted entry returned by query")
+	}
+	it, err := lsh.ScanIter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for it.Next() {
+		if it.Value().Id < 5 {
+			t.Error("Deleted entry returned by scan")
+		}
+		count++
+	}
+	if count != 5 {
+		t.Errorf("Expected 5 entries, got %d", count)
+	}
+	// Re-inserting a deleted id replaces the tombstone
+	if err := lsh.Insert(0, sigs[0]); err != nil {
+		t.Error(err)
+	}
+	n, err := lsh.Compact()
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 4 {
+		t.Errorf("Expected 4 purged entries, got %d", n)
+	}
+	ids, err = lsh.QueryIDs(sigs[0])
+	if err != nil {
+		t.Error(err)
+	}
+	if len(ids) != 1 || ids[0] != 0 {
+		t.Error("Error in query after compact")
+	}
+	removeTempFile(t, f)
+}
+
+func Test_BatchGetDelete(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// More IDs than the parameters Sqlite accepts in a statement
+	sigs := randomSigs(2500, 4)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	chunks := 0
+	lsh.inChunks(len(ids), func(i int) interface{} { return ids[i] },
+		func(in string, args []interface{}) error {
+			chunks++
+			return nil
+		})
+	if chunks != 3 {
+		t.Errorf("Expected 3 chunks, got %d", chunks)
+	}
+	got, err := lsh.BatchGet(append(ids, -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(ids) {
+		t.Errorf("Expected %d Signatures, got %d", len(ids), len(got))
+	}
+	if !sameSig(got[2000], sigs[2000]) {
+		t.Errorf("Expected %v, got %v", sigs[2000], got[2000])
+	}
+	if err := lsh.BatchDelete(ids[:2000]); err != nil {
+		t.Fatal(err)
+	}
+	exist, err := lsh.ExistIDs(ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, ok := range exist {
+		if ok != (id >= 2000) {
+			t.Errorf("Expected %d to exist: %v", id, id >= 2000)
+		}
+	}
+	if err := lsh.BatchDelete(nil); err != ErrEmptyBatch {
+		t.Errorf("Expected ErrEmptyBatch, got %v", err)
+	}
+}
+
+func Test_DeleteWhere(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, soft := range []bool{false, true} {
+		db.Exec("DROP TABLE lshtable")
+		db.Exec("DROP TABLE lshtable_meta")
+		var opts []Option
+		if soft {
+			opts = append(opts, WithSoftDelete())
+		}
+		lsh, err := NewSqliteLsh(2, 2, "lshtable", db, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs := []Signature{{1, 2, 3, 4}, {1, 2, 5, 6}, {1, 2, 7, 8}}
+		if err := lsh.BatchInsert([]int{0, 1, 2}, sigs); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec("ALTER TABLE lshtable ADD COLUMN tenant TEXT"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec("UPDATE lshtable SET tenant = CASE WHEN id = 1 THEN 'b' ELSE 'a' END"); err != nil {
+			t.Fatal(err)
+		}
+		n, err := lsh.DeleteWhere("tenant = ?", "a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 2 {
+			t.Errorf("soft delete %v: expected 2 deleted, got %d", soft, n)
+		}
+		ids, err := lsh.QueryIDs(Signature{1, 2, 0, 0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) != 1 || ids[0] != 1 {
+			t.Errorf("soft delete %v: expected only 1, got %v", soft, ids)
+		}
+		// The condition of a view is ANDed with the predicate
+		n, err = lsh.Where("id > ?", 5).DeleteWhere("tenant = ?", "b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 0 {
+			t.Errorf("soft delete %v: expected 0 deleted, got %d", soft, n)
+		}
+	}
+}
+
+func Test_DeleteWhereViewCache(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithQueryCache(10, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.BatchInsert([]int{0, 1}, []Signature{{1, 2, 3, 4}, {1, 2, 5, 6}}); err != nil {
+		t.Fatal(err)
+	}
+	query := Signature{1, 2, 0, 0}
+	// Cache the result in the parent index
+	if ids, err := lsh.QueryIDs(query); err != nil || len(ids) != 2 {
+		t.Fatalf("Expected 2 IDs, got %v, %v", ids, err)
+	}
+	if _, err := lsh.Where("id = ?", 1).DeleteWhere("1 = 1"); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := lsh.QueryIDs(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != 0 {
+		t.Errorf("Expected only 0 after the delete through the view, got %v", ids)
+	}
+}
@@ -0,0 +1,93 @@
+package sqllsh
+
+import "database/sql"
+
+// dialect holds the parts of the SQL that differ between databases.
+type dialect struct {
+	varFmt         func(int) string // Formatter for placeholder
+	intType        string           // Type of the hash value columns
+	blobType       string           // Type of a binary column
+	limitFmt       string           // Clause limiting the rows of a query, takes the number of rows
+	createIndexFmt string           // Prefix of CREATE INDEX, takes index name and table name, empty if no indexes
+	dropIndexFmt   string           // Statement dropping an index, takes index name and table name
+	reindexFmt     string           // Statement rebuilding the indexes, takes table name, empty if none
+	sortFmt        string           // Statement run by Index without createIndexFmt, takes table name
+	analyzeFmt     string           // Statement refreshing the statistics, takes table name
+	explainPrefix  string           // Prefix of a query returning its plan
+	plan           QueryPlan        // Query plan used for PlanAuto
+	limitDelete    bool             // Whether DELETE takes a LIMIT
+	autoIDType     string           // Type of a generated id column, empty if unsupported
+	returning      bool             // Whether an insert can return the generated id
+	maxBatch       int              // Rows per transaction without WithCommitSize, 0 for no limit
+	maxValues      int              // Values per transaction without WithCommitSize, 0 for no limit
+	maxParams      int              // Parameters per statement, 0 if unknown
+	maxIdent       int              // Length of the identifiers, 0 if unlimited
+	multiRowValues int              // Values per multi-row insert of BatchInsert, 0 to insert rows one at a time
+	includeClause  string           // Clause adding the id to an index, empty if always there
+	// Clause following the table name in CREATE INDEX for each supported
+	// index type
+	indexMethods map[IndexType]string
+	// Keyword before TABLE in CREATE TABLE for each supported table kind
+	// other than TablePermanent
+	tableKinds map[TableKind]string
+	// Clause appended to the generic CREATE TABLE, nil if none
+	tableOptions func(lsh *SqlLsh) string
+	// Overrides the generic CREATE TABLE, nil if not used
+	createTable func(lsh *SqlLsh) string
+	// Runs DDL statements, for databases that cannot run them in a
+	// transaction, nil if not used
+	ddl func(db DB, stmts []string) error
+	// Clause appended to an insert for the conflict behavior, nil if only
+	// ConflictError is supported
+	conflictClause func(c Conflict, keys, cols []string) string
+	// Start of an insert for the conflict behavior, for databases
+	// resolving conflicts with another statement, nil to use INSERT INTO
+	// and conflictClause
+	insertVerb func(c Conflict) string
+	// Query of the index names of the table given as argument, empty if
+	// not available
+	indexesQuery string
+	// Query of the table names of the schema, empty if not available
+	tablesQuery string
+	// Whether the table can be partitioned by namespace
+	partitions bool
+	// Whether SqliteOptions can be used
+	pragmas bool
+	// Whether CREATE TABLE lacks IF NOT EXISTS, so the tables are looked
+	// up before being created
+	probeTables bool
+	// Statement setting the timeout of the queries of a transaction, in
+	// milliseconds, empty if the database has none
+	timeoutFmt string
+	// Statement sending a notification, takes the placeholders of the
+	// channel and the payload, empty if the database has none
+	notifyFmt string
+	// Whether DDL statements commit the transaction they run in, so they
+	// are run outside of transactions
+	implicitCommit bool
+	// Converts a plan returned as one JSON value to one line per step,
+	// nil if the plan has one step per row
+	explainJSON func(plan string) (string, error)
+	// Whether a transaction can be started at the serializable isolation
+	// level; the transactions of the other databases are serializable
+	// already, or cannot be
+	serializable bool
+	// Statement run by Index instead of sortFmt when the table has
+	// several hash tables, and so an interleaved sort key, takes table
+	// name, empty if the database has none
+	interleavedSortFmt string
+	// Isolation level of a transaction reading a consistent snapshot of
+	// the table, sql.LevelDefault if all the transactions do
+	snapshot sql.IsolationLevel
+	// Statement making the generated ids follow the largest id of the
+	// table, takes table name, empty if the database does so already
+	restartIDFmt string
+	// Statement declaring a column NOT NULL, takes table and column
+	// names, empty to add the columns NOT NULL with a default instead
+	setNotNullFmt string
+	// Whether the placeholder style can be set with WithPlaceholder
+	placeholders bool
+	// Adjustments of the dialect for compatible databases, see
+	// WithPostgresVariant
+	variants map[PostgresVariant]func(d dialect) dialect
+}
@@ -0,0 +1,186 @@
+package sqllsh
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Statement is a SQL statement recorded by a Recorder, with the
+// arguments it was run with.
+type Statement struct {
+	Query string
+	Args  []interface{}
+	Tx    bool // Whether the statement ran inside a transaction
+}
+
+// Recorder is a DB that records the statements run on it instead of
+// sending them to a database, for unit tests of the code using an
+// index and for reviewing the DDL of an index before running it.
+// Passing it to a constructor, such as NewPostgresLsh, creates an index
+// in dry-run mode: the statements are generated for the dialect of the
+// constructor as usual, but every Exec succeeds affecting no rows and
+// every query returns no rows.
+// So the constructor records the DDL creating the table and its
+// metadata, and queries find nothing.
+// NewAutoLsh and NewManager use the dialect of the driver given by
+// Driver.
+type Recorder struct {
+	*sql.DB
+	// Import path of the driver whose dialect NewAutoLsh and NewManager
+	// use, such as "github.com/lib/pq", or empty for the standard SQL
+	// of NewAnsiLsh
+	Driver string
+	mu     sync.Mutex
+	stmts  []Statement
+}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder() *Recorder {
+	r := &Recorder{}
+	r.DB = sql.OpenDB(recordConnector{r})
+	return r
+}
+
+// Statements returns the statements recorded so far, in the order they
+// were run. Prepared statements are recorded each time they are run.
+func (r *Recorder) Statements() []Statement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Statement(nil), r.stmts...)
+}
+
+// Script returns the queries of the recorded statements as a script,
+// each terminated by a semicolon. The arguments are not included.
+func (r *Recorder) Script() string {
+	var b strings.Builder
+	for _, stmt := range r.Statements() {
+		b.WriteString(stmt.Query)
+		b.WriteString(";\n")
+	}
+	return b.String()
+}
+
+// Reset forgets the statements recorded so far.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.stmts = nil
+	r.mu.Unlock()
+}
+
+func (r *Recorder) record(query string, args []driver.NamedValue, tx bool) {
+	stmt := Statement{Query: query, Args: make([]interface{}, len(args)), Tx: tx}
+	for i, arg := range args {
+		stmt.Args[i] = arg.Value
+	}
+	r.mu.Lock()
+	r.stmts = append(r.stmts, stmt)
+	r.mu.Unlock()
+}
+
+// recordConnector opens the connections of a Recorder.
+type recordConnector struct {
+	r *Recorder
+}
+
+func (c recordConnector) Connect(context.Context) (driver.Conn, error) {
+	return &recordConn{r: c.r}, nil
+}
+
+func (c recordConnector) Driver() driver.Driver {
+	return recordDriver{}
+}
+
+type recordDriver struct{}
+
+func (recordDriver) Open(string) (driver.Conn, error) {
+	return nil, ErrUnsupported
+}
+
+// recordConn is a connection of a Recorder, recording its statements.
+type recordConn struct {
+	r  *Recorder
+	tx bool // Whether a transaction is open
+}
+
+func (c *recordConn) Prepare(query string) (driver.Stmt, error) {
+	return recordStmt{c, query}, nil
+}
+
+func (c *recordConn) Close() error { return nil }
+
+func (c *recordConn) Begin() (driver.Tx, error) {
+	c.tx = true
+	return recordTx{c}, nil
+}
+
+// BeginTx accepts any isolation level, which is not recorded.
+func (c *recordConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.Begin()
+}
+
+func (c *recordConn) ExecContext(ctx context.Context, query string,
+	args []driver.NamedValue) (driver.Result, error) {
+	c.r.record(query, args, c.tx)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recordConn) QueryContext(ctx context.Context, query string,
+	args []driver.NamedValue) (driver.Rows, error) {
+	c.r.record(query, args, c.tx)
+	return recordRows{}, nil
+}
+
+type recordStmt struct {
+	c     *recordConn
+	query string
+}
+
+func (s recordStmt) Close() error { return nil }
+
+// NumInput returns -1, so any number of arguments is accepted.
+func (s recordStmt) NumInput() int { return -1 }
+
+func (s recordStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+
+func (s recordStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, driver.ErrSkip
+}
+
+func (s recordStmt) ExecContext(ctx context.Context,
+	args []driver.NamedValue) (driver.Result, error) {
+	s.c.r.record(s.query, args, s.c.tx)
+	return driver.RowsAffected(0), nil
+}
+
+func (s recordStmt) QueryContext(ctx context.Context,
+	args []driver.NamedValue) (driver.Rows, error) {
+	s.c.r.record(s.query, args, s.c.tx)
+	return recordRows{}, nil
+}
+
+type recordTx struct {
+	c *recordConn
+}
+
+func (tx recordTx) Commit() error {
+	tx.c.tx = false
+	return nil
+}
+
+func (tx recordTx) Rollback() error {
+	tx.c.tx = false
+	return nil
+}
+
+// recordRows is an empty result set.
+type recordRows struct{}
+
+func (recordRows) Columns() []string              { return nil }
+func (recordRows) Close() error                   { return nil }
+func (recordRows) Next(dest []driver.Value) error { return io.EOF }
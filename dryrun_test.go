@@ -0,0 +1,43 @@
+package sqllsh
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_Recorder(t *testing.T) {
+	rec := NewRecorder()
+	defer rec.Close()
+	lsh, err := NewPostgresLsh(2, 2, "lshtable", rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(rec.Script(), "CREATE TABLE IF NOT EXISTS lshtable (") {
+		t.Errorf("Expected the table to be created first, got %s", rec.Script())
+	}
+	if !strings.Contains(rec.Script(), "INSERT INTO lshtable_meta") {
+		t.Errorf("Expected the metadata to be inserted, got %s", rec.Script())
+	}
+	rec.Reset()
+	if err := lsh.Insert(1, Signature{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	stmts := rec.Statements()
+	if len(stmts) != 1 || !strings.HasPrefix(stmts[0].Query, "INSERT INTO lshtable") {
+		t.Fatalf("Expected one insert, got %v", stmts)
+	}
+	if len(stmts[0].Args) != 5 || stmts[0].Args[0] != int64(1) {
+		t.Errorf("Expected the ID and 4 hash values, got %v", stmts[0].Args)
+	}
+	rec.Reset()
+	ids, err := lsh.QueryIDs(Signature{1, 2, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 0 {
+		t.Errorf("Expected no IDs, got %v", ids)
+	}
+	if len(rec.Statements()) != 1 {
+		t.Errorf("Expected the query to be recorded, got %v", rec.Statements())
+	}
+}
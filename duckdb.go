@@ -0,0 +1,37 @@
+package sqllsh
+
+var duckdbDialect = dialect{
+	varFmt: func(i int) string {
+		return "?"
+	},
+	intType:        "BIGINT",
+	blobType:       "BLOB",
+	limitFmt:       " LIMIT %d",
+	createIndexFmt: "CREATE INDEX %s ON %s",
+	dropIndexFmt:   "DROP INDEX %[1]s",
+	indexMethods:   map[IndexType]string{IndexBTree: ""},
+	analyzeFmt:     "ANALYZE %s",
+	explainPrefix:  "EXPLAIN ",
+	plan:           PlanOr,
+	conflictClause: onConflictClause,
+	indexesQuery:   "SELECT index_name FROM duckdb_indexes() WHERE table_name = ?",
+	tablesQuery:    "SELECT table_name FROM duckdb_tables()",
+	setNotNullFmt:  "ALTER TABLE %s ALTER COLUMN %s SET NOT NULL",
+}
+
+// NewDuckdbLsh creates a new DuckDB-backed LSH index, for example using
+// the github.com/marcboeker/go-duckdb driver.
+// DuckDB builds ART indexes instead of B-Trees, and does not support
+// rebuilding them, so Compact only purges the deleted entries.
+// The caller is responsible for closing the database connection
+// object.
+func NewDuckdbLsh(k, l int, tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	lsh, err := newSqlLsh(k, l, tableName, db, duckdbDialect, opts)
+	return lsh, err
+}
+
+// OpenDuckdbLsh opens an existing DuckDB-backed LSH index with its
+// recorded parameters, like OpenSqliteLsh.
+func OpenDuckdbLsh(tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	return openSqlLsh(tableName, db, duckdbDialect, opts)
+}
@@ -0,0 +1,75 @@
+//go:build duckdb
+
+package sqllsh
+
+import (
+	"database/sql"
+	"log"
+	"math/rand"
+	"testing"
+	"time"
+
+	_ "github.com/marcboeker/go-duckdb"
+)
+
+func runDuckdb(k, l, n, nq int, b *testing.B, opts ...Option) {
+	// Initialize database
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	// Initialize data
+	lsh, err := NewDuckdbLsh(k, l, "lshtable", db, opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	sigs := randomSigs(n, k*l)
+	ids := make([]int, len(sigs))
+	for i := range sigs {
+		ids[i] = i
+	}
+	qids := rand.Perm(len(ids))[:nq]
+
+	// Inserting
+	start := time.Now()
+	err = lsh.BatchInsert(ids, sigs)
+	if err != nil {
+		b.Fatal(err)
+	}
+	dur := float64(time.Now().Sub(start)) / float64(time.Second)
+	log.Printf("Batch inserting %d signatures takes %.4f seconds", len(sigs), dur)
+
+	// Indexing
+	start = time.Now()
+	err = lsh.Index()
+	if err != nil {
+		b.Fatal(err)
+	}
+	dur = float64(time.Now().Sub(start)) / float64(time.Second)
+	log.Printf("Building index takes %.4f seconds", dur)
+
+	// Query
+	start = time.Now()
+	for _, i := range qids {
+		if _, err := lsh.QueryIDs(sigs[i]); err != nil {
+			b.Fatal(err)
+		}
+	}
+	dur = float64(time.Now().Sub(start)) / float64(time.Millisecond)
+	log.Printf("%d queries, average %.4f ms / query",
+		len(qids), dur/float64(nq))
+}
+
+func BenchmarkDuckdbLsh128(b *testing.B) {
+	runDuckdb(2, 64, 10000, 100, b)
+}
+
+func BenchmarkDuckdbLsh256(b *testing.B) {
+	runDuckdb(4, 64, 10000, 100, b)
+}
+
+func BenchmarkDuckdbLsh512(b *testing.B) {
+	runDuckdb(8, 64, 10000, 100, b)
+}
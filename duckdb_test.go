@@ -0,0 +1,50 @@
+//go:build duckdb
+
+package sqllsh
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	_ "github.com/marcboeker/go-duckdb"
+)
+
+func Test_DuckdbLsh(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewDuckdbLsh(2, 3, "lshtable", db, WithSoftDelete())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(10, 6)
+	ids := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	found, err := lsh.QueryIDs(sigs[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0] != 3 {
+		t.Errorf("Expected [3], got %v", found)
+	}
+	if err := lsh.Insert(3, sigs[3]); !errors.Is(err, ErrIDExists) {
+		t.Errorf("Expected ErrIDExists, got %v", err)
+	}
+	if err := lsh.Delete(3); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := lsh.Compact(); err != nil || n != 1 {
+		t.Errorf("Expected 1 entry purged, got %d, %v", n, err)
+	}
+	if err := lsh.Analyze(); err != nil {
+		t.Error(err)
+	}
+}
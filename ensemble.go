@@ -0,0 +1,221 @@
+package sqllsh
+
+import (
+	"math"
+	"sort"
+)
+
+// WithEnsemble partitions the index by set size as in LSH Ensemble
+// (Zhu et al., "LSH Ensemble: Internet-Scale Domain Search", 2016), for
+// containment search with QueryContainment.
+// bounds are the increasing upper set sizes of the partitions: the
+// first one holds the sets of at most bounds[0] elements, the second
+// one those of at most bounds[1], and so on, and the last one also
+// holds the larger sets.
+// EnsembleBounds computes bounds giving partitions of equal sizes.
+// The partition of each Signature is stored in a column of the table,
+// set by InsertSet and BatchInsertSets; Insert and BatchInsert put the
+// Signatures in the first partition.
+// WithNamespaces, AddHashTables and Reshape are not supported.
+// The option changes the table schema, so it must be used every time
+// the same table is opened.
+func WithEnsemble(bounds ...int) Option {
+	return func(lsh *SqlLsh) {
+		lsh.ensemble = append([]int{}, bounds...)
+	}
+}
+
+// validBounds reports whether bounds are positive and increasing.
+func validBounds(bounds []int) bool {
+	for i, b := range bounds {
+		if b < 1 || (i > 0 && b <= bounds[i-1]) {
+			return false
+		}
+	}
+	return len(bounds) > 0
+}
+
+// EnsembleBounds returns the bounds of WithEnsemble splitting the given
+// set sizes, such as those of a sample of the sets to index, into n
+// partitions of about the same number of sets.
+// Fewer bounds are returned if many sets have the same size.
+func EnsembleBounds(sizes []int, n int) []int {
+	if len(sizes) == 0 || n < 1 {
+		return nil
+	}
+	sorted := append([]int{}, sizes...)
+	sort.Ints(sorted)
+	var bounds []int
+	for i := 1; i <= n; i++ {
+		b := sorted[i*len(sorted)/n-1]
+		if len(bounds) == 0 || b > bounds[len(bounds)-1] {
+			bounds = append(bounds, b)
+		}
+	}
+	return bounds
+}
+
+// partition returns the partition of the sets of the given size.
+func (lsh *SqlLsh) partition(size int) int {
+	for p, b := range lsh.ensemble {
+		if size <= b {
+			return p
+		}
+	}
+	return len(lsh.ensemble) - 1
+}
+
+// partView returns a view of the index scoped to partition p, like the
+// views of Namespace.
+func (lsh *SqlLsh) partView(p int) *SqlLsh {
+	view := *lsh
+	view.part = p
+	view.inPart = true
+	view.adHoc = true
+	view.ownDB = false
+	view.parentCache = lsh.sharedCache()
+	view.cache = nil
+	view.insertStmt = nil
+	view.queryStmt = nil
+	view.countStmt = nil
+	view.scanStmt = nil
+	view.deleteStmt = nil
+	view.purgeStmt = nil
+	return &view
+}
+
+// InsertSet is like Insert, but puts the Signature of a set of size
+// elements in the partition of WithEnsemble holding that size.
+func (lsh *SqlLsh) InsertSet(id int, sig Signature, size int) error {
+	if lsh.ensemble == nil {
+		return ErrUnsupported
+	}
+	if size < 1 {
+		return ErrInvalidParameter
+	}
+	return lsh.partView(lsh.partition(size)).Insert(id, sig)
+}
+
+// BatchInsertSets is like InsertSet for many sets, with sizes[i] the
+// size of the set of ids[i].
+// It runs one BatchInsert per partition, so an error may leave the
+// Signatures of some partitions inserted.
+func (lsh *SqlLsh) BatchInsertSets(ids []int, sigs []Signature, sizes []int) error {
+	if lsh.ensemble == nil {
+		return ErrUnsupported
+	}
+	if err := validateBatch(ids, sigs, lsh.k*lsh.l); err != nil {
+		return err
+	}
+	if len(sizes) != len(ids) {
+		return ErrInvalidParameter
+	}
+	partIDs := make([][]int, len(lsh.ensemble))
+	partSigs := make([][]Signature, len(lsh.ensemble))
+	for i, size := range sizes {
+		if size < 1 {
+			return ErrInvalidParameter
+		}
+		p := lsh.partition(size)
+		partIDs[p] = append(partIDs[p], ids[i])
+		partSigs[p] = append(partSigs[p], sigs[i])
+	}
+	for p := range partIDs {
+		if len(partIDs[p]) == 0 {
+			continue
+		}
+		if err := lsh.partView(p).BatchInsert(partIDs[p], partSigs[p]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QueryContainment returns the IDs of the sets that likely contain at
+// least threshold of the elements of the query set, which has size
+// elements and the Signature sig.
+// In each partition, the hash key prefix and the number of hash tables
+// queried are chosen to minimize the false positive and false negative
+// probabilities, given the upper set size of the partition, as in LSH
+// Ensemble.
+// The sets larger than the last bound of WithEnsemble are found with a
+// lower probability.
+// The layouts matching full hash keys only, such as
+// WithCompactLayout, can only choose the number of hash tables.
+func (lsh *SqlLsh) QueryContainment(sig Signature, size int, threshold float64) ([]int, error) {
+	if lsh.ensemble == nil {
+		return nil, ErrUnsupported
+	}
+	sig, err := lsh.beforeQuery("query containment", sig)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != lsh.k*lsh.l {
+		return nil, ErrSignatureSizeMismatch
+	}
+	if size < 1 || threshold <= 0 || threshold > 1 {
+		return nil, ErrInvalidParameter
+	}
+	seen := make(map[int]bool)
+	ids := make([]int, 0)
+	for p, upper := range lsh.ensemble {
+		r, b := lsh.containmentParams(float64(upper), float64(size), threshold)
+		bands := make([]int, b)
+		for i := range bands {
+			bands[i] = i
+		}
+		found, err := lsh.partView(p).queryBands(sig, bands, r)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range found {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// containmentParams returns the hash key prefix r and the number of
+// hash tables b minimizing the sum of the false positive and false
+// negative probabilities of a containment query with q elements and
+// the given threshold, against sets of at most x elements.
+func (lsh *SqlLsh) containmentParams(x, q, threshold float64) (int, int) {
+	minR := 1
+	if lsh.fullKeys() {
+		minR = lsh.k
+	}
+	bestR, bestB, best := lsh.k, lsh.l, math.Inf(1)
+	for r := minR; r <= lsh.k; r++ {
+		for b := 1; b <= lsh.l; b++ {
+			candidate := func(c float64) float64 {
+				// The Jaccard similarity of the query and a set of x
+				// elements containing c of the query
+				s := math.Min(c*q/(x+q-c*q), 1)
+				return 1 - math.Pow(1-math.Pow(s, float64(r)), float64(b))
+			}
+			fp := integrate(candidate, 0, threshold)
+			fn := integrate(func(c float64) float64 {
+				return 1 - candidate(c)
+			}, threshold, 1)
+			if fp+fn < best {
+				bestR, bestB, best = r, b, fp+fn
+			}
+		}
+	}
+	return bestR, bestB
+}
+
+// integrate returns the integral of f from a to b, using the midpoint
+// rule.
+func integrate(f func(float64) float64, a, b float64) float64 {
+	const steps = 32
+	width := (b - a) / steps
+	sum := 0.0
+	for i := 0; i < steps; i++ {
+		sum += f(a + (float64(i)+0.5)*width)
+	}
+	return sum * width
+}
@@ -0,0 +1,77 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func Test_EnsembleBounds(t *testing.T) {
+	bounds := EnsembleBounds([]int{5, 1, 2, 2, 8, 3, 10, 4}, 4)
+	if !reflect.DeepEqual(bounds, []int{2, 3, 5, 10}) {
+		t.Errorf("Expected [2 3 5 10], got %v", bounds)
+	}
+	if bounds := EnsembleBounds([]int{1, 1, 1, 1}, 2); !reflect.DeepEqual(bounds, []int{1}) {
+		t.Errorf("Expected [1], got %v", bounds)
+	}
+}
+
+func Test_QueryContainment(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(4, 8, "lshtable", db, WithEnsemble(10, 100, 1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(100, 32)
+	ids := make([]int, len(sigs))
+	sizes := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+		sizes[i] = 5 + 10*i
+	}
+	if err := lsh.BatchInsertSets(ids, sigs, sizes); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.InsertSet(100, sigs[3], 2000); err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM lshtable WHERE part = 2").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 91 {
+		t.Errorf("Expected 91 sets in the last partition, got %d", n)
+	}
+	found, err := lsh.QueryContainment(sigs[3], 35, 0.8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsID(found, 3) || !containsID(found, 100) {
+		t.Errorf("Expected 3 and 100 in %v", found)
+	}
+	if _, err := lsh.QueryContainment(sigs[3], 35, 0); err != ErrInvalidParameter {
+		t.Errorf("Expected ErrInvalidParameter, got %v", err)
+	}
+	if _, err := NewSqliteLsh(4, 8, "other", db, WithEnsemble(10, 10)); err != ErrInvalidParameter {
+		t.Errorf("Expected ErrInvalidParameter, got %v", err)
+	}
+	if _, err := NewSqliteLsh(4, 8, "other", db, WithEnsemble(10, 100), WithNamespaces()); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
+
+func Test_ContainmentParams(t *testing.T) {
+	lsh := &SqlLsh{k: 8, l: 32}
+	// A lower threshold needs shorter prefixes or more hash tables
+	r1, b1 := lsh.containmentParams(100, 100, 0.9)
+	r2, b2 := lsh.containmentParams(100, 100, 0.3)
+	if float64(r2)/float64(b2) >= float64(r1)/float64(b1) {
+		t.Errorf("Expected more candidates at 0.3 than (%d, %d), got (%d, %d)", r1, b1, r2, b2)
+	}
+}
@@ -0,0 +1,157 @@
+package sqllsh
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrSignatureSizeMismatch is returned when a Signature does not
+	// have size k*l.
+	ErrSignatureSizeMismatch = errors.New("Signature size mismatch")
+	// ErrCountMismatch is returned when the number of IDs and the number
+	// of Signatures in a batch differ.
+	ErrCountMismatch = errors.New("Number of signatures and ids mismatch")
+	// ErrEmptyBatch is returned when a batch operation is given no input.
+	ErrEmptyBatch = errors.New("Empty batch")
+	// ErrIDExists is returned when inserting an ID that is already in
+	// the table.
+	ErrIDExists = errors.New("ID already exists")
+	// ErrIDCollision is returned when no free integer key is found for
+	// a string ID of a TypedLsh, as too many other IDs hash near it.
+	ErrIDCollision = errors.New("ID hash collision")
+	// ErrTableExists is returned by the constructors when a table with
+	// the same name exists but was created with a different signature
+	// size.
+	ErrTableExists = errors.New("Table already exists with a different signature size")
+	// ErrInvalidParameter is returned when an argument is out of its
+	// valid range.
+	ErrInvalidParameter = errors.New("Invalid parameter")
+	// ErrIncompatible is returned when two indexes used together have
+	// different k and l, or are in different databases.
+	ErrIncompatible = errors.New("Indexes are incompatible")
+	// ErrUnsupported is returned when an operation requires an option
+	// or a database feature that the index does not have.
+	ErrUnsupported = errors.New("Operation not supported by this index")
+	// ErrSchemaMismatch is returned by Validate when a column of the
+	// table does not have the type used by the index.
+	ErrSchemaMismatch = errors.New("Table schema does not match the index")
+	// ErrIndexMissing is returned by Validate when the index of a hash
+	// table does not exist.
+	ErrIndexMissing = errors.New("Hash table index missing")
+	// ErrNotFound is returned by Get when the ID is not in the index,
+	// and by the Open constructors and ReadMeta when the table has no
+	// metadata.
+	ErrNotFound = errors.New("ID not found")
+	// ErrNullHashValue is wrapped by NullValueError.
+	ErrNullHashValue = errors.New("NULL hash value")
+)
+
+// SignatureSizeError is returned by BatchInsert when a Signature
+// in the batch does not have size k*l.
+type SignatureSizeError struct {
+	Index int // Position of the Signature in the batch
+	Size  int // Size of the Signature
+	Want  int // Expected size, k*l
+}
+
+func (e *SignatureSizeError) Error() string {
+	return fmt.Sprintf("Signature size mismatch at index %d: got %d, want %d",
+		e.Index, e.Size, e.Want)
+}
+
+// Unwrap returns ErrSignatureSizeMismatch.
+func (e *SignatureSizeError) Unwrap() error {
+	return ErrSignatureSizeMismatch
+}
+
+// NullValueError is returned when a row of the table has a NULL hash
+// value, which the index never writes: the columns of tables created
+// by older versions of the package are nullable, and the table may be
+// written by other programs.
+// Such rows cannot be found by the hash tables with the NULL values.
+type NullValueError struct {
+	Id     int    // ID of the row
+	Column string // Column holding the NULL
+}
+
+func (e *NullValueError) Error() string {
+	return fmt.Sprintf("NULL hash value in column %s of ID %d", e.Column, e.Id)
+}
+
+// Unwrap returns ErrNullHashValue.
+func (e *NullValueError) Unwrap() error {
+	return ErrNullHashValue
+}
+
+// IDConflictError is returned by the inserts with ConflictIdempotent
+// when an ID is already in the table with a different Signature.
+type IDConflictError struct {
+	Id int // ID of the Signature
+}
+
+func (e *IDConflictError) Error() string {
+	return fmt.Sprintf("ID %d already exists with a different Signature", e.Id)
+}
+
+// Unwrap returns ErrIDExists.
+func (e *IDConflictError) Unwrap() error {
+	return ErrIDExists
+}
+
+// PartialInsertError is returned by BatchInsert when some of the
+// Signatures were committed before an error occurred.
+type PartialInsertError struct {
+	Committed int   // Number of Signatures committed, from the start of the batch
+	Err       error // Error that stopped the insert
+}
+
+func (e *PartialInsertError) Error() string {
+	return fmt.Sprintf("%d signatures committed before error: %s", e.Committed, e.Err)
+}
+
+// Unwrap returns the error that stopped the insert.
+func (e *PartialInsertError) Unwrap() error {
+	return e.Err
+}
+
+// OpError records the operation during which a database error occurred.
+type OpError struct {
+	Op  string // Operation, e.g. "insert" or "query"
+	Err error  // Underlying error
+}
+
+func (e *OpError) Error() string {
+	return e.Op + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+// wrapErr annotates a database error with the operation, translating
+// driver-specific unique violations into ErrIDExists.
+func wrapErr(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if isDuplicateKey(err) {
+		err = ErrIDExists
+	}
+	return &OpError{Op: op, Err: err}
+}
+
+// isDuplicateKey reports whether err is a primary key violation.
+// Drivers do not share an error type for this, so the messages of
+// the supported databases are matched instead.
+func isDuplicateKey(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "UNIQUE constraint failed") ||
+		strings.Contains(msg, "duplicate key value") ||
+		strings.Contains(msg, "Duplicate entry") ||
+		strings.Contains(msg, "violates primary key constraint") ||
+		strings.Contains(msg, "ORA-00001") ||
+		strings.Contains(msg, `code = "AlreadyExists"`)
+}
@@ -0,0 +1,37 @@
+package sqllsh
+
+import "fmt"
+
+// ExistIDs returns which of ids are in the index, so loaders can skip
+// the entries already inserted.
+// Every ID of ids is in the result, mapped to false if it is not in
+// the index; entries deleted with WithSoftDelete are not in the index.
+// The IDs are checked with as few queries as the parameter limit of
+// the database allows.
+func (lsh *SqlLsh) ExistIDs(ids []int) (map[int]bool, error) {
+	exist := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		exist[id] = false
+	}
+	err := lsh.inChunks(len(ids), func(i int) interface{} { return ids[i] },
+		func(in string, args []interface{}) error {
+			rows, err := lsh.readDB().Query(fmt.Sprintf("SELECT id FROM %s WHERE %sid IN (%s)",
+				lsh.tableName, lsh.liveCond(), in), args...)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var id int
+				if err := rows.Scan(&id); err != nil {
+					return err
+				}
+				exist[id] = true
+			}
+			return rows.Err()
+		})
+	if err != nil {
+		return nil, wrapErr("exist", err)
+	}
+	return exist, nil
+}
@@ -0,0 +1,47 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_ExistIDs(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithSoftDelete())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(1200, 4)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = 2 * i
+	}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Delete(0); err != nil {
+		t.Fatal(err)
+	}
+	check := make([]int, 2*len(ids))
+	for i := range check {
+		check[i] = i
+	}
+	exist, err := lsh.ExistIDs(check)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exist) != len(check) {
+		t.Fatalf("Expected %d IDs, got %d", len(check), len(exist))
+	}
+	for id, ok := range exist {
+		if want := id%2 == 0 && id != 0; ok != want {
+			t.Errorf("Expected %v for %d, got %v", want, id, ok)
+		}
+	}
+}
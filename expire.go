@@ -0,0 +1,74 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// expireBatchSize is the number of entries ExpireBefore deletes in
+// each transaction.
+const expireBatchSize = 1000
+
+// ExpireBefore removes the entries inserted before t, and returns the
+// number of entries removed.
+// The entries are removed in batches, each in its own transaction,
+// so that locks are not held for long on a large table.
+// If an error occurs, the batches already committed stay removed.
+// It requires the index to be created using WithInsertTime.
+func (lsh *SqlLsh) ExpireBefore(t time.Time) (int64, error) {
+	if !lsh.insertTime {
+		return 0, ErrUnsupported
+	}
+	key := strings.Join(lsh.keyCols(), ", ")
+	if lsh.namespaces {
+		key = "(" + key + ")"
+	}
+	query := fmt.Sprintf(
+		"DELETE FROM %s WHERE %s IN (SELECT %s FROM %s WHERE %sinserted_at < %s"+lsh.dialect.limitFmt+")",
+		lsh.tableName, key, strings.Join(lsh.keyCols(), ", "), lsh.tableName, lsh.scopeCond(),
+		lsh.dialect.varFmt(0), expireBatchSize)
+	if lsh.dialect.limitDelete {
+		query = fmt.Sprintf("DELETE FROM %s WHERE %sinserted_at < %s LIMIT %d",
+			lsh.tableName, lsh.scopeCond(), lsh.dialect.varFmt(0), expireBatchSize)
+	}
+	var stmt *sql.Stmt
+	if !lsh.adHoc {
+		var err error
+		stmt, err = lsh.db.Prepare(query)
+		if err != nil {
+			return 0, wrapErr("expire", err)
+		}
+		defer stmt.Close()
+	}
+	var total int64
+	for {
+		tx, err := lsh.db.Begin()
+		if err != nil {
+			return total, wrapErr("expire", err)
+		}
+		res, err := lsh.exec(tx.Tx, stmt, query, t.UnixNano())
+		if err != nil {
+			tx.Rollback()
+			return total, wrapErr("expire", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return total, wrapErr("expire", err)
+		}
+		err = tx.Commit()
+		if err != nil {
+			tx.Rollback()
+			return total, wrapErr("expire", err)
+		}
+		total += n
+		if n > 0 {
+			lsh.sharedCache().clear()
+		}
+		if n < expireBatchSize {
+			return total, nil
+		}
+	}
+}
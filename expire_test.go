@@ -0,0 +1,58 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func Test_ExpireBefore(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithInsertTime())
+	if err != nil {
+		t.Error(err)
+	}
+	sigs := randomSigs(2500, 4)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	n, err := lsh.ExpireBefore(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 0 {
+		t.Errorf("Expected no expired entries, got %d", n)
+	}
+	n, err = lsh.ExpireBefore(time.Now().Add(time.Second))
+	if err != nil {
+		t.Error(err)
+	}
+	if n != int64(len(sigs)) {
+		t.Errorf("Expected %d expired entries, got %d", len(sigs), n)
+	}
+	removeTempFile(t, f)
+}
+
+func Test_ExpireBeforeUnsupported(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Error(err)
+	}
+	if _, err := lsh.ExpireBefore(time.Now()); err != ErrUnsupported {
+		t.Error("Fail to raise error")
+	}
+	removeTempFile(t, f)
+}
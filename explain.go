@@ -0,0 +1,47 @@
+package sqllsh
+
+import "strings"
+
+// ExplainQuery returns the plan the database uses for the query run by
+// Query for sig, one line per step, which shows whether the indexes of
+// the hash tables are used.
+func (lsh *SqlLsh) ExplainQuery(sig Signature) (string, error) {
+	if len(sig) != lsh.k*lsh.l {
+		return "", ErrSignatureSizeMismatch
+	}
+	if lsh.dialect.explainPrefix == "" {
+		return "", ErrUnsupported
+	}
+	rows, err := lsh.readDB().Query(lsh.dialect.explainPrefix+lsh.queryStr(),
+		lsh.queryArgs(sig, lsh.allBands(), lsh.k)...)
+	if err != nil {
+		return "", wrapErr("explain", err)
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return "", wrapErr("explain", err)
+	}
+	// The step is in the last column, the others are step numbers
+	values := make([]interface{}, len(cols))
+	for i := range values {
+		values[i] = new(interface{})
+	}
+	var lines []string
+	for rows.Next() {
+		var step string
+		values[len(values)-1] = &step
+		if err := rows.Scan(values...); err != nil {
+			return "", wrapErr("explain", err)
+		}
+		lines = append(lines, step)
+	}
+	if err := rows.Err(); err != nil {
+		return "", wrapErr("explain", err)
+	}
+	if lsh.dialect.explainJSON != nil {
+		plan, err := lsh.dialect.explainJSON(strings.Join(lines, ""))
+		return plan, wrapErr("explain", err)
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,36 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func Test_ExplainQuery(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := lsh.ExplainQuery(Signature{1, 2, 3, 4, 5, 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if !strings.Contains(plan, fmt.Sprintf("lshtable_ht_%d", i)) {
+			t.Errorf("Expected plan using index lshtable_ht_%d, got:\n%s", i, plan)
+		}
+	}
+	if _, err := lsh.ExplainQuery(Signature{1}); err != ErrSignatureSizeMismatch {
+		t.Errorf("Expected ErrSignatureSizeMismatch, got %v", err)
+	}
+	removeTempFile(t, f)
+}
@@ -0,0 +1,93 @@
+package sqllsh
+
+import "strings"
+
+// filter is an extra condition of the queries of a view, on the
+// columns users added to the table.
+type filter struct {
+	cond string        // Condition with ? placeholders
+	args []interface{} // Arguments of the placeholders
+}
+
+// Where returns a view of the index whose queries only find the
+// entries also matching the SQL condition cond, such as
+// "category = ?" on a column added to the table by its users, so that
+// the candidates are filtered in the database instead of being sent
+// to the application.
+// The condition is ANDed with the collision conditions and written in
+// the SQL as is, so it must not come from untrusted input; its
+// arguments are given by args, with ? placeholders, which are numbered
+// for the databases using $1 or :1 placeholders. A ? inside a quoted
+// string is left as is.
+// Calling Where on a view ANDs the conditions.
+// The condition applies to Query, QueryIDs, QueryIter, QueryPrefix,
+// QueryAtLeast, QueryPage, QueryCandidates, CountCandidates, DeleteWhere
+// and the methods built on them; the other methods, such as inserts,
+// ignore it.
+// Like Namespace, the view shares the table of lsh, runs its statements
+// without preparing them, and does not use the query cache.
+func (lsh *SqlLsh) Where(cond string, args ...interface{}) *SqlLsh {
+	f := &filter{cond: cond, args: args}
+	if lsh.filter != nil {
+		f.cond = "(" + lsh.filter.cond + ") AND (" + cond + ")"
+		f.args = append(append([]interface{}(nil), lsh.filter.args...), args...)
+	}
+	view := *lsh
+	view.filter = f
+	view.adHoc = true
+	view.ownDB = false
+	view.parentCache = lsh.sharedCache()
+	view.cache = nil
+	view.insertStmt = nil
+	view.queryStmt = nil
+	view.countStmt = nil
+	view.scanStmt = nil
+	view.deleteStmt = nil
+	view.purgeStmt = nil
+	return &view
+}
+
+// filterCond returns the condition of the view, preceded by AND, with
+// its placeholders numbered from offset, or an empty string if the
+// index has no condition.
+func (lsh *SqlLsh) filterCond(offset int) string {
+	if lsh.filter == nil {
+		return ""
+	}
+	return " AND (" + lsh.numberVars(lsh.filter.cond, offset) + ")"
+}
+
+// numberVars replaces the ? placeholders of the SQL s, outside quoted
+// strings, with the placeholders of the database numbered from offset.
+func (lsh *SqlLsh) numberVars(s string, offset int) string {
+	var b strings.Builder
+	quoted := false
+	n := offset
+	for _, r := range s {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == '?' && !quoted:
+			b.WriteString(lsh.dialect.varFmt(n))
+			n++
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// filterArgs returns the arguments of filterCond.
+func (lsh *SqlLsh) filterArgs() []interface{} {
+	if lsh.filter == nil {
+		return nil
+	}
+	return lsh.filter.args
+}
+
+// queryArgs returns the arguments of bandsQueryStr and countStr: the
+// arguments of bandsArgs followed by those of the condition of the
+// view.
+func (lsh *SqlLsh) queryArgs(sig Signature, bands []int, prefix int) []interface{} {
+	return append(lsh.bandsArgs(sig, bands, prefix), lsh.filterArgs()...)
+}
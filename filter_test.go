@@ -0,0 +1,83 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_Where(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, plan := range []QueryPlan{PlanOr, PlanUnion} {
+		db.Exec("DROP TABLE lshtable")
+		db.Exec("DROP TABLE lshtable_meta")
+		lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithQueryPlan(plan))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec("ALTER TABLE lshtable ADD COLUMN category TEXT"); err != nil {
+			t.Fatal(err)
+		}
+		sig := Signature{1, 2, 3, 4}
+		for id := 0; id < 4; id++ {
+			if err := lsh.Insert(id, sig); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := db.Exec("UPDATE lshtable SET category = CASE WHEN id < 2 THEN 'a' ELSE 'b' END"); err != nil {
+			t.Fatal(err)
+		}
+		view := lsh.Where("category = ?", "b")
+		ids, err := view.QueryIDs(sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) != 2 || !containsID(ids, 2) || !containsID(ids, 3) {
+			t.Errorf("Expected [2 3], got %v", ids)
+		}
+		ids, err = view.Where("id <> ?", 3).QueryIDs(sig)
+		if err != nil || len(ids) != 1 || ids[0] != 2 {
+			t.Errorf("Expected [2], got %v, %v", ids, err)
+		}
+		if n, err := view.CountCandidates(sig); err != nil || n != 2 {
+			t.Errorf("Expected 2 candidates, got %d, %v", n, err)
+		}
+		page, err := view.QueryPage(sig, nil, 1)
+		if err != nil || len(page) != 1 || page[0] != 2 {
+			t.Errorf("Expected [2], got %v, %v", page, err)
+		}
+		out := make(chan Candidate)
+		go func() {
+			if err := view.QueryCandidates(sig, out); err != nil {
+				t.Error(err)
+			}
+			close(out)
+		}()
+		n := 0
+		for c := range out {
+			if c.Id < 2 || len(c.Bands) != 2 {
+				t.Errorf("Unexpected candidate %v", c)
+			}
+			n++
+		}
+		if n != 2 {
+			t.Errorf("Expected 2 candidates, got %d", n)
+		}
+		// The index itself is not filtered
+		if ids, err := lsh.QueryIDs(sig); err != nil || len(ids) != 4 {
+			t.Errorf("Expected 4 IDs, got %v, %v", ids, err)
+		}
+	}
+}
+
+func Test_FilterCond(t *testing.T) {
+	lsh := (&SqlLsh{dialect: postgresDialect}).Where("category = ? AND tag <> '?'", "a")
+	if cond := lsh.filterCond(4); cond != " AND (category = $5 AND tag <> '?')" {
+		t.Errorf("Unexpected condition %s", cond)
+	}
+}
@@ -0,0 +1,60 @@
+package sqllsh
+
+// QueryPrefix is like Query, but a collision only requires the first
+// prefix hash values of a hash key to be equal, instead of all k.
+// A shorter prefix finds more candidates, trading precision for recall
+// at query time as in LSH Forest.
+// The indexes built by Index cover the hash values of each hash key in
+// order, so the prefix is matched using range scans on the indexes.
+// The caller is responsible for closing the channel.
+func (lsh *SqlLsh) QueryPrefix(sig Signature, prefix int, out chan int) error {
+	ids, err := lsh.queryPrefix(sig, prefix)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		out <- id
+	}
+	return nil
+}
+
+// QueryAtLeast finds the IDs of at least m Signatures, if there are
+// enough in the table, that collide with the query Signature.
+// It starts with the full hash keys, and shortens the prefix used by
+// QueryPrefix one hash value at a time until m IDs are found or the
+// prefix is a single hash value.
+func (lsh *SqlLsh) QueryAtLeast(sig Signature, m int) ([]int, error) {
+	var ids []int
+	var err error
+	last := 1
+	if lsh.fullKeys() {
+		last = lsh.k
+	}
+	for prefix := lsh.k; prefix >= last; prefix-- {
+		ids, err = lsh.queryPrefix(sig, prefix)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) >= m {
+			break
+		}
+	}
+	return ids, nil
+}
+
+func (lsh *SqlLsh) queryPrefix(sig Signature, prefix int) ([]int, error) {
+	sig, err := lsh.beforeQuery("query prefix", sig)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != lsh.k*lsh.l {
+		return nil, ErrSignatureSizeMismatch
+	}
+	if prefix < 1 || prefix > lsh.k {
+		return nil, ErrInvalidParameter
+	}
+	if lsh.fullKeys() && prefix < lsh.k {
+		return nil, ErrUnsupported
+	}
+	return lsh.queryBands(sig, lsh.allBands(), prefix)
+}
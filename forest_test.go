@@ -0,0 +1,55 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_QueryPrefix(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(3, 2, "lshtable", db)
+	if err != nil {
+		t.Error(err)
+	}
+	lsh.Insert(0, Signature{1, 2, 3, 4, 5, 6})
+	lsh.Insert(1, Signature{1, 2, 0, 0, 0, 0})
+	lsh.Insert(2, Signature{1, 0, 0, 0, 0, 0})
+	lsh.Insert(3, Signature{7, 7, 7, 7, 7, 7})
+	if err := lsh.Index(); err != nil {
+		t.Error(err)
+	}
+	sig := Signature{1, 2, 3, 9, 9, 9}
+	for prefix, n := range map[int]int{3: 1, 2: 2, 1: 3} {
+		out := make(chan int)
+		go func() {
+			if err := lsh.QueryPrefix(sig, prefix, out); err != nil {
+				t.Error(err)
+			}
+			close(out)
+		}()
+		count := 0
+		for _ = range out {
+			count++
+		}
+		if count != n {
+			t.Errorf("Expected %d IDs with prefix %d, got %d", n, prefix, count)
+		}
+	}
+	if err := lsh.QueryPrefix(sig, 4, nil); err != ErrInvalidParameter {
+		t.Error("Fail to raise error")
+	}
+	for m, n := range map[int]int{1: 1, 2: 2, 3: 3, 4: 3} {
+		ids, err := lsh.QueryAtLeast(sig, m)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(ids) != n {
+			t.Errorf("Expected %d IDs for at least %d, got %d", n, m, len(ids))
+		}
+	}
+	removeTempFile(t, f)
+}
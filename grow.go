@@ -0,0 +1,140 @@
+package sqllsh
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AddHashTables adds extra hash tables to the index, so that l can be
+// increased without rebuilding the index from scratch.
+// The hash value columns of the new hash tables are added to the table,
+// and backfill is called with the ID of every existing row to get the
+// k*extra new hash values of its Signature, which are appended to
+// the existing ones.
+// The new columns are NOT NULL like the others, and the indexes of the
+// new hash tables are then built.
+// Everything is done in one transaction, so if an error occurs or
+// backfill returns a Signature of the wrong size, the index is left
+// unchanged.
+// AddHashTables must not be called concurrently with other methods,
+// and every SqlLsh using the same table must be created again with
+// the new l afterwards.
+// It is not supported on MySQL, MariaDB, TiDB, Vitess, Oracle and
+// Snowflake, which commit the transaction when altering the table.
+func (lsh *SqlLsh) AddHashTables(extra int, backfill func(id int) Signature) error {
+	if extra < 1 {
+		return ErrInvalidParameter
+	}
+	if lsh.dialect.ddl != nil || lsh.dialect.implicitCommit || lsh.namespaces || lsh.ensemble != nil || lsh.fullKeys() {
+		return ErrUnsupported
+	}
+	ids, err := lsh.allIDs()
+	if err != nil {
+		return wrapErr("add hash tables", err)
+	}
+	grown := *lsh
+	grown.l = lsh.l + extra
+	tx, err := lsh.db.Begin()
+	if err != nil {
+		return wrapErr("add hash tables", err)
+	}
+	colType := lsh.dialect.intType
+	if lsh.dialect.setNotNullFmt == "" {
+		// The default is replaced by the backfill
+		colType += " DEFAULT 0 NOT NULL"
+	}
+	for i := lsh.k * lsh.l; i < grown.k*grown.l; i++ {
+		_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD hv_%d %s", lsh.tableName, i, colType))
+		if err != nil {
+			tx.Rollback()
+			return wrapErr("add hash tables", err)
+		}
+	}
+	setSeg := make([]string, lsh.k*extra)
+	for i := range setSeg {
+		setSeg[i] = fmt.Sprintf("hv_%d = %s", lsh.k*lsh.l+i, lsh.dialect.varFmt(i))
+	}
+	update, err := tx.Prepare(fmt.Sprintf("UPDATE %s SET %s WHERE id = %s",
+		lsh.tableName, strings.Join(setSeg, ", "), lsh.dialect.varFmt(len(setSeg))))
+	if err != nil {
+		tx.Rollback()
+		return wrapErr("add hash tables", err)
+	}
+	defer update.Close()
+	for _, id := range ids {
+		sig := backfill(id)
+		if len(sig) != lsh.k*extra {
+			tx.Rollback()
+			return ErrSignatureSizeMismatch
+		}
+		args := make([]interface{}, len(sig)+1)
+		for i := range sig {
+			args[i] = sig[i]
+		}
+		args[len(sig)] = id
+		if _, err := update.Exec(args...); err != nil {
+			tx.Rollback()
+			return wrapErr("add hash tables", err)
+		}
+	}
+	for i := lsh.k * lsh.l; i < grown.k*grown.l && lsh.dialect.setNotNullFmt != ""; i++ {
+		_, err = tx.Exec(fmt.Sprintf(lsh.dialect.setNotNullFmt, lsh.tableName, fmt.Sprintf("hv_%d", i)))
+		if err != nil {
+			tx.Rollback()
+			return wrapErr("add hash tables", err)
+		}
+	}
+	if _, err := tx.Exec(lsh.updateMetaStr(), grown.k, grown.l); err != nil {
+		tx.Rollback()
+		return wrapErr("add hash tables", err)
+	}
+	for i := lsh.l; i < grown.l && lsh.dialect.createIndexFmt != ""; i++ {
+		if _, err := tx.Exec(grown.indexStr(i)); err != nil {
+			tx.Rollback()
+			return wrapErr("add hash tables", err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return wrapErr("add hash tables", err)
+	}
+	lsh.closeStmts()
+	lsh.l = grown.l
+	if err := lsh.prepare(); err != nil {
+		return err
+	}
+	lsh.cache.clear()
+	if lsh.bloom != nil {
+		lsh.bloom = newBandBloom(lsh.l, lsh.bloom.n, lsh.bloom.fpRate)
+		if err := lsh.loadBloom(); err != nil {
+			return err
+		}
+	}
+	if lsh.hot != nil {
+		// The new hash tables have hot keys of their own
+		if _, err := lsh.RefreshHotKeys(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allIDs returns the IDs of all rows in the table, including deleted
+// entries.
+func (lsh *SqlLsh) allIDs() ([]int, error) {
+	rows, err := lsh.db.Query(fmt.Sprintf("SELECT id FROM %s", lsh.tableName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
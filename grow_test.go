@@ -0,0 +1,111 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func Test_AddHashTables(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Error(err)
+	}
+	sigs := randomSigs(10, 6)
+	for i := range sigs {
+		lsh.Insert(i, sigs[i][:4])
+	}
+	if err := lsh.Index(); err != nil {
+		t.Error(err)
+	}
+	err = lsh.AddHashTables(1, func(id int) Signature {
+		return sigs[id][:3]
+	})
+	if err != ErrSignatureSizeMismatch {
+		t.Error("Fail to raise error")
+	}
+	err = lsh.AddHashTables(1, func(id int) Signature {
+		return sigs[id][4:]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range sigs {
+		// Only the new hash table collides
+		sig := append(Signature{0, 0, 0, 0}, sigs[i][4:]...)
+		ids, err := lsh.QueryIDs(sig)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(ids) != 1 || ids[0] != i {
+			t.Error("Error in query on the new hash table")
+		}
+	}
+	if err := lsh.Insert(len(sigs), sigs[0]); err != nil {
+		t.Error(err)
+	}
+	if _, err := NewSqliteLsh(2, 3, "lshtable", db); err != nil {
+		t.Error(err)
+	}
+	removeTempFile(t, f)
+}
+
+func Test_AddHashTablesNotNull(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 1, "lshtable", db, WithHotKeys(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := lsh.Insert(i, Signature{uint(i), 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Finds the hot keys of the existing hash table
+	if _, err := lsh.HotBands(Signature{9, 9}); err != nil {
+		t.Fatal(err)
+	}
+	err = lsh.AddHashTables(1, func(id int) Signature {
+		return Signature{5, 6}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("UPDATE lshtable SET hv_2 = NULL"); err == nil {
+		t.Error("Expected the new columns to be NOT NULL")
+	}
+	// The hash key shared by every Signature in the new hash table is hot
+	bands, err := lsh.HotBands(Signature{9, 9, 5, 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bands) != 1 || bands[0] != 1 {
+		t.Errorf("Expected the new hash table to be hot, got %v", bands)
+	}
+	// PostgreSQL adds the columns without a default
+	rec := NewRecorder()
+	defer rec.Close()
+	pg, err := NewPostgresLsh(2, 1, "lshtable", rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Reset()
+	if err := pg.AddHashTables(1, nil); err != nil {
+		t.Fatal(err)
+	}
+	script := rec.Script()
+	if !strings.Contains(script, "ALTER TABLE lshtable ALTER COLUMN hv_3 SET NOT NULL") {
+		t.Errorf("Expected the new columns to be set NOT NULL, got %s", script)
+	}
+}
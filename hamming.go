@@ -0,0 +1,127 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// Neighbor is an ID found by QueryHamming, with the Hamming distance
+// between its Signature and the query Signature.
+type Neighbor struct {
+	Id       int
+	Distance int
+}
+
+// HammingDistance returns the number of bits that differ between the
+// hash values of a and b, which must have the same size.
+// For SimHash Signatures, it estimates the angle between the hashed
+// vectors, see HammingAngle.
+func HammingDistance(a, b Signature) int {
+	d := 0
+	for i := range a {
+		d += bits.OnesCount64(uint64(a[i] ^ b[i]))
+	}
+	return d
+}
+
+// HammingAngle returns the angle, in radians, between two vectors
+// estimated from the Hamming distance between their SimHash
+// Signatures of n bits in total.
+func HammingAngle(distance, n int) float64 {
+	return math.Pi * float64(distance) / float64(n)
+}
+
+// QueryHamming finds the candidates of sig like QueryIDs, and returns
+// the topN closest to sig by the Hamming distance between their stored
+// Signatures, the closest first, and by ascending ID between equal
+// distances.
+// It re-ranks SimHash candidates, where the hash values hold the bits
+// of the SimHash, one or many per hash value.
+// With WithBBit, the distance is over the stored lowest bits of the
+// hash values.
+// It is not supported with WithBandKeys.
+func (lsh *SqlLsh) QueryHamming(sig Signature, topN int) ([]Neighbor, error) {
+	if lsh.keysOnly {
+		return nil, ErrUnsupported
+	}
+	if topN < 1 {
+		return nil, ErrInvalidParameter
+	}
+	ids, err := lsh.QueryIDs(sig)
+	if err != nil {
+		return nil, err
+	}
+	sigs, err := lsh.signatures(ids)
+	if err != nil {
+		return nil, wrapErr("query", err)
+	}
+	if lsh.bits > 0 {
+		sig = truncateBits(sig, lsh.bits)
+	}
+	neighbors := make([]Neighbor, 0, len(sigs))
+	for id, other := range sigs {
+		neighbors = append(neighbors, Neighbor{Id: id, Distance: HammingDistance(sig, other)})
+	}
+	sort.Slice(neighbors, func(i, j int) bool {
+		if neighbors[i].Distance != neighbors[j].Distance {
+			return neighbors[i].Distance < neighbors[j].Distance
+		}
+		return neighbors[i].Id < neighbors[j].Id
+	})
+	if len(neighbors) > topN {
+		neighbors = neighbors[:topN]
+	}
+	return neighbors, nil
+}
+
+// truncateBits returns the lowest b bits of the hash values of sig, as
+// stored by WithBBit.
+func truncateBits(sig Signature, b int) Signature {
+	mask := uint(1)<<uint(b) - 1
+	truncated := make(Signature, len(sig))
+	for i, v := range sig {
+		truncated[i] = v & mask
+	}
+	return truncated
+}
+
+// signatures returns the stored Signatures of the IDs in the index,
+// looked up with as few queries as the parameter limit of the database
+// allows.
+func (lsh *SqlLsh) signatures(ids []int) (map[int]Signature, error) {
+	return lsh.signaturesIn(lsh.readDB(), ids)
+}
+
+// rowQuerier runs a query, such as a dbConn or a *sql.Tx.
+type rowQuerier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// signaturesIn is like signatures, running the queries with q.
+func (lsh *SqlLsh) signaturesIn(q rowQuerier, ids []int) (map[int]Signature, error) {
+	sigs := make(map[int]Signature, len(ids))
+	err := lsh.inChunks(len(ids), func(i int) interface{} { return ids[i] },
+		func(in string, args []interface{}) error {
+			rows, err := q.Query(fmt.Sprintf("SELECT %s FROM %s WHERE %sid IN (%s)",
+				lsh.entryCols(), lsh.tableName, lsh.liveCond(), in), args...)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				e, err := lsh.scanRow(rows)
+				if err != nil {
+					return err
+				}
+				sigs[e.Id] = e.Signature
+			}
+			return rows.Err()
+		})
+	if err != nil {
+		return nil, err
+	}
+	return sigs, nil
+}
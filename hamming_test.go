@@ -0,0 +1,53 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func Test_HammingDistance(t *testing.T) {
+	if d := HammingDistance(Signature{0, 1, 3}, Signature{1, 1, 0}); d != 3 {
+		t.Errorf("Expected 3, got %d", d)
+	}
+	if a := HammingAngle(8, 16); a != math.Pi/2 {
+		t.Errorf("Expected Pi/2, got %f", a)
+	}
+}
+
+func Test_QueryHamming(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	query := Signature{1, 0, 1, 0, 1, 1, 0, 0}
+	sigs := []Signature{
+		{1, 0, 1, 0, 0, 0, 1, 1}, // Distance 4
+		{1, 0, 1, 0, 1, 1, 0, 1}, // Distance 1
+		{1, 0, 1, 0, 1, 1, 0, 0}, // Distance 0
+		{0, 1, 0, 1, 0, 0, 1, 1}, // No collision
+	}
+	for _, opts := range [][]Option{nil, {WithBBit(1)}} {
+		db.Exec("DROP TABLE lshtable")
+		db.Exec("DROP TABLE lshtable_meta")
+		lsh, err := NewSqliteLsh(4, 2, "lshtable", db, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := lsh.BatchInsert([]int{0, 1, 2, 3}, sigs); err != nil {
+			t.Fatal(err)
+		}
+		neighbors, err := lsh.QueryHamming(query, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []Neighbor{{Id: 2, Distance: 0}, {Id: 1, Distance: 1}}
+		if !reflect.DeepEqual(neighbors, expected) {
+			t.Errorf("Expected %v, got %v", expected, neighbors)
+		}
+	}
+}
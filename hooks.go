@@ -0,0 +1,81 @@
+package sqllsh
+
+// InsertInfo describes the Signatures about to be inserted, given to
+// the insert hooks.
+type InsertInfo struct {
+	Op    string      // "insert", "batch insert" or "bulk load", as in Event
+	Table string      // Table of the index
+	IDs   []int       // IDs of the Signatures, nil for InsertAuto
+	Sigs  []Signature // Signatures to insert
+}
+
+// InsertHook is called before Signatures are inserted, and may replace
+// info.Sigs, but must not modify the Signatures in place.
+// If it returns an error, the insert does not run, and fails with an
+// *OpError wrapping that error.
+type InsertHook func(info *InsertInfo) error
+
+// QueryInfo describes a query about to run, given to the query hooks.
+type QueryInfo struct {
+	// "query" for Query, QueryIDs and the methods built on them, or
+	// "query iter", "query bands", "query prefix", "query candidates",
+	// "query page", "query containment", "query probe" or "count"
+	Op    string
+	Table string    // Table of the index
+	Sig   Signature // Query Signature
+}
+
+// QueryHook is called before a query runs, and may replace info.Sig,
+// but must not modify it in place.
+// If it returns an error, the query does not run, and fails with an
+// *OpError wrapping that error.
+type QueryHook func(info *QueryInfo) error
+
+// WithInsertHook adds h to the hooks called before inserting, for
+// example to validate the Signatures, count them or log them for
+// auditing.
+// The hooks are called in the order they are added, each one seeing
+// the Signatures set by the previous ones, and the first error stops
+// the chain.
+func WithInsertHook(h InsertHook) Option {
+	return func(lsh *SqlLsh) {
+		lsh.insertHooks = append(lsh.insertHooks, h)
+	}
+}
+
+// WithQueryHook adds h to the hooks called before querying, which are
+// called like the ones of WithInsertHook.
+func WithQueryHook(h QueryHook) Option {
+	return func(lsh *SqlLsh) {
+		lsh.queryHooks = append(lsh.queryHooks, h)
+	}
+}
+
+// beforeInsert runs the insert hooks, returning the Signatures to
+// insert.
+func (lsh *SqlLsh) beforeInsert(op string, ids []int, sigs []Signature) ([]Signature, error) {
+	if len(lsh.insertHooks) == 0 {
+		return sigs, nil
+	}
+	info := &InsertInfo{Op: op, Table: lsh.tableName, IDs: ids, Sigs: sigs}
+	for _, h := range lsh.insertHooks {
+		if err := h(info); err != nil {
+			return nil, wrapErr(op, err)
+		}
+	}
+	return info.Sigs, nil
+}
+
+// beforeQuery runs the query hooks, returning the Signature to query.
+func (lsh *SqlLsh) beforeQuery(op string, sig Signature) (Signature, error) {
+	if len(lsh.queryHooks) == 0 {
+		return sig, nil
+	}
+	info := &QueryInfo{Op: op, Table: lsh.tableName, Sig: sig}
+	for _, h := range lsh.queryHooks {
+		if err := h(info); err != nil {
+			return nil, wrapErr(op, err)
+		}
+	}
+	return info.Sig, nil
+}
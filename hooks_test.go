@@ -0,0 +1,123 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func Test_Hooks(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	errVeto := errors.New("veto")
+	var ops []string
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db,
+		WithInsertHook(func(info *InsertInfo) error {
+			ops = append(ops, info.Op)
+			for _, id := range info.IDs {
+				if id < 0 {
+					return errVeto
+				}
+			}
+			return nil
+		}),
+		// Replaces the Signatures, the second hook sees the new ones
+		WithInsertHook(func(info *InsertInfo) error {
+			sigs := make([]Signature, len(info.Sigs))
+			for i, sig := range info.Sigs {
+				sigs[i] = append(Signature{}, sig...)
+				sigs[i][0] = 0
+			}
+			info.Sigs = sigs
+			return nil
+		}),
+		WithQueryHook(func(info *QueryInfo) error {
+			ops = append(ops, info.Op)
+			if info.Sig[1] == 9 {
+				return errVeto
+			}
+			info.Sig = append(Signature{}, info.Sig...)
+			info.Sig[0] = 0
+			return nil
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Insert(1, Signature{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.BatchInsert([]int{2, -1}, []Signature{{1, 2, 3, 4}, {1, 2, 3, 4}}); !errors.Is(err, errVeto) {
+		t.Errorf("Expected the veto error, got %v", err)
+	}
+	found, err := lsh.QueryIDs(Signature{5, 2, 7, 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0] != 1 {
+		t.Errorf("Expected [1], got %v", found)
+	}
+	if _, err := lsh.CountCandidates(Signature{5, 9, 7, 7}); !errors.Is(err, errVeto) {
+		t.Errorf("Expected the veto error, got %v", err)
+	}
+	expected := []string{"insert", "batch insert", "query", "count"}
+	if len(ops) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, ops)
+	}
+	for i := range ops {
+		if ops[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, ops)
+		}
+	}
+}
+
+func Test_HooksRunOnce(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var inserts, queries []string
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithPStable(1, 0),
+		// Moves the Signatures away from the ones of the caller
+		WithInsertHook(func(info *InsertInfo) error {
+			inserts = append(inserts, info.Op)
+			sig := append(Signature{}, info.Sigs[0]...)
+			sig[0] = 7
+			info.Sigs = []Signature{sig}
+			return nil
+		}),
+		WithQueryHook(func(info *QueryInfo) error {
+			queries = append(queries, info.Op)
+			return nil
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Insert(1, Signature{7, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	// The check sees the Signature of the hook, which collides with 1
+	dups, inserted, err := lsh.InsertIfNovel(2, Signature{0, 2, 3, 4}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inserted || len(dups) != 1 {
+		t.Errorf("Expected the duplicate of 1 not to be inserted, got %v %v", dups, inserted)
+	}
+	if len(inserts) != 2 {
+		t.Errorf("Expected the insert hook to run once per insert, got %v", inserts)
+	}
+	if _, err := lsh.QueryFloatProbe([]float64{0.1, 0.1, 0.1, 0.1}, 2); err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 1 || queries[0] != "query probe" {
+		t.Errorf("Expected the query hook to run once, got %v", queries)
+	}
+}
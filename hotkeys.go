@@ -0,0 +1,147 @@
+package sqllsh
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// WithHotKeys leaves out of the queries the hash tables where the hash
+// key of the query Signature is shared by more than threshold
+// Signatures, such as the hash keys of a degenerate all-zeros
+// Signature.
+// Such hash keys dominate the query latency while adding candidates
+// that are mostly false positives.
+// The hot hash keys are found by the first query, rather than when the
+// index is created as it scans each hash table, and by RefreshHotKeys,
+// which should be called after loading many Signatures. They are local
+// to the SqlLsh like the Bloom filters of WithBloomFilters.
+// HotBands tells which hash tables a query leaves out.
+func WithHotKeys(threshold int64) Option {
+	return func(lsh *SqlLsh) {
+		lsh.hot = &hotKeys{threshold: threshold}
+	}
+}
+
+// hotKeys is the set of hot hash keys of each hash table.
+// The methods can be called on a nil hotKeys, which has no hot keys.
+type hotKeys struct {
+	mu        sync.RWMutex
+	load      sync.Mutex // Held while finding the hot keys the first time
+	threshold int64
+	keys      []map[string]bool // nil until the hot keys are found
+}
+
+// loadHotKeys finds the hot hash keys if they have not been found yet.
+func (lsh *SqlLsh) loadHotKeys() error {
+	if lsh.hot == nil {
+		return nil
+	}
+	lsh.hot.load.Lock()
+	defer lsh.hot.load.Unlock()
+	lsh.hot.mu.RLock()
+	loaded := lsh.hot.keys != nil
+	lsh.hot.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+	_, err := lsh.RefreshHotKeys()
+	return err
+}
+
+// isHot reports whether values are the values of a hot hash key of
+// band.
+func (h *hotKeys) isHot(band int, values []interface{}) bool {
+	if h == nil {
+		return false
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return band < len(h.keys) && h.keys[band][hotKeyString(values)]
+}
+
+// hotKeyString returns the map key of the values of a hash key, as
+// given in the arguments of a query or read from the table.
+func hotKeyString(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case uint:
+			parts[i] = fmt.Sprint(int64(v))
+		case uint64:
+			parts[i] = fmt.Sprint(int64(v))
+		default:
+			parts[i] = fmt.Sprint(v)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// HotBands returns the hash tables where the hash key of sig is hot,
+// which the queries of sig leave out.
+func (lsh *SqlLsh) HotBands(sig Signature) ([]int, error) {
+	if len(sig) != lsh.k*lsh.l {
+		return nil, ErrSignatureSizeMismatch
+	}
+	if err := lsh.loadHotKeys(); err != nil {
+		return nil, err
+	}
+	var bands []int
+	for band := 0; band < lsh.l; band++ {
+		if lsh.hot.isHot(band, lsh.bandValues(sig, band)) {
+			bands = append(bands, band)
+		}
+	}
+	return bands, nil
+}
+
+// maybeBands returns the hash tables in which the hash key of sig may
+// collide and is not hot, or nil if it can be any of them.
+func (lsh *SqlLsh) maybeBands(sig Signature) []int {
+	bands := lsh.bloom.bands(sig, lsh.k)
+	if lsh.hot == nil {
+		return bands
+	}
+	if bands == nil {
+		bands = lsh.allBands()
+	}
+	cold := make([]int, 0, len(bands))
+	for _, band := range bands {
+		if !lsh.hot.isHot(band, lsh.bandValues(sig, band)) {
+			cold = append(cold, band)
+		}
+	}
+	return cold
+}
+
+// RefreshHotKeys finds the hot hash keys in the table, which replace
+// the ones found before, and returns them with their counts.
+// It returns ErrUnsupported without WithHotKeys.
+func (lsh *SqlLsh) RefreshHotKeys() ([]BandKey, error) {
+	if lsh.hot == nil {
+		return nil, ErrUnsupported
+	}
+	keys := make([]map[string]bool, lsh.l)
+	var hot []BandKey
+	for band := range keys {
+		keys[band] = make(map[string]bool)
+		found, err := lsh.bandKeyCounts(band, lsh.hot.threshold, 0)
+		if err != nil {
+			return nil, wrapErr("refresh hot keys", err)
+		}
+		for _, key := range found {
+			values := make([]interface{}, len(key.Values))
+			for i, v := range key.Values {
+				values[i] = v
+			}
+			keys[band][hotKeyString(values)] = true
+		}
+		hot = append(hot, found...)
+	}
+	lsh.hot.mu.Lock()
+	lsh.hot.keys = keys
+	lsh.hot.mu.Unlock()
+	// The cached results may include the hash tables left out now
+	lsh.cache.clear()
+	return hot, nil
+}
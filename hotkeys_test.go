@@ -0,0 +1,138 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_HotKeys(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, layout := range [][]Option{nil, {WithCompactLayout()}} {
+		db.Exec("DROP TABLE lshtable")
+		db.Exec("DROP TABLE lshtable_meta")
+		lsh, err := NewSqliteLsh(2, 3, "lshtable", db, append(layout, WithHotKeys(10))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs := randomSigs(20, 6)
+		ids := make([]int, 0)
+		for i := range sigs {
+			ids = append(ids, i)
+		}
+		zero := make(Signature, 6)
+		for i := 0; i < 20; i++ {
+			sigs = append(sigs, zero)
+			ids = append(ids, len(ids))
+		}
+		if err := lsh.BatchInsert(ids, sigs); err != nil {
+			t.Fatal(err)
+		}
+		hot, err := lsh.RefreshHotKeys()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hot) != 3 || hot[0].Count != 20 {
+			t.Errorf("Expected one hot key per hash table, got %v", hot)
+		}
+		if bands, err := lsh.HotBands(zero); err != nil || !reflect.DeepEqual(bands, []int{0, 1, 2}) {
+			t.Errorf("Expected all hash tables to be hot, got %v, %v", bands, err)
+		}
+		found, err := lsh.QueryIDs(zero)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(found) != 0 {
+			t.Errorf("Expected no IDs, got %v", found)
+		}
+		found, err = lsh.QueryIDs(sigs[3])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !containsID(found, 3) {
+			t.Errorf("Expected 3 in %v", found)
+		}
+		// The hot keys are found by the first query after opening the
+		// index
+		lsh, err = NewSqliteLsh(2, 3, "lshtable", db, append(layout, WithHotKeys(10))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, err := lsh.CountCandidates(zero); err != nil || n != 0 {
+			t.Errorf("Expected no candidates, got %d, %v", n, err)
+		}
+	}
+}
+
+func Test_HotKeysLazy(t *testing.T) {
+	rec := NewRecorder()
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", rec, WithHotKeys(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scans := func() int {
+		n := 0
+		for _, s := range rec.Statements() {
+			if strings.Contains(s.Query, "GROUP BY") {
+				n++
+			}
+		}
+		return n
+	}
+	if n := scans(); n != 0 {
+		t.Errorf("Expected no scan when creating the index, got %d", n)
+	}
+	sig := Signature{1, 2, 3, 4, 5, 6}
+	for i := 0; i < 2; i++ {
+		if _, err := lsh.QueryIDs(sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := scans(); n != 3 {
+		t.Errorf("Expected one scan per hash table, got %d", n)
+	}
+}
+
+func Test_HotKeysReshape(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithHotKeys(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := lsh.Insert(i, Signature{uint(i), 1, 1, uint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bands, err := lsh.HotBands(Signature{9, 1, 1, 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bands) != 0 {
+		t.Errorf("Expected no hot hash tables, got %v", bands)
+	}
+	if err := lsh.Reshape(1, 4, nil); err != nil {
+		t.Fatal(err)
+	}
+	// The hash values shared by every Signature are now hot hash keys
+	bands, err = lsh.HotBands(Signature{9, 1, 1, 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(bands, []int{1, 2}) {
+		t.Errorf("Expected hash tables [1 2] to be hot, got %v", bands)
+	}
+}
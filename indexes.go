@@ -0,0 +1,150 @@
+package sqllsh
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// WithDeferredIndexes puts the index in bulk load mode: queries fail
+// with ErrIndexMissing until Index is called, instead of silently
+// scanning the whole table.
+// If lazy is true, the first query builds the indexes instead of
+// failing.
+// An existing table that already has its indexes can be queried right
+// away.
+func WithDeferredIndexes(lazy bool) Option {
+	return func(lsh *SqlLsh) {
+		lsh.deferred = &deferredIndexes{lazy: lazy}
+	}
+}
+
+// deferredIndexes tracks whether the indexes of a table in bulk load
+// mode are built.
+type deferredIndexes struct {
+	mu    sync.Mutex
+	lazy  bool
+	built bool
+}
+
+// set records whether the indexes are built.
+func (d *deferredIndexes) set(built bool) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.built = built
+	d.mu.Unlock()
+}
+
+// ready returns nil if the table can be queried, building the indexes
+// first in lazy mode and finding the hot hash keys the first time.
+func (lsh *SqlLsh) ready() error {
+	if err := lsh.loadHotKeys(); err != nil {
+		return err
+	}
+	d := lsh.deferred
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.built {
+		return nil
+	}
+	if !d.lazy {
+		return ErrIndexMissing
+	}
+	if err := lsh.index(); err != nil {
+		return err
+	}
+	d.built = true
+	return nil
+}
+
+// DropIndexes drops the indexes built by Index, so that a large load
+// does not have to update them row by row; Index must be called again
+// afterwards.
+// With WithDeferredIndexes, queries fail again until then.
+func (lsh *SqlLsh) DropIndexes() error {
+	if lsh.dialect.createIndexFmt == "" {
+		lsh.deferred.set(false)
+		return nil
+	}
+	names := make(map[string]bool)
+	if lsh.dialect.indexesQuery != "" {
+		var err error
+		if names, err = lsh.indexNames(); err != nil {
+			return wrapErr("drop indexes", err)
+		}
+	}
+	var stmts []string
+	for i := 0; i < lsh.l; i++ {
+		// Without the names of the indexes, they are all dropped
+		if names[strings.ToLower(lsh.indexName(i))] || lsh.dialect.indexesQuery == "" {
+			stmts = append(stmts, fmt.Sprintf(lsh.dialect.dropIndexFmt, lsh.indexName(i), lsh.tableName))
+		}
+	}
+	if lsh.dialect.ddl != nil {
+		if err := lsh.dialect.ddl(lsh.db.DB, stmts); err != nil {
+			return wrapErr("drop indexes", err)
+		}
+		lsh.deferred.set(false)
+		return nil
+	}
+	if lsh.dialect.implicitCommit {
+		for _, stmt := range stmts {
+			if _, err := lsh.db.Exec(stmt); err != nil {
+				return wrapErr("drop indexes", err)
+			}
+		}
+		lsh.deferred.set(false)
+		return nil
+	}
+	tx, err := lsh.db.Begin()
+	if err != nil {
+		return wrapErr("drop indexes", err)
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			tx.Rollback()
+			return wrapErr("drop indexes", err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return wrapErr("drop indexes", err)
+	}
+	lsh.deferred.set(false)
+	return nil
+}
+
+// Reindex rebuilds the indexes of the table, which shrinks them after
+// many deletes or updates.
+// It uses the statement of the database rebuilding indexes in place if
+// there is one, otherwise the indexes are dropped and built again, so
+// queries are slow in between.
+func (lsh *SqlLsh) Reindex() error {
+	done := lsh.observe("reindex")
+	err := lsh.reindex()
+	done(int64(lsh.l), err)
+	return err
+}
+
+func (lsh *SqlLsh) reindex() error {
+	if lsh.dialect.reindexFmt == "" {
+		if err := lsh.DropIndexes(); err != nil {
+			return err
+		}
+		if err := lsh.index(); err != nil {
+			return err
+		}
+		lsh.deferred.set(true)
+		return nil
+	}
+	if _, err := lsh.db.Exec(fmt.Sprintf(lsh.dialect.reindexFmt, lsh.tableName)); err != nil {
+		return wrapErr("reindex", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func Test_DeferredIndexes(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db, WithDeferredIndexes(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(10, 6)
+	if err := lsh.BatchInsert([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, sigs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lsh.QueryIDs(sigs[0]); !errors.Is(err, ErrIndexMissing) {
+		t.Errorf("Expected ErrIndexMissing before Index, got %v", err)
+	}
+	if _, err := lsh.CountCandidates(sigs[0]); !errors.Is(err, ErrIndexMissing) {
+		t.Errorf("Expected ErrIndexMissing before Index, got %v", err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := lsh.QueryIDs(sigs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsID(ids, 0) {
+		t.Errorf("Expected 0 in %v", ids)
+	}
+	// Reopening the indexed table does not need Index again
+	reopened, err := NewSqliteLsh(2, 3, "lshtable", db, WithDeferredIndexes(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reopened.QueryIDs(sigs[0]); err != nil {
+		t.Error(err)
+	}
+	if err := lsh.DropIndexes(); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Validate(); !errors.Is(err, ErrIndexMissing) {
+		t.Errorf("Expected ErrIndexMissing after DropIndexes, got %v", err)
+	}
+	if _, err := lsh.QueryIDs(sigs[0]); !errors.Is(err, ErrIndexMissing) {
+		t.Errorf("Expected ErrIndexMissing after DropIndexes, got %v", err)
+	}
+	// Dropping again does nothing
+	if err := lsh.DropIndexes(); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_LazyIndexes(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db, WithDeferredIndexes(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(10, 6)
+	if err := lsh.BatchInsert([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, sigs); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := lsh.QueryIDs(sigs[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsID(ids, 3) {
+		t.Errorf("Expected 3 in %v", ids)
+	}
+	if err := lsh.Validate(); err != nil {
+		t.Errorf("Expected the indexes built by the query, got %v", err)
+	}
+}
+
+func Test_Reindex(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without a statement rebuilding indexes in place, they are dropped
+	// and built again
+	for _, reindexFmt := range []string{sqliteDialect.reindexFmt, ""} {
+		if _, err := db.Exec("DROP TABLE IF EXISTS lshtable"); err != nil {
+			t.Fatal(err)
+		}
+		d := sqliteDialect
+		d.reindexFmt = reindexFmt
+		lsh, err := newSqlLsh(2, 3, "lshtable", db, d, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs := randomSigs(10, 6)
+		if err := lsh.BatchInsert([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, sigs); err != nil {
+			t.Fatal(err)
+		}
+		if err := lsh.Index(); err != nil {
+			t.Fatal(err)
+		}
+		if err := lsh.Delete(1); err != nil {
+			t.Fatal(err)
+		}
+		if err := lsh.Reindex(); err != nil {
+			t.Fatal(err)
+		}
+		if err := lsh.Validate(); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package sqllsh
+
+// IndexType is the kind of index created by Index for each hash table.
+type IndexType int
+
+const (
+	// IndexBTree creates a B-Tree index on all hash values of a hash
+	// key. It is supported by all databases.
+	IndexBTree IndexType = iota
+	// IndexHash creates a hash index, which only supports equality
+	// and can be smaller and faster than a B-Tree.
+	// As hash indexes cover a single column, only the first hash value
+	// of each hash key is indexed, and the rest are checked on the
+	// rows found.
+	IndexHash
+)
+
+// WithIndexType sets the kind of index created by Index.
+// The constructor returns ErrUnsupported if the database does not
+// support the index type.
+func WithIndexType(t IndexType) Option {
+	return func(lsh *SqlLsh) {
+		lsh.indexType = t
+	}
+}
+
+// indexCols returns the number of hash values of each hash key covered
+// by the indexes.
+func (lsh *SqlLsh) indexCols() int {
+	if lsh.indexType == IndexHash {
+		return 1
+	}
+	return len(lsh.bandCols(0))
+}
+
+// WithCoveringIndexes adds the id to the index of each hash table, so
+// queries are answered by index-only scans without reading the rows.
+// On SQLite the indexes always contain the id, as it is the rowid.
+// It cannot be used with IndexHash.
+func WithCoveringIndexes() Option {
+	return func(lsh *SqlLsh) {
+		lsh.covering = true
+	}
+}
+
+// WithPartialIndexes leaves the entries marked deleted out of the index
+// of each hash table, keeping the indexes small when there are many
+// tombstones.
+// It has an effect only with WithSoftDelete.
+func WithPartialIndexes() Option {
+	return func(lsh *SqlLsh) {
+		lsh.partial = true
+	}
+}
+
+// indexSuffix returns the clauses following the columns of an index.
+func (lsh *SqlLsh) indexSuffix() string {
+	var s string
+	if lsh.covering {
+		s += lsh.dialect.includeClause
+	}
+	if lsh.partial && lsh.softDelete {
+		s += " WHERE deleted = 0"
+	}
+	return s
+}
@@ -0,0 +1,73 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_IndexType(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	if _, err := NewSqliteLsh(2, 3, "lshtable", db, WithIndexType(IndexHash)); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db, WithIndexType(IndexBTree))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Error(err)
+	}
+	removeTempFile(t, f)
+}
+
+func Test_IndexTypeHashStr(t *testing.T) {
+	lsh := &SqlLsh{k: 4, l: 2, tableName: "lshtable", dialect: postgresDialect,
+		indexType: IndexHash}
+	expected := "CREATE INDEX lshtable_ht_1 ON lshtable USING HASH (hv_4)"
+	if s := lsh.indexStr(1); s != expected {
+		t.Errorf("Expected %q, got %q", expected, s)
+	}
+}
+
+func Test_CoveringPartialIndexes(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db, WithSoftDelete(),
+		WithCoveringIndexes(), WithPartialIndexes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "CREATE INDEX lshtable_ht_1 ON lshtable (hv_2,hv_3) WHERE deleted = 0"
+	if s := lsh.indexStr(1); s != expected {
+		t.Errorf("Expected %q, got %q", expected, s)
+	}
+	lsh.Insert(0, Signature{1, 2, 3, 4, 5, 6})
+	lsh.Insert(1, Signature{1, 2, 3, 4, 5, 6})
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Delete(0); err != nil {
+		t.Fatal(err)
+	}
+	found, err := lsh.QueryIDs(Signature{1, 2, 3, 4, 5, 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0] != 1 {
+		t.Errorf("Expected [1], got %v", found)
+	}
+	pg := &SqlLsh{k: 2, l: 3, tableName: "lshtable", dialect: postgresDialect,
+		covering: true}
+	expected = "CREATE INDEX lshtable_ht_0 ON lshtable USING BTREE (hv_0,hv_1) INCLUDE (id)"
+	if s := pg.indexStr(0); s != expected {
+		t.Errorf("Expected %q, got %q", expected, s)
+	}
+	removeTempFile(t, f)
+}
@@ -0,0 +1,144 @@
+package sqllsh
+
+import "database/sql"
+
+// IDIterator lazily reads the IDs found by QueryIter.
+// Call Next before each Value, and check Err once Next returns false.
+// Close must be called if the iteration is stopped before Next
+// returns false.
+type IDIterator struct {
+	rows    *sql.Rows
+	session *querySession
+	id      int
+	err     error
+}
+
+// QueryIter is like Query, but returns an iterator over the IDs
+// instead of writing them to a channel.
+func (lsh *SqlLsh) QueryIter(sig Signature) (*IDIterator, error) {
+	sig, err := lsh.beforeQuery("query iter", sig)
+	if err != nil {
+		return nil, err
+	}
+	s, err := lsh.beginQuery()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := lsh.queryRows(s, sig)
+	if err != nil || rows == nil {
+		// Without rows, Next returns false right away and the session
+		// would not be ended
+		s.end()
+		if err != nil {
+			return nil, err
+		}
+		return &IDIterator{}, nil
+	}
+	return &IDIterator{rows: rows, session: s}, nil
+}
+
+// Next advances to the next ID, returning false when there are no
+// more IDs or an error occurred.
+func (it *IDIterator) Next() bool {
+	if it.err != nil || it.rows == nil {
+		it.Close()
+		return false
+	}
+	if !it.rows.Next() {
+		it.err = wrapErr("query", it.rows.Err())
+		it.Close()
+		return false
+	}
+	if err := it.rows.Scan(&it.id); err != nil {
+		it.err = wrapErr("query", err)
+		it.Close()
+		return false
+	}
+	return true
+}
+
+// Value returns the current ID.
+func (it *IDIterator) Value() int {
+	return it.id
+}
+
+// Err returns the error, if any, encountered during the iteration.
+func (it *IDIterator) Err() error {
+	return it.err
+}
+
+// Close releases the underlying database rows.
+// It is safe to call Close more than once.
+func (it *IDIterator) Close() error {
+	defer func() {
+		it.session.end()
+		it.session = nil
+	}()
+	if it.rows == nil {
+		return nil
+	}
+	err := it.rows.Close()
+	it.rows = nil
+	return err
+}
+
+// EntryIterator lazily reads the Entries returned by ScanIter.
+// It is used the same way as IDIterator.
+type EntryIterator struct {
+	lsh   *SqlLsh
+	rows  *sql.Rows
+	entry Entry
+	err   error
+}
+
+// ScanIter is like Scan, but returns an iterator over the Entries
+// instead of writing them to a channel.
+func (lsh *SqlLsh) ScanIter() (*EntryIterator, error) {
+	rows, err := lsh.read(lsh.scanStmt, lsh.scanStr())
+	if err != nil {
+		return nil, wrapErr("scan", err)
+	}
+	return &EntryIterator{lsh: lsh, rows: rows}, nil
+}
+
+// Next advances to the next Entry, returning false when there are no
+// more Entries or an error occurred.
+func (it *EntryIterator) Next() bool {
+	if it.err != nil || it.rows == nil {
+		return false
+	}
+	if !it.rows.Next() {
+		it.err = wrapErr("scan", it.rows.Err())
+		it.Close()
+		return false
+	}
+	e, err := it.lsh.scanEntry(it.rows)
+	if err != nil {
+		it.err = err
+		it.Close()
+		return false
+	}
+	it.entry = e
+	return true
+}
+
+// Value returns the current Entry.
+func (it *EntryIterator) Value() Entry {
+	return it.entry
+}
+
+// Err returns the error, if any, encountered during the iteration.
+func (it *EntryIterator) Err() error {
+	return it.err
+}
+
+// Close releases the underlying database rows.
+// It is safe to call Close more than once.
+func (it *EntryIterator) Close() error {
+	if it.rows == nil {
+		return nil
+	}
+	err := it.rows.Close()
+	it.rows = nil
+	return err
+}
@@ -0,0 +1,120 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func Test_QueryIter(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Error(err)
+	}
+	sigs := randomSigs(10, 4)
+	for i := range sigs {
+		lsh.Insert(i, sigs[i])
+	}
+	for i := range sigs {
+		it, err := lsh.QueryIter(sigs[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := false
+		for it.Next() {
+			if it.Value() == i {
+				found = true
+			}
+		}
+		if err := it.Err(); err != nil {
+			t.Error(err)
+		}
+		if !found {
+			t.Error("Error in query")
+		}
+	}
+	removeTempFile(t, f)
+}
+
+func Test_ScanIter(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Error(err)
+	}
+	sigs := randomSigs(10, 4)
+	for i := range sigs {
+		lsh.Insert(i, sigs[i])
+	}
+	it, err := lsh.ScanIter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for it.Next() {
+		e := it.Value()
+		if !(e.Id >= 0 && len(e.Signature) == 4) {
+			t.Fatal("Incorrect signature returned")
+		}
+		count++
+	}
+	if err := it.Err(); err != nil {
+		t.Error(err)
+	}
+	if count != len(sigs) {
+		t.Fatal("Did not retrieve the same number inserted")
+	}
+	// Stop early, the rows must be released
+	it, err = lsh.ScanIter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !it.Next() {
+		t.Fatal("Expected an entry")
+	}
+	if err := it.Close(); err != nil {
+		t.Error(err)
+	}
+	if it.Next() {
+		t.Error("Next should return false after Close")
+	}
+	removeTempFile(t, f)
+}
+
+func Test_QueryIterNoBands(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithBloomFilters(100, 0.01),
+		WithQueryTimeout(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Insert(0, Signature{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	// The Bloom filters leave no hash table to query
+	it, err := lsh.QueryIter(Signature{5, 6, 7, 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.session != nil {
+		t.Error("Expected the session to be ended without rows")
+	}
+	if it.Next() || it.Err() != nil {
+		t.Errorf("Expected no IDs and no error, got %v", it.Err())
+	}
+}
@@ -0,0 +1,79 @@
+package sqllsh
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SimilarityJoin finds all pairs of IDs in the table whose Signatures
+// have at least minCollisions hash key collisions, then writes the
+// pairs to a given output channel.
+// The smaller ID of a pair is always first.
+// The pairs are found using a single self-join inside the database,
+// which is much faster than querying the index with every Signature.
+// The caller is responsible for closing the channel.
+func (lsh *SqlLsh) SimilarityJoin(minCollisions int, out chan [2]int) error {
+	return lsh.join(lsh, minCollisions, out)
+}
+
+// Join finds all pairs of IDs, the first from lsh and the second from
+// other, whose Signatures have at least minCollisions hash key
+// collisions, then writes the pairs to a given output channel.
+// This is useful for record linkage between two datasets.
+// Both indexes must have the same k and l, and must use the same
+// database connection object, as the pairs are found using a single
+// join inside the database.
+// The caller is responsible for closing the channel.
+func (lsh *SqlLsh) Join(other *SqlLsh, minCollisions int, out chan [2]int) error {
+	if lsh.k != other.k || lsh.l != other.l || lsh.db != other.db || lsh.packed != other.packed || lsh.bits != other.bits {
+		return ErrIncompatible
+	}
+	return lsh.join(other, minCollisions, out)
+}
+
+// join finds the pairs of IDs from lsh and other with at least
+// minCollisions hash key collisions.
+func (lsh *SqlLsh) join(other *SqlLsh, minCollisions int, out chan [2]int) error {
+	if minCollisions < 1 || minCollisions > lsh.l {
+		return ErrInvalidParameter
+	}
+	rows, err := lsh.readDB().Query(joinStr(lsh, other), minCollisions)
+	if err != nil {
+		return wrapErr("join", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var pair [2]int
+		if err := rows.Scan(&pair[0], &pair[1]); err != nil {
+			return wrapErr("join", err)
+		}
+		out <- pair
+	}
+	return wrapErr("join", rows.Err())
+}
+
+// joinStr returns the query counting the hash key collisions between
+// the rows of a and b, one band at a time.
+// When a and b are the same index, each pair is returned once.
+func joinStr(a, b *SqlLsh) string {
+	bandSeg := make([]string, a.l)
+	for i := 0; i < a.l; i++ {
+		seg := make([]string, 0, a.k+3)
+		if a == b {
+			seg = append(seg, "a.id < b.id")
+		}
+		if a.softDelete {
+			seg = append(seg, "a.deleted = 0")
+		}
+		if b.softDelete {
+			seg = append(seg, "b.deleted = 0")
+		}
+		for _, col := range a.bandCols(i) {
+			seg = append(seg, fmt.Sprintf("a.%s = b.%s", col, col))
+		}
+		bandSeg[i] = fmt.Sprintf("SELECT a.id AS id_a, b.id AS id_b FROM %s a, %s b WHERE ",
+			a.tableName, b.tableName) + strings.Join(seg, " AND ")
+	}
+	return "SELECT id_a, id_b FROM (\n" + strings.Join(bandSeg, "\nUNION ALL\n") +
+		fmt.Sprintf("\n) pairs GROUP BY id_a, id_b HAVING COUNT(*) >= %s", a.dialect.varFmt(0))
+}
@@ -0,0 +1,98 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_SimilarityJoin(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Error(err)
+	}
+	lsh.Insert(0, Signature{1, 2, 3, 4})
+	lsh.Insert(1, Signature{1, 2, 5, 6})
+	lsh.Insert(2, Signature{1, 2, 3, 4})
+	lsh.Insert(3, Signature{7, 8, 9, 10})
+	for _, c := range []struct {
+		minCollisions int
+		pairs         map[[2]int]bool
+	}{
+		{1, map[[2]int]bool{{0, 1}: true, {0, 2}: true, {1, 2}: true}},
+		{2, map[[2]int]bool{{0, 2}: true}},
+	} {
+		out := make(chan [2]int)
+		go func() {
+			if err := lsh.SimilarityJoin(c.minCollisions, out); err != nil {
+				t.Error(err)
+			}
+			close(out)
+		}()
+		count := 0
+		for pair := range out {
+			if !c.pairs[pair] {
+				t.Errorf("Unexpected pair %v", pair)
+			}
+			count++
+		}
+		if count != len(c.pairs) {
+			t.Errorf("Expected %d pairs, got %d", len(c.pairs), count)
+		}
+	}
+	if err := lsh.SimilarityJoin(0, nil); err != ErrInvalidParameter {
+		t.Error("Fail to raise error")
+	}
+	removeTempFile(t, f)
+}
+
+func Test_Join(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	a, err := NewSqliteLsh(2, 2, "tablea", db)
+	if err != nil {
+		t.Error(err)
+	}
+	b, err := NewSqliteLsh(2, 2, "tableb", db)
+	if err != nil {
+		t.Error(err)
+	}
+	a.Insert(0, Signature{1, 2, 3, 4})
+	a.Insert(1, Signature{5, 6, 7, 8})
+	b.Insert(0, Signature{1, 2, 9, 9})
+	b.Insert(1, Signature{9, 9, 7, 8})
+	b.Insert(2, Signature{1, 2, 3, 4})
+	pairs := map[[2]int]bool{{0, 0}: true, {1, 1}: true, {0, 2}: true}
+	out := make(chan [2]int)
+	go func() {
+		if err := a.Join(b, 1, out); err != nil {
+			t.Error(err)
+		}
+		close(out)
+	}()
+	count := 0
+	for pair := range out {
+		if !pairs[pair] {
+			t.Errorf("Unexpected pair %v", pair)
+		}
+		count++
+	}
+	if count != len(pairs) {
+		t.Errorf("Expected %d pairs, got %d", len(pairs), count)
+	}
+	c, err := NewSqliteLsh(2, 3, "tablec", db)
+	if err != nil {
+		t.Error(err)
+	}
+	if err := a.Join(c, 1, nil); err != ErrIncompatible {
+		t.Error("Fail to raise error")
+	}
+	removeTempFile(t, f)
+}
@@ -0,0 +1,66 @@
+package sqllsh
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BandKey is a hash key of a hash table and the number of Signatures
+// that have it.
+type BandKey struct {
+	Band   int     // Hash table of the hash key
+	Values []int64 // Values of the hash key columns of the hash table
+	Count  int64   // Number of Signatures with the hash key
+}
+
+// BandKeyStats returns the topN most frequent hash keys of band, which
+// is numbered from 0 to l-1, most frequent first.
+// A few hash keys shared by a large part of the table show a skew in
+// the Signatures, which WithHotKeys can leave out of the queries.
+// The query groups the whole table, so it can be slow on large tables.
+func (lsh *SqlLsh) BandKeyStats(band int, topN int) ([]BandKey, error) {
+	if band < 0 || band >= lsh.l || topN < 1 {
+		return nil, ErrInvalidParameter
+	}
+	keys, err := lsh.bandKeyCounts(band, 0, topN)
+	if err != nil {
+		return nil, wrapErr("band key stats", err)
+	}
+	return keys, nil
+}
+
+// bandKeyCounts returns the hash keys of band shared by more than min
+// Signatures, the most frequent first, at most limit of them unless
+// limit is zero.
+func (lsh *SqlLsh) bandKeyCounts(band int, min int64, limit int) ([]BandKey, error) {
+	bandCols := lsh.bandCols(band)
+	cols := strings.Join(bandCols, ", ")
+	where := ""
+	if cond := lsh.liveCond(); cond != "" {
+		where = " WHERE " + strings.TrimSuffix(cond, " AND ")
+	}
+	query := fmt.Sprintf("SELECT %s, COUNT(*) FROM %s%s GROUP BY %s HAVING COUNT(*) > %s ORDER BY COUNT(*) DESC",
+		cols, lsh.tableName, where, cols, lsh.dialect.varFmt(0))
+	if limit > 0 {
+		query += fmt.Sprintf(lsh.dialect.limitFmt, limit)
+	}
+	rows, err := lsh.readDB().Query(query, min)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	keys := make([]BandKey, 0)
+	for rows.Next() {
+		key := BandKey{Band: band, Values: make([]int64, len(bandCols))}
+		dest := make([]interface{}, len(key.Values)+1)
+		for i := range key.Values {
+			dest[i] = &key.Values[i]
+		}
+		dest[len(key.Values)] = &key.Count
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
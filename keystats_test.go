@@ -0,0 +1,46 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_BandKeyStats(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := []Signature{
+		{1, 2, 3, 4, 5, 6},
+		{1, 2, 0, 0, 0, 0},
+		{1, 2, 3, 4, 0, 0},
+		{7, 8, 3, 4, 0, 0},
+	}
+	if err := lsh.BatchInsert([]int{0, 1, 2, 3}, sigs); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := lsh.BandKeyStats(0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].Count != 3 || keys[0].Values[0] != 1 || keys[0].Values[1] != 2 {
+		t.Errorf("Expected (1, 2) 3 times, got %v", keys)
+	}
+	keys, err = lsh.BandKeyStats(2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0].Count != 3 || keys[1].Count != 1 {
+		t.Errorf("Expected counts 3 and 1, got %v", keys)
+	}
+	if _, err := lsh.BandKeyStats(3, 1); err != ErrInvalidParameter {
+		t.Errorf("Expected ErrInvalidParameter, got %v", err)
+	}
+}
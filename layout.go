@@ -0,0 +1,204 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+)
+
+// WithCompactLayout stores each Signature once, encoded in a single
+// binary column, and only one hash key column per hash table, holding
+// a 64-bit hash of the k hash values of its hash key.
+// The table then has l+2 columns instead of k*l+1, which makes inserts
+// and Get cheaper and the indexes smaller.
+// Two different hash keys can have the same hash, so a query may find
+// a few candidates more than with the default layout.
+// QueryPrefix and QueryAtLeast can only match full hash keys, and
+// AddHashTables and Reshape are not supported.
+// The option changes the table schema, so it must be used every time
+// the same table is opened.
+func WithCompactLayout() Option {
+	return func(lsh *SqlLsh) {
+		lsh.packed = true
+	}
+}
+
+// WithBandKeys is like WithCompactLayout, but does not store the
+// Signatures, so the table only has the id and the l hash key columns
+// whatever k is.
+// Scan returns Entries without Signatures, and Get and the Bloom
+// filters are not supported.
+// The option changes the table schema, so it must be used every time
+// the same table is opened.
+func WithBandKeys() Option {
+	return func(lsh *SqlLsh) {
+		lsh.packed = true
+		lsh.keysOnly = true
+	}
+}
+
+// WithKeyHash sets the 64-bit hash function of the hash keys used by
+// WithCompactLayout and WithBandKeys, which is given the k hash values
+// of a hash key as 8 big-endian bytes each.
+// The default is FNV-1a; a faster hash such as xxhash.Sum64 from
+// github.com/cespare/xxhash can be used instead.
+// The same hash function must be used every time the same table is
+// opened.
+func WithKeyHash(h func(b []byte) uint64) Option {
+	return func(lsh *SqlLsh) {
+		lsh.keyHash = h
+	}
+}
+
+// Get returns the Signature stored for id, or ErrNotFound if id is not
+// in the index.
+// It is not supported with WithBandKeys.
+func (lsh *SqlLsh) Get(id int) (Signature, error) {
+	if lsh.keysOnly {
+		return nil, ErrUnsupported
+	}
+	row := lsh.readDB().QueryRow(fmt.Sprintf("SELECT %s FROM %s WHERE %sid = %s",
+		lsh.entryCols(), lsh.tableName, lsh.liveCond(), lsh.dialect.varFmt(0)), id)
+	e, err := lsh.scanRow(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, wrapErr("get", err)
+	}
+	return e.Signature, nil
+}
+
+// BatchGet returns the Signatures stored for ids, by ID; the IDs not in
+// the index are not in the result.
+// The IDs are read with as few queries as the parameter limit of the
+// database allows.
+// It is not supported with WithBandKeys.
+func (lsh *SqlLsh) BatchGet(ids []int) (map[int]Signature, error) {
+	if lsh.keysOnly {
+		return nil, ErrUnsupported
+	}
+	sigs, err := lsh.signatures(ids)
+	if err != nil {
+		return nil, wrapErr("get", err)
+	}
+	return sigs, nil
+}
+
+// valueColDefs returns the definitions of the columns holding the
+// Signature, given the integer and binary column types.
+// The columns are NOT NULL: a NULL never equals a hash value, so a row
+// with one could not be found by some of the hash tables.
+func (lsh *SqlLsh) valueColDefs(intType, blobType string) []string {
+	if lsh.packed {
+		var defs []string
+		if !lsh.keysOnly {
+			defs = append(defs, "sig "+blobType+" NOT NULL")
+		}
+		for i := 0; i < lsh.l; i++ {
+			defs = append(defs, fmt.Sprintf("key_%d %s NOT NULL", i, intType))
+		}
+		return defs
+	}
+	var defs []string
+	for _, col := range lsh.hashCols() {
+		defs = append(defs, col+" "+intType+" NOT NULL")
+	}
+	return defs
+}
+
+// bandCols returns the columns holding the hash key of band.
+func (lsh *SqlLsh) bandCols(band int) []string {
+	if lsh.packed {
+		return []string{fmt.Sprintf("key_%d", band)}
+	}
+	if lsh.bits > 0 {
+		n := lsh.bitWords()
+		cols := make([]string, n)
+		for j := range cols {
+			cols[j] = fmt.Sprintf("bv_%d", n*band+j)
+		}
+		return cols
+	}
+	cols := make([]string, lsh.k)
+	for j := range cols {
+		cols[j] = fmt.Sprintf("hv_%d", lsh.k*band+j)
+	}
+	return cols
+}
+
+// bandWidth returns the number of arguments of the condition on one
+// band, using the first prefix hash values of the hash key.
+func (lsh *SqlLsh) bandWidth(prefix int) int {
+	if lsh.packed || lsh.bits > 0 {
+		return len(lsh.bandCols(0))
+	}
+	return prefix
+}
+
+// bandValues returns the values of the columns of band for sig.
+func (lsh *SqlLsh) bandValues(sig Signature, band int) []interface{} {
+	if lsh.packed {
+		return []interface{}{lsh.bandKey(sig, band)}
+	}
+	if lsh.bits > 0 {
+		return lsh.packBits(sig[lsh.k*band : lsh.k*(band+1)])
+	}
+	values := make([]interface{}, lsh.k)
+	for j := range values {
+		values[j] = sig[lsh.k*band+j]
+	}
+	return values
+}
+
+// fullKeys returns whether the layout can only match full hash keys.
+func (lsh *SqlLsh) fullKeys() bool {
+	return lsh.packed || lsh.bits > 0
+}
+
+// encodeSignature returns the binary encoding of sig stored by the
+// compact layout, 8 big-endian bytes per hash value.
+func encodeSignature(sig Signature) []byte {
+	buf := make([]byte, 8*len(sig))
+	for i, v := range sig {
+		binary.BigEndian.PutUint64(buf[8*i:], uint64(v))
+	}
+	return buf
+}
+
+// decodeSignature returns the Signature encoded by encodeSignature.
+func decodeSignature(buf []byte) Signature {
+	sig := make(Signature, len(buf)/8)
+	for i := range sig {
+		sig[i] = uint(binary.BigEndian.Uint64(buf[8*i:]))
+	}
+	return sig
+}
+
+// Scan implements sql.Scanner, reading a Signature stored by the compact
+// layout, so that Entry can be scanned by packages mapping columns to
+// struct fields, such as github.com/jmoiron/sqlx.
+func (sig *Signature) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		*sig = decodeSignature(src)
+	case nil:
+		*sig = nil
+	default:
+		return fmt.Errorf("sqllsh: cannot scan %T into a Signature", src)
+	}
+	return nil
+}
+
+// bandKey returns the hash of the hash key of band in sig, as stored by
+// the compact layout.
+func (lsh *SqlLsh) bandKey(sig Signature, band int) int64 {
+	b := encodeSignature(sig[lsh.k*band : lsh.k*(band+1)])
+	if lsh.keyHash != nil {
+		return int64(lsh.keyHash(b))
+	}
+	h := fnv.New64a()
+	h.Write(b)
+	return int64(h.Sum64())
+}
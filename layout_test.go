@@ -0,0 +1,164 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func Test_CompactLayout(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 4, "lshtable", db, WithCompactLayout())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(100, 8)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	for i, sig := range sigs {
+		found, err := lsh.QueryIDs(sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !containsID(found, i) {
+			t.Errorf("Expected %d in the result of its own Signature, got %v", i, found)
+		}
+		got, err := lsh.Get(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameSig(got, sig) {
+			t.Errorf("Expected %v for %d, got %v", sig, i, got)
+		}
+	}
+	if _, err := lsh.Get(len(sigs)); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	out := make(chan int)
+	if err := lsh.QueryPrefix(sigs[0], 1, out); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+	if _, err := NewSqliteLsh(2, 4, "lshtable", db); err != ErrTableExists {
+		t.Errorf("Expected ErrTableExists, got %v", err)
+	}
+}
+
+func Test_Get(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithSoftDelete())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := Signature{1, 2, 3, 4}
+	if err := lsh.Insert(1, sig); err != nil {
+		t.Fatal(err)
+	}
+	got, err := lsh.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameSig(got, sig) {
+		t.Errorf("Expected %v, got %v", sig, got)
+	}
+	if err := lsh.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lsh.Get(1); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound after Delete, got %v", err)
+	}
+}
+
+func Test_SignatureEncoding(t *testing.T) {
+	sig := Signature{0, 1, 1 << 40, ^uint(0)}
+	if got := decodeSignature(encodeSignature(sig)); !sameSig(got, sig) {
+		t.Errorf("Expected %v, got %v", sig, got)
+	}
+}
+
+func containsID(ids []int, id int) bool {
+	for _, x := range ids {
+		if x == id {
+			return true
+		}
+	}
+	return false
+}
+
+func sameSig(a, b Signature) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func Test_BandKeys(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	hashed := 0
+	lsh, err := NewSqliteLsh(4, 2, "lshtable", db, WithBandKeys(),
+		WithKeyHash(func(b []byte) uint64 {
+			hashed++
+			return uint64(b[7]) + uint64(b[15])<<8
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(lsh.Statements().CreateTable, "\n"); n != 4 {
+		t.Errorf("Expected the id and 2 key columns, got %s", lsh.Statements().CreateTable)
+	}
+	sig := Signature{1, 2, 3, 4, 5, 6, 7, 8}
+	if err := lsh.Insert(1, sig); err != nil {
+		t.Fatal(err)
+	}
+	if hashed != 2 {
+		t.Errorf("Expected the key hash to be used for 2 hash keys, got %d", hashed)
+	}
+	// Only the first two values of each hash key are hashed
+	ids, err := lsh.QueryIDs(Signature{1, 2, 0, 0, 0, 0, 0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("Expected [1], got %v", ids)
+	}
+	if _, err := lsh.Get(1); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+	out := make(chan Entry, 1)
+	if err := lsh.Scan(out); err != nil {
+		t.Fatal(err)
+	}
+	if e := <-out; e.Id != 1 || e.Signature != nil {
+		t.Errorf("Expected the entry of 1 without Signature, got %v", e)
+	}
+}
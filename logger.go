@@ -0,0 +1,62 @@
+package sqllsh
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Event describes an operation run by an index.
+type Event struct {
+	Op       string        // "insert", "batch insert", "query", "index", "reindex", "delete" or "compact"
+	Rows     int64         // Number of rows written, found or purged, or indexes built
+	Duration time.Duration // Time the operation took
+	Err      error         // Error returned by the operation
+	Retried  bool          // Whether the failed operation is retried, see WithRetry
+}
+
+// Logger records the operations run by an index.
+// Log is called from the goroutine running the operation, so it should
+// return quickly.
+type Logger interface {
+	Log(e Event)
+}
+
+// LoggerFunc is an adapter to use a function as a Logger.
+type LoggerFunc func(e Event)
+
+// Log calls f(e).
+func (f LoggerFunc) Log(e Event) {
+	f(e)
+}
+
+// WithLogger records the operations of the index with l.
+func WithLogger(l Logger) Option {
+	return func(lsh *SqlLsh) {
+		lsh.logger = l
+	}
+}
+
+// SlogLogger returns a Logger writing the events to l, at the error
+// level for failed operations and the debug level otherwise.
+func SlogLogger(l *slog.Logger) Logger {
+	return LoggerFunc(func(e Event) {
+		if e.Err != nil {
+			l.Error("sqllsh "+e.Op, "rows", e.Rows, "duration", e.Duration, "error", e.Err)
+			return
+		}
+		l.Debug("sqllsh "+e.Op, "rows", e.Rows, "duration", e.Duration)
+	})
+}
+
+// log records an operation with the logger, if there is one.
+func (lsh *SqlLsh) log(op string, rows int64, start time.Time, err error) {
+	if lsh.logger == nil {
+		return
+	}
+	lsh.logger.Log(Event{
+		Op:       op,
+		Rows:     rows,
+		Duration: time.Since(start),
+		Err:      err,
+	})
+}
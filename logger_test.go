@@ -0,0 +1,47 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_Logger(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	var events []Event
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db, WithLogger(LoggerFunc(func(e Event) {
+		events = append(events, e)
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := Signature{1, 2, 3, 4, 5, 6}
+	lsh.Insert(0, sig)
+	lsh.BatchInsert([]int{1, 2}, []Signature{sig, sig})
+	lsh.Index()
+	lsh.QueryIDs(sig)
+	lsh.Insert(0, sig)
+	var ops []string
+	var rows []int64
+	for _, e := range events {
+		ops = append(ops, e.Op)
+		rows = append(rows, e.Rows)
+	}
+	expectedOps := []string{"insert", "batch insert", "index", "query", "insert"}
+	if !reflect.DeepEqual(ops, expectedOps) {
+		t.Errorf("Expected %v, got %v", expectedOps, ops)
+	}
+	expectedRows := []int64{1, 2, 3, 3, 1}
+	if !reflect.DeepEqual(rows, expectedRows) {
+		t.Errorf("Expected %v, got %v", expectedRows, rows)
+	}
+	if !errors.Is(events[4].Err, ErrIDExists) {
+		t.Errorf("Expected ErrIDExists, got %v", events[4].Err)
+	}
+	removeTempFile(t, f)
+}
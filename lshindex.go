@@ -0,0 +1,22 @@
+package sqllsh
+
+// LshIndex is the interface of an LSH index, implemented by SqlLsh, so
+// that code using an index can be given another implementation, such
+// as MapLsh for tests and small datasets.
+type LshIndex interface {
+	// Insert adds a Signature with id.
+	Insert(id int, sig Signature) error
+	// BatchInsert adds the Signatures with the IDs at the same
+	// positions.
+	BatchInsert(ids []int, sigs []Signature) error
+	// Query writes the IDs of the Signatures with at least one hash key
+	// collision with sig to out, without closing it.
+	Query(sig Signature, out chan int) error
+	// Delete removes the Signature with id.
+	Delete(id int) error
+	// Index prepares the index for queries once the Signatures are
+	// inserted.
+	Index() error
+}
+
+var _ LshIndex = (*SqlLsh)(nil)
@@ -0,0 +1,129 @@
+package sqllsh
+
+import (
+	"sort"
+	"strings"
+)
+
+// Manager manages the LSH indexes of one database, such as one index
+// per collection, by the name of their tables.
+type Manager struct {
+	db      DB
+	dialect dialect
+	opts    []Option
+}
+
+// NewManager creates a Manager of the indexes in the database of db,
+// picking the dialect from the driver of db as NewAutoLsh does.
+// The options are used by every index created or opened by the
+// Manager, before the options given to Create and Open.
+// The caller is responsible for closing the database connection
+// object.
+func NewManager(db DB, opts ...Option) (*Manager, error) {
+	d, err := detectDialect(db, &opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{db: db, dialect: d, opts: opts}, nil
+}
+
+// List returns the sorted names of the index tables in the database,
+// found by their metadata tables.
+// Tables created by older versions of the package have no metadata
+// until they are opened once with a constructor, so they are not
+// listed.
+// It returns ErrUnsupported if the tables of the database cannot be
+// listed, as with the standard SQL dialect of NewAnsiLsh.
+func (m *Manager) List() ([]string, error) {
+	if m.dialect.tablesQuery == "" {
+		return nil, ErrUnsupported
+	}
+	db := dbConn{DB: m.db}
+	rows, err := db.Query(m.dialect.tablesQuery)
+	if err != nil {
+		return nil, wrapErr("list", err)
+	}
+	tables := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, wrapErr("list", err)
+		}
+		tables[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, wrapErr("list", err)
+	}
+	var names []string
+	for table := range tables {
+		name := strings.TrimSuffix(table, "_meta")
+		if name == table || !tables[name] {
+			continue
+		}
+		// Another table may end with _meta
+		if _, err := readMeta(db, table); err != nil {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Create creates a new index in the table name, or opens it if it
+// exists with the same k and l, as the constructors do.
+func (m *Manager) Create(name string, k, l int, opts ...Option) (*SqlLsh, error) {
+	return newSqlLsh(k, l, name, m.db, m.dialect, m.options(opts))
+}
+
+// Open opens the existing index in the table name, with the parameters
+// recorded in its metadata table.
+// It returns ErrNotFound if there is no such index.
+func (m *Manager) Open(name string, opts ...Option) (*SqlLsh, error) {
+	return openSqlLsh(name, m.db, m.dialect, m.options(opts))
+}
+
+// Drop drops the index in the table name: its table, with its indexes
+// and partitions, its metadata table, and the tables of BulkLoad and
+// TypedLsh if they exist.
+// The SqlLsh using the table, which must be closed, cannot be used
+// afterwards.
+// Spanner is not supported, as its tables cannot be dropped before
+// their indexes.
+func (m *Manager) Drop(name string) error {
+	if m.dialect.ddl != nil {
+		return ErrUnsupported
+	}
+	lsh := &SqlLsh{tableName: name, db: dbConn{DB: m.db}, dialect: m.dialect}
+	tables := []string{lsh.checkpointTable(), lsh.tableName + "_keys", lsh.tableName,
+		lsh.metaTable()}
+	var stmts []string
+	for _, table := range tables {
+		if !m.dialect.probeTables {
+			stmts = append(stmts, "DROP TABLE IF EXISTS "+table)
+		} else if lsh.tableExists(table) {
+			stmts = append(stmts, "DROP TABLE "+table)
+		}
+	}
+	tx, err := lsh.db.Begin()
+	if err != nil {
+		return wrapErr("drop", err)
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			tx.Rollback()
+			return wrapErr("drop", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return wrapErr("drop", err)
+	}
+	return nil
+}
+
+func (m *Manager) options(opts []Option) []Option {
+	return append(append([]Option(nil), m.opts...), opts...)
+}
@@ -0,0 +1,68 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_Manager(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	m, err := NewManager(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Not an index, but ends with _meta
+	if _, err := db.Exec("CREATE TABLE other (id INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE TABLE other_meta (id INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"songs", "images"} {
+		lsh, err := m.Create(name, 2, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := lsh.Insert(1, Signature{1, 2, 3, 4}); err != nil {
+			t.Fatal(err)
+		}
+		if err := lsh.Index(); err != nil {
+			t.Fatal(err)
+		}
+		lsh.Close()
+	}
+	names, err := m.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "images" || names[1] != "songs" {
+		t.Errorf("Expected [images songs], got %v", names)
+	}
+	lsh, err := m.Open("songs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lsh.k != 2 || lsh.l != 2 {
+		t.Errorf("Expected k = 2 and l = 2, got %d and %d", lsh.k, lsh.l)
+	}
+	ids, err := lsh.QueryIDs(Signature{1, 2, 3, 4})
+	if err != nil || len(ids) != 1 {
+		t.Errorf("Expected [1], got %v, %v", ids, err)
+	}
+	lsh.Close()
+	if err := m.Drop("songs"); err != nil {
+		t.Fatal(err)
+	}
+	if names, err = m.List(); err != nil || len(names) != 1 || names[0] != "images" {
+		t.Errorf("Expected [images], got %v, %v", names, err)
+	}
+	if _, err := m.Open("songs"); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package sqllsh
+
+import (
+	"sort"
+	"sync"
+)
+
+// MapLsh is an LSH index held in Go maps, one per hash table.
+// It is a reference implementation of LshIndex, useful as a test
+// oracle for the candidates found by SqlLsh, and as a lightweight index
+// for small datasets that do not need to be persisted.
+// It is safe for concurrent use.
+type MapLsh struct {
+	k, l   int
+	mu     sync.RWMutex
+	sigs   map[int]Signature
+	tables map[string]map[int]bool // IDs by hash key, from bandKeys
+}
+
+var _ LshIndex = (*MapLsh)(nil)
+
+// NewMapLsh creates an empty in-memory index with hash key size k and
+// l hash tables.
+func NewMapLsh(k, l int) *MapLsh {
+	return &MapLsh{
+		k:      k,
+		l:      l,
+		sigs:   make(map[int]Signature),
+		tables: make(map[string]map[int]bool),
+	}
+}
+
+// Insert adds sig with id, returning ErrIDExists if id is already in
+// the index.
+func (m *MapLsh) Insert(id int, sig Signature) error {
+	if len(sig) != m.k*m.l {
+		return ErrSignatureSizeMismatch
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sigs[id]; ok {
+		return ErrIDExists
+	}
+	m.add(id, sig)
+	return nil
+}
+
+// BatchInsert adds the Signatures with the IDs at the same positions.
+// Nothing is inserted if one of the IDs is already in the index or
+// repeated in ids.
+func (m *MapLsh) BatchInsert(ids []int, sigs []Signature) error {
+	if err := validateBatch(ids, sigs, m.k*m.l); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if _, ok := m.sigs[id]; ok || seen[id] {
+			return ErrIDExists
+		}
+		seen[id] = true
+	}
+	for i, id := range ids {
+		m.add(id, sigs[i])
+	}
+	return nil
+}
+
+func (m *MapLsh) add(id int, sig Signature) {
+	m.sigs[id] = append(Signature(nil), sig...)
+	for _, key := range bandKeys(sig, m.k) {
+		if m.tables[key] == nil {
+			m.tables[key] = make(map[int]bool)
+		}
+		m.tables[key][id] = true
+	}
+}
+
+// Query writes the IDs of the Signatures with at least one hash key
+// collision with sig to out, in ascending order.
+// The caller is responsible for closing the channel.
+func (m *MapLsh) Query(sig Signature, out chan int) error {
+	ids, err := m.QueryIDs(sig)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		out <- id
+	}
+	return nil
+}
+
+// QueryIDs is like Query, but returns the IDs in a slice.
+func (m *MapLsh) QueryIDs(sig Signature) ([]int, error) {
+	if len(sig) != m.k*m.l {
+		return nil, ErrSignatureSizeMismatch
+	}
+	m.mu.RLock()
+	found := make(map[int]bool)
+	for _, key := range bandKeys(sig, m.k) {
+		for id := range m.tables[key] {
+			found[id] = true
+		}
+	}
+	m.mu.RUnlock()
+	ids := make([]int, 0, len(found))
+	for id := range found {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// Delete removes the Signature with id.
+// Deleting an id that does not exist is not an error.
+func (m *MapLsh) Delete(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sig, ok := m.sigs[id]
+	if !ok {
+		return nil
+	}
+	for _, key := range bandKeys(sig, m.k) {
+		delete(m.tables[key], id)
+		if len(m.tables[key]) == 0 {
+			delete(m.tables, key)
+		}
+	}
+	delete(m.sigs, id)
+	return nil
+}
+
+// Index does nothing, as the maps are always ready for queries.
+func (m *MapLsh) Index() error {
+	return nil
+}
@@ -0,0 +1,91 @@
+package sqllsh
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func Test_MapLsh(t *testing.T) {
+	m := NewMapLsh(2, 2)
+	if err := m.Insert(1, Signature{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Insert(1, Signature{1, 2, 3, 4}); err != ErrIDExists {
+		t.Errorf("Expected ErrIDExists, got %v", err)
+	}
+	if err := m.BatchInsert([]int{2, 1}, []Signature{{5, 6, 3, 4}, {1, 2, 3, 4}}); err != ErrIDExists {
+		t.Errorf("Expected ErrIDExists, got %v", err)
+	}
+	if err := m.BatchInsert([]int{2, 3}, []Signature{{5, 6, 3, 4}, {7, 8, 9, 9}}); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := m.QueryIDs(Signature{1, 2, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("Expected [1 2], got %v", ids)
+	}
+	if err := m.Delete(2); err != nil {
+		t.Fatal(err)
+	}
+	ids, err = m.QueryIDs(Signature{0, 0, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("Expected [1], got %v", ids)
+	}
+	if _, err := m.QueryIDs(Signature{1}); err != ErrSignatureSizeMismatch {
+		t.Errorf("Expected ErrSignatureSizeMismatch, got %v", err)
+	}
+}
+
+// Test_MapLshOracle checks the candidates of SqlLsh against MapLsh.
+func Test_MapLshOracle(t *testing.T) {
+	lsh, err := NewMemoryLsh(2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lsh.Close()
+	m := NewMapLsh(2, 4)
+	// Few distinct hash values, so that there are many collisions
+	random := rand.New(rand.NewSource(1))
+	sigs := make([]Signature, 500)
+	ids := make([]int, len(sigs))
+	for i := range sigs {
+		ids[i] = i
+		sigs[i] = make(Signature, 8)
+		for j := range sigs[i] {
+			sigs[i][j] = uint(random.Intn(4))
+		}
+	}
+	for _, idx := range []LshIndex{lsh, m} {
+		if err := idx.BatchInsert(ids, sigs); err != nil {
+			t.Fatal(err)
+		}
+		if err := idx.Index(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, sig := range sigs[:50] {
+		got, err := lsh.QueryIDs(sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Ints(got)
+		want, err := m.QueryIDs(sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("Expected %d candidates, got %d", len(want), len(got))
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("Expected %v, got %v", want, got)
+			}
+		}
+	}
+}
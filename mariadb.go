@@ -0,0 +1,63 @@
+package sqllsh
+
+import "database/sql"
+
+// mariadbDialect is the MySQL dialect with the extensions of MariaDB,
+// which creates and drops indexes idempotently, returns generated IDs,
+// and resolves conflicts with INSERT IGNORE and REPLACE, as ON
+// DUPLICATE KEY UPDATE with VALUES is deprecated.
+var mariadbDialect = dialect{
+	varFmt:         mysqlDialect.varFmt,
+	intType:        mysqlDialect.intType,
+	blobType:       mysqlDialect.blobType,
+	limitFmt:       mysqlDialect.limitFmt,
+	createIndexFmt: "CREATE INDEX IF NOT EXISTS %s ON %s",
+	dropIndexFmt:   "DROP INDEX IF EXISTS %[1]s ON %[2]s",
+	indexMethods:   mysqlDialect.indexMethods,
+	reindexFmt:     mysqlDialect.reindexFmt,
+	analyzeFmt:     mysqlDialect.analyzeFmt,
+	explainPrefix:  "EXPLAIN ",
+	autoIDType:     mysqlDialect.autoIDType,
+	returning:      true,
+	plan:           PlanUnion,
+	limitDelete:    true,
+	insertVerb:     mariadbInsertVerb,
+	indexesQuery:   mysqlIndexesQuery,
+	tablesQuery:    mysqlTablesQuery,
+	maxParams:      65535,
+	maxIdent:       64,
+	serializable:   true,
+	snapshot:       sql.LevelRepeatableRead,
+	implicitCommit: true,
+}
+
+// NewMariadbLsh creates a new MariaDB-backed LSH index, which uses the
+// MySQL protocol.
+// It requires MariaDB 10.5 or later.
+// With ConflictIgnore, INSERT IGNORE also turns other errors of the
+// insert into warnings, such as values out of range.
+// The DDL statements are run like on MySQL, see NewMysqlLsh.
+// The caller is responsible for closing the database connection
+// object.
+func NewMariadbLsh(k, l int, tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	lsh, err := newSqlLsh(k, l, tableName, db, mariadbDialect, opts)
+	return lsh, err
+}
+
+// OpenMariadbLsh opens an existing MariaDB-backed LSH index with its
+// recorded parameters, like OpenSqliteLsh.
+func OpenMariadbLsh(tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	return openSqlLsh(tableName, db, mariadbDialect, opts)
+}
+
+// mariadbInsertVerb returns the start of an insert with the conflict
+// behavior c.
+func mariadbInsertVerb(c Conflict) string {
+	switch c {
+	case ConflictIgnore:
+		return "INSERT IGNORE INTO"
+	case ConflictReplace:
+		return "REPLACE INTO"
+	}
+	return "INSERT INTO"
+}
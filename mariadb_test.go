@@ -0,0 +1,21 @@
+package sqllsh
+
+import "testing"
+
+func Test_MariadbStatements(t *testing.T) {
+	lsh := &SqlLsh{k: 2, l: 2, tableName: "lshtable", dialect: mariadbDialect}
+	for c, expected := range map[Conflict]string{
+		ConflictError:   "INSERT INTO lshtable (id,hv_0,hv_1,hv_2,hv_3) VALUES(?,?,?,?,?)",
+		ConflictIgnore:  "INSERT IGNORE INTO lshtable (id,hv_0,hv_1,hv_2,hv_3) VALUES(?,?,?,?,?)",
+		ConflictReplace: "REPLACE INTO lshtable (id,hv_0,hv_1,hv_2,hv_3) VALUES(?,?,?,?,?)",
+	} {
+		lsh.conflict = c
+		if s := lsh.insertStr(); s != expected {
+			t.Errorf("Expected %q, got %q", expected, s)
+		}
+	}
+	expected := "CREATE INDEX IF NOT EXISTS lshtable_ht_1 ON lshtable (hv_2,hv_3)"
+	if s := lsh.indexStr(1); s != expected {
+		t.Errorf("Expected %q, got %q", expected, s)
+	}
+}
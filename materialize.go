@@ -0,0 +1,103 @@
+package sqllsh
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// QueryToTable writes the distinct IDs of the Signatures colliding with
+// sig into a new table with a single id column, instead of sending them
+// to the application, and returns the name of the table and the number
+// of IDs written, for workflows joining or aggregating the candidates
+// in SQL.
+// The table is named <table>_cand_ followed by random hexadecimal
+// digits, in the schema of the index and quoted like its table, and is
+// created with the given kind, which must be supported by the
+// database: a TableTemporary table is only visible to the connection
+// that created it, and dropped at the end of its session, so it needs
+// an index on a *sql.Conn or a *sql.Tx, and ErrUnsupported is returned
+// on a *sql.DB; the tables of the other kinds must be dropped by the
+// caller.
+// The query cache and WithQueryTimeout are not used.
+// Spanner is not supported.
+func (lsh *SqlLsh) QueryToTable(sig Signature, kind TableKind) (string, int64, error) {
+	sig, err := lsh.beforeQuery("query to table", sig)
+	if err != nil {
+		return "", 0, err
+	}
+	if len(sig) != lsh.k*lsh.l {
+		return "", 0, ErrSignatureSizeMismatch
+	}
+	if _, ok := lsh.dialect.tableKinds[kind]; (kind != TablePermanent && !ok) ||
+		lsh.dialect.ddl != nil {
+		return "", 0, ErrUnsupported
+	}
+	if _, ok := lsh.db.DB.(*sql.DB); ok && kind == TableTemporary {
+		// The table would be on a connection of the pool the caller
+		// cannot choose
+		return "", 0, ErrUnsupported
+	}
+	if err := lsh.ready(); err != nil {
+		return "", 0, err
+	}
+	name, err := lsh.candidateTable(kind)
+	if err != nil {
+		return "", 0, wrapErr("query to table", err)
+	}
+	tx, err := lsh.db.Begin()
+	if err != nil {
+		return "", 0, wrapErr("query to table", err)
+	}
+	_, err = tx.Exec(fmt.Sprintf("CREATE %sTABLE %s (id %s PRIMARY KEY)",
+		lsh.dialect.tableKinds[kind], name, lsh.dialect.intType))
+	if err != nil {
+		tx.Rollback()
+		return "", 0, wrapErr("query to table", err)
+	}
+	var n int64
+	bands := lsh.maybeBands(sig)
+	if bands == nil {
+		bands = lsh.allBands()
+	}
+	if len(bands) > 0 {
+		res, err := tx.Exec(fmt.Sprintf("INSERT INTO %s (id) %s", name,
+			lsh.bandsQueryStr(bands, lsh.k)), lsh.queryArgs(sig, bands, lsh.k)...)
+		if err != nil {
+			tx.Rollback()
+			return "", 0, wrapErr("query to table", err)
+		}
+		if n, err = res.RowsAffected(); err != nil {
+			tx.Rollback()
+			return "", 0, wrapErr("query to table", err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return "", 0, wrapErr("query to table", err)
+	}
+	return name, n, nil
+}
+
+// candidateTable returns a new name for a table of QueryToTable.
+// Temporary tables cannot be created in the schema of the index on
+// PostgreSQL, so their names have no schema.
+func (lsh *SqlLsh) candidateTable(kind TableKind) (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	i := strings.LastIndex(lsh.tableName, ".") + 1
+	schema, table := lsh.tableName[:i], lsh.tableName[i:]
+	name := lsh.baseName() + "_cand_" + hex.EncodeToString(suffix)
+	if len(table) > 1 && strings.ContainsAny(table[:1], "\"`[") {
+		name = table[:1] + name + table[len(table)-1:]
+	}
+	if kind == TableTemporary {
+		return name, nil
+	}
+	return schema + name, nil
+}
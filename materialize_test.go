@@ -0,0 +1,65 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func Test_QueryToTable(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.BatchInsert([]int{0, 1, 2}, []Signature{{1, 2, 3, 4}, {1, 2, 5, 6}, {7, 8, 9, 10}}); err != nil {
+		t.Fatal(err)
+	}
+	name, n, err := lsh.QueryToTable(Signature{1, 2, 5, 6}, TablePermanent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(name, "lshtable_cand_") || n != 2 {
+		t.Errorf("Expected 2 IDs in a lshtable_cand_ table, got %d in %s", n, name)
+	}
+	var sum int
+	if err := db.QueryRow("SELECT SUM(id) FROM " + name).Scan(&sum); err != nil || sum != 1 {
+		t.Errorf("Expected the IDs 0 and 1, got sum %d, %v", sum, err)
+	}
+	other, _, err := lsh.QueryToTable(Signature{1, 2, 5, 6}, TablePermanent)
+	if err != nil || other == name {
+		t.Errorf("Expected a new table, got %s, %v", other, err)
+	}
+	if _, _, err := lsh.QueryToTable(Signature{1, 2, 5, 6}, TableTemporary); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
+
+func Test_QueryToTableName(t *testing.T) {
+	rec := NewRecorder()
+	defer rec.Close()
+	lsh, err := NewPostgresLsh(2, 2, `"s"."t"`, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, _, err := lsh.QueryToTable(Signature{1, 2, 3, 4}, TablePermanent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(name, `"s"."t_cand_`) || !strings.HasSuffix(name, `"`) {
+		t.Errorf("Expected a quoted name in the schema s, got %s", name)
+	}
+	name, _, err = lsh.QueryToTable(Signature{1, 2, 3, 4}, TableTemporary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(name, `"t_cand_`) || !strings.HasSuffix(name, `"`) {
+		t.Errorf("Expected a quoted name without schema, got %s", name)
+	}
+}
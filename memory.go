@@ -0,0 +1,69 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrNoSqliteDriver is returned by NewMemoryLsh when no Sqlite driver
+// is registered.
+var ErrNoSqliteDriver = errors.New("No Sqlite driver registered")
+
+// memoryDBs counts the in-memory databases opened, to give each one a
+// distinct name.
+var memoryDBs uint32
+
+// sqliteDrivers are the names of the Sqlite drivers NewMemoryLsh can
+// use, registered by github.com/mattn/go-sqlite3 and modernc.org/sqlite.
+var sqliteDrivers = []string{"sqlite3", "sqlite"}
+
+// NewMemoryLsh creates a new LSH index in a private in-memory Sqlite
+// database, for tests and small datasets.
+// It uses the first registered driver of github.com/mattn/go-sqlite3
+// and modernc.org/sqlite, so one of them must be imported, otherwise
+// ErrNoSqliteDriver is returned.
+// The database is shared by the connections of the pool, and is
+// discarded by Close.
+func NewMemoryLsh(k, l int, opts ...Option) (*SqlLsh, error) {
+	driver := ""
+	for _, name := range sqliteDrivers {
+		for _, registered := range sql.Drivers() {
+			if driver == "" && name == registered {
+				driver = name
+			}
+		}
+	}
+	if driver == "" {
+		return nil, ErrNoSqliteDriver
+	}
+	n := atomic.AddUint32(&memoryDBs, 1)
+	db, err := sql.Open(driver, fmt.Sprintf("file:sqllsh_memory_%d?mode=memory&cache=shared", n))
+	if err != nil {
+		return nil, wrapErr("open", err)
+	}
+	// The database is discarded when its last connection is closed, so
+	// the connections are never closed for their age or idle time, which
+	// is what 0 means
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+	lsh, err := newSqlLsh(k, l, "lshtable", db, sqliteDialect, opts)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	lsh.ownDB = true
+	return lsh, nil
+}
+
+// Close releases the prepared statements of the index.
+// The database connection object is closed only if it was opened by
+// the index, as by NewMemoryLsh.
+func (lsh *SqlLsh) Close() error {
+	lsh.closeStmts()
+	if lsh.ownDB {
+		return lsh.db.Close()
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package sqllsh
+
+import "testing"
+
+func Test_NewMemoryLsh(t *testing.T) {
+	a, err := NewMemoryLsh(2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewMemoryLsh(2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(100, 6)
+	for i, sig := range sigs {
+		if err := a.Insert(i, sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.Index(); err != nil {
+		t.Fatal(err)
+	}
+	found, err := a.QueryIDs(sigs[7])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0] != 7 {
+		t.Errorf("Expected [7], got %v", found)
+	}
+	// Each index has its own database
+	found, err = b.QueryIDs(sigs[7])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 0 {
+		t.Errorf("Expected no IDs, got %v", found)
+	}
+	if err := a.Close(); err != nil {
+		t.Error(err)
+	}
+	if err := b.Close(); err != nil {
+		t.Error(err)
+	}
+}
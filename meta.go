@@ -0,0 +1,385 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// schemaVersion is the version of the table layout written by this
+// package, recorded in the metadata table.
+const schemaVersion = 3
+
+// legacyIndexPrefix is the prefix of the index names of the tables
+// created before the prefix was recorded in the metadata table.
+const legacyIndexPrefix = "ht_"
+
+// Layouts of the table recorded in the metadata table.
+const (
+	layoutColumns  = iota // One column per hash value
+	layoutCompact         // WithCompactLayout
+	layoutBandKeys        // WithBandKeys
+)
+
+// Types of the id column recorded in the metadata table.
+const (
+	idGiven = iota // IDs given by the application
+	idAuto         // WithAutoID
+)
+
+// settingCols are the columns of the metadata table recording the
+// options changing the columns of the table, added in version 3.
+var settingCols = []string{"id_type", "soft_delete", "insert_time", "namespaces"}
+
+// migration upgrades a table from one schema version to the next,
+// inside tx.
+type migration func(lsh *SqlLsh, tx *sql.Tx) error
+
+// migrations holds the migration from each schema version to the next,
+// starting from version 0, the tables created before the metadata
+// table existed.
+// A layout change adds its migration here and increments
+// schemaVersion, so that existing tables are upgraded in place the next
+// time they are opened.
+var migrations = []migration{
+	// 0 to 1: only the metadata table is added
+	func(lsh *SqlLsh, tx *sql.Tx) error {
+		return nil
+	},
+	// 1 to 2: the prefix of the index names is recorded, and the
+	// indexes of the existing tables keep their names
+	func(lsh *SqlLsh, tx *sql.Tx) error {
+		if err := lsh.addMetaColumns(tx, []string{"index_prefix"}, lsh.stringType()); err != nil {
+			return err
+		}
+		_, err := tx.Exec(fmt.Sprintf("UPDATE %s SET index_prefix = %s WHERE index_prefix IS NULL",
+			lsh.metaTable(), lsh.dialect.varFmt(0)), legacyIndexPrefix)
+		return err
+	},
+	// 2 to 3: the options changing the columns are recorded, as those of
+	// the index opening the table, which has been using them
+	func(lsh *SqlLsh, tx *sql.Tx) error {
+		if err := lsh.addMetaColumns(tx, settingCols, lsh.dialect.intType); err != nil {
+			return err
+		}
+		sets := make([]string, len(settingCols))
+		for i, col := range settingCols {
+			sets[i] = col + " = " + lsh.dialect.varFmt(i)
+		}
+		_, err := tx.Exec(fmt.Sprintf("UPDATE %s SET %s WHERE id = 0",
+			lsh.metaTable(), strings.Join(sets, ", ")), lsh.meta().settings()...)
+		return err
+	},
+}
+
+// addMetaColumns adds the nullable columns cols of type typ to the
+// metadata table, unless they exist.
+func (lsh *SqlLsh) addMetaColumns(tx *sql.Tx, cols []string, typ string) error {
+	rows, err := tx.Query(fmt.Sprintf("SELECT * FROM %s WHERE 1 = 0", lsh.metaTable()))
+	if err != nil {
+		return err
+	}
+	existing, err := rows.Columns()
+	rows.Close()
+	if err != nil {
+		return err
+	}
+	found := make(map[string]bool)
+	for _, col := range existing {
+		found[strings.ToLower(col)] = true
+	}
+	var stmts []string
+	for _, col := range cols {
+		if found[col] {
+			continue
+		}
+		if lsh.dialect.ddl != nil {
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", lsh.metaTable(), col, typ))
+		} else {
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ADD %s %s", lsh.metaTable(), col, typ))
+		}
+	}
+	if len(stmts) == 0 {
+		return nil
+	}
+	if lsh.dialect.ddl != nil {
+		return lsh.dialect.ddl(lsh.db.DB, stmts)
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stringType returns the type of the string columns of the metadata
+// table.
+func (lsh *SqlLsh) stringType() string {
+	if lsh.dialect.createTable != nil {
+		// Spanner
+		return "STRING(255)"
+	}
+	return "VARCHAR(255)"
+}
+
+// Meta is the metadata of an index table, recorded in the table
+// <tableName>_meta when the table is created.
+type Meta struct {
+	Version int // Schema version of the table
+	K       int // Hash key size
+	L       int // Number of hash tables
+	Layout  int // Layout of the hash values
+	Bits    int // Bits stored of each hash value with WithBBit, 0 for all
+	// Prefix of the names of the indexes built by Index, see
+	// WithIndexPrefix
+	IndexPrefix string
+	IDType      int  // Type of the id column, given or generated by WithAutoID
+	SoftDelete  bool // Whether the table has the column of WithSoftDelete
+	InsertTime  bool // Whether the table has the column of WithInsertTime
+	Namespaces  bool // Whether the table has the column of WithNamespaces
+}
+
+// settings returns the values of settingCols.
+func (m Meta) settings() []interface{} {
+	values := []interface{}{m.IDType, 0, 0, 0}
+	for i, set := range []bool{m.SoftDelete, m.InsertTime, m.Namespaces} {
+		if set {
+			values[i+1] = 1
+		}
+	}
+	return values
+}
+
+// metaTable returns the name of the metadata table.
+func (lsh *SqlLsh) metaTable() string {
+	return lsh.tableName + "_meta"
+}
+
+// metaTableStr returns the statement creating the metadata table,
+// which has a single row with id 0.
+func (lsh *SqlLsh) metaTableStr() string {
+	cols := []string{"id", "version", "k", "l", "layout", "bits"}
+	for i, col := range cols {
+		cols[i] = col + " " + lsh.dialect.intType + " NOT NULL"
+	}
+	// The columns added by migrations are nullable
+	cols = append(cols, "index_prefix "+lsh.stringType())
+	for _, col := range settingCols {
+		cols = append(cols, col+" "+lsh.dialect.intType)
+	}
+	if lsh.dialect.createTable != nil {
+		// Spanner puts the primary key after the columns
+		return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n", lsh.metaTable()) +
+			strings.Join(cols, ",\n") + "\n) PRIMARY KEY (id)"
+	}
+	cols = append(cols, "PRIMARY KEY (id)")
+	return fmt.Sprintf("%s %s (\n", lsh.createTablePrefix(), lsh.metaTable()) +
+		strings.Join(cols, ",\n") + "\n)"
+}
+
+// meta returns the metadata expected for the options of the index.
+func (lsh *SqlLsh) meta() Meta {
+	m := Meta{Version: schemaVersion, K: lsh.k, L: lsh.l, Bits: lsh.bits,
+		IndexPrefix: lsh.indexPrefix, SoftDelete: lsh.softDelete,
+		InsertTime: lsh.insertTime, Namespaces: lsh.namespaces}
+	if lsh.autoID {
+		m.IDType = idAuto
+	}
+	if m.IndexPrefix == "" {
+		m.IndexPrefix = lsh.defaultIndexPrefix()
+	}
+	if lsh.keysOnly {
+		m.Layout = layoutBandKeys
+	} else if lsh.packed {
+		m.Layout = layoutCompact
+	}
+	return m
+}
+
+// ReadMeta returns the metadata of the table of the index.
+func (lsh *SqlLsh) ReadMeta() (Meta, error) {
+	m, err := readMeta(lsh.db, lsh.metaTable())
+	if err == sql.ErrNoRows {
+		return Meta{}, ErrNotFound
+	}
+	if err != nil {
+		return Meta{}, wrapErr("read meta", err)
+	}
+	return m, nil
+}
+
+// readMeta reads the metadata in table.
+func readMeta(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, table string) (Meta, error) {
+	var m Meta
+	err := q.QueryRow(fmt.Sprintf("SELECT version, k, l, layout, bits FROM %s WHERE id = 0",
+		table)).Scan(&m.Version, &m.K, &m.L, &m.Layout, &m.Bits)
+	if err != nil || m.Version < 2 {
+		// Older tables have no index_prefix column
+		return m, err
+	}
+	var prefix sql.NullString
+	if m.Version < 3 {
+		err = q.QueryRow(fmt.Sprintf("SELECT index_prefix FROM %s WHERE id = 0",
+			table)).Scan(&prefix)
+	} else {
+		err = q.QueryRow(fmt.Sprintf("SELECT index_prefix, %s FROM %s WHERE id = 0",
+			strings.Join(settingCols, ", "), table)).Scan(&prefix, &m.IDType,
+			&m.SoftDelete, &m.InsertTime, &m.Namespaces)
+	}
+	m.IndexPrefix = prefix.String
+	return m, err
+}
+
+// migrate checks the metadata of the table against the options of the
+// index, upgrading the table to the current schema version if it is
+// older, and records the metadata of new tables.
+// Without WithIndexPrefix, the index names then use the recorded
+// prefix.
+func (lsh *SqlLsh) migrate() error {
+	created := false
+	for {
+		m, err := lsh.migrateMeta(created)
+		if err != sql.ErrNoRows {
+			if err == nil && lsh.indexPrefix == "" {
+				lsh.indexPrefix = m.IndexPrefix
+			}
+			return err
+		}
+		if created {
+			return wrapErr("migrate", err)
+		}
+		if err := lsh.insertMeta(); err != nil {
+			return err
+		}
+		created = true
+	}
+}
+
+// migrateMeta checks and upgrades the metadata of the table in one
+// transaction, and returns it.
+// It returns sql.ErrNoRows if the table has no metadata yet, unless it
+// was just inserted in dry-run mode, where nothing can be read.
+func (lsh *SqlLsh) migrateMeta(created bool) (Meta, error) {
+	want := lsh.meta()
+	tx, err := lsh.db.Begin()
+	if err != nil {
+		return Meta{}, wrapErr("migrate", err)
+	}
+	m, err := readMeta(tx, lsh.metaTable())
+	if err == sql.ErrNoRows && created && lsh.dryRun {
+		m, err = want, nil
+	}
+	if err == nil && m.Version < 3 {
+		// Older tables recorded less, their options are those in use
+		m.IDType, m.SoftDelete, m.InsertTime, m.Namespaces =
+			want.IDType, want.SoftDelete, want.InsertTime, want.Namespaces
+		if m.Version < 2 {
+			m.IndexPrefix = legacyIndexPrefix
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return Meta{}, err
+		}
+		return Meta{}, wrapErr("migrate", err)
+	}
+	if m.K != want.K || m.L != want.L {
+		tx.Rollback()
+		return Meta{}, ErrTableExists
+	}
+	if m.Layout != want.Layout || m.Bits != want.Bits || m.Version > schemaVersion ||
+		m.IDType != want.IDType || m.SoftDelete != want.SoftDelete ||
+		m.InsertTime != want.InsertTime || m.Namespaces != want.Namespaces {
+		tx.Rollback()
+		return Meta{}, ErrSchemaMismatch
+	}
+	for v := m.Version; v < schemaVersion; v++ {
+		if err := migrations[v](lsh, tx.Tx); err != nil {
+			tx.Rollback()
+			return Meta{}, wrapErr("migrate", err)
+		}
+	}
+	if m.Version < schemaVersion {
+		_, err = tx.Exec(fmt.Sprintf("UPDATE %s SET version = %s WHERE id = 0",
+			lsh.metaTable(), lsh.dialect.varFmt(0)), schemaVersion)
+		if err != nil {
+			tx.Rollback()
+			return Meta{}, wrapErr("migrate", err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return Meta{}, wrapErr("migrate", err)
+	}
+	return m, nil
+}
+
+// insertMeta records the metadata of a new table, or of one created
+// before the metadata table, whose indexes have the names used then.
+// Processes creating the table at the same time all try to insert it,
+// so the insert ignores an existing row where the database allows it,
+// and a duplicate key otherwise: the row is read again afterwards.
+func (lsh *SqlLsh) insertMeta() error {
+	m := lsh.meta()
+	tx, err := lsh.db.Begin()
+	if err != nil {
+		return wrapErr("migrate", err)
+	}
+	if lsh.indexPrefix == "" && lsh.hasLegacyIndexes(tx) {
+		m.IndexPrefix = legacyIndexPrefix
+	}
+	cols := append([]string{"id", "version", "k", "l", "layout", "bits", "index_prefix"}, settingCols...)
+	vars := make([]string, len(cols))
+	for i := range vars {
+		vars[i] = lsh.dialect.varFmt(i)
+	}
+	verb := "INSERT INTO"
+	if lsh.dialect.insertVerb != nil {
+		verb = lsh.dialect.insertVerb(ConflictIgnore)
+	}
+	stmt := fmt.Sprintf("%s %s (%s) VALUES (%s)", verb, lsh.metaTable(),
+		strings.Join(cols, ", "), strings.Join(vars, ", "))
+	if lsh.dialect.conflictClause != nil {
+		stmt += lsh.dialect.conflictClause(ConflictIgnore, []string{"id"}, cols[1:])
+	}
+	args := append([]interface{}{0, m.Version, m.K, m.L, m.Layout, m.Bits, m.IndexPrefix},
+		m.settings()...)
+	if _, err := tx.Exec(stmt, args...); err != nil {
+		tx.Rollback()
+		if isDuplicateKey(err) {
+			return nil
+		}
+		return wrapErr("migrate", err)
+	}
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		if isDuplicateKey(err) {
+			return nil
+		}
+		return wrapErr("migrate", err)
+	}
+	return nil
+}
+
+// hasLegacyIndexes returns whether the table has the index of the first
+// hash table under its name before the prefix was recorded, looked up
+// with q. It returns false if the database cannot list the indexes.
+func (lsh *SqlLsh) hasLegacyIndexes(q rowQuerier) bool {
+	if lsh.dialect.indexesQuery == "" || lsh.dialect.createIndexFmt == "" {
+		return false
+	}
+	names, err := lsh.indexNamesIn(q)
+	return err == nil && names[legacyIndexPrefix+"0"]
+}
+
+// updateMetaStr returns the statement recording new k and l in the
+// metadata table.
+func (lsh *SqlLsh) updateMetaStr() string {
+	return fmt.Sprintf("UPDATE %s SET k = %s, l = %s WHERE id = 0",
+		lsh.metaTable(), lsh.dialect.varFmt(0), lsh.dialect.varFmt(1))
+}
@@ -0,0 +1,189 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_Meta(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 4, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := lsh.ReadMeta()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != (Meta{Version: schemaVersion, K: 2, L: 4, IndexPrefix: "lshtable_ht_"}) {
+		t.Errorf("Unexpected metadata %v", m)
+	}
+	// Same number of hash value columns, different hash keys
+	if _, err := NewSqliteLsh(4, 2, "lshtable", db); err != ErrTableExists {
+		t.Errorf("Expected ErrTableExists, got %v", err)
+	}
+	if err := lsh.Reshape(4, 2, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSqliteLsh(4, 2, "lshtable", db); err != nil {
+		t.Errorf("Expected the reshaped parameters to be recorded, got %v", err)
+	}
+	if _, err := db.Exec("UPDATE lshtable_meta SET bits = 8"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSqliteLsh(4, 2, "lshtable", db); err != ErrSchemaMismatch {
+		t.Errorf("Expected ErrSchemaMismatch, got %v", err)
+	}
+}
+
+func Test_MigrateWithoutMeta(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := NewSqliteLsh(2, 2, "lshtable", db); err != nil {
+		t.Fatal(err)
+	}
+	// A table created before the metadata table existed
+	if _, err := db.Exec("DROP TABLE lshtable_meta"); err != nil {
+		t.Fatal(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := lsh.ReadMeta()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Version != schemaVersion {
+		t.Errorf("Expected version %d, got %d", schemaVersion, m.Version)
+	}
+	if _, err := db.Exec("UPDATE lshtable_meta SET version = version + 1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSqliteLsh(2, 2, "lshtable", db); err != ErrSchemaMismatch {
+		t.Errorf("Expected ErrSchemaMismatch for a newer version, got %v", err)
+	}
+}
+
+func Test_MigrateIndexPrefix(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := NewSqliteLsh(2, 2, "lshtable", db); err != nil {
+		t.Fatal(err)
+	}
+	// A table of schema version 1, indexed under the names used then
+	for _, stmt := range []string{
+		"DROP TABLE lshtable_meta",
+		"CREATE TABLE lshtable_meta (id INTEGER, version INTEGER, k INTEGER, l INTEGER, layout INTEGER, bits INTEGER)",
+		"INSERT INTO lshtable_meta VALUES (0, 1, 2, 2, 0, 0)",
+		"CREATE INDEX ht_0 ON lshtable (hv_0, hv_1)",
+		"CREATE INDEX ht_1 ON lshtable (hv_2, hv_3)",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := lsh.ReadMeta()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Version != schemaVersion || m.IndexPrefix != "ht_" {
+		t.Errorf("Expected version %d with prefix ht_, got %+v", schemaVersion, m)
+	}
+	if err := lsh.Validate(); err != nil {
+		t.Errorf("Expected the existing indexes to be found, got %v", err)
+	}
+	if err := lsh.DropIndexes(); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := lsh.indexNames(); err != nil || len(found) != 0 {
+		t.Errorf("Expected the indexes to be dropped, got %v, %v", found, err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := lsh.indexNames(); err != nil || len(found) != 2 || !found["ht_1"] {
+		t.Errorf("Expected the indexes to be built under their names, got %v, %v", found, err)
+	}
+	// A table created before the metadata table, with its indexes
+	if _, err := db.Exec("DROP TABLE lshtable_meta"); err != nil {
+		t.Fatal(err)
+	}
+	if lsh, err = NewSqliteLsh(2, 2, "lshtable", db); err != nil {
+		t.Fatal(err)
+	}
+	if names := lsh.Names(); names.Indexes[0] != "ht_0" {
+		t.Errorf("Expected the index names to be kept, got %v", names.Indexes)
+	}
+}
+
+func Test_MetaSettings(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithSoftDelete(), WithInsertTime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := lsh.ReadMeta()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.SoftDelete || !m.InsertTime || m.Namespaces || m.IDType != idGiven {
+		t.Errorf("Unexpected settings in %+v", m)
+	}
+	if _, err := NewSqliteLsh(2, 2, "lshtable", db, WithSoftDelete()); err != ErrSchemaMismatch {
+		t.Errorf("Expected ErrSchemaMismatch without WithInsertTime, got %v", err)
+	}
+	opened, err := OpenSqliteLsh("lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opened.softDelete || !opened.insertTime {
+		t.Error("Expected the recorded options to be used")
+	}
+	// Another process inserted the metadata first
+	if err := lsh.insertMeta(); err != nil {
+		t.Errorf("Expected the existing metadata to be kept, got %v", err)
+	}
+	// A table of schema version 2 records the options opening it
+	for _, stmt := range []string{
+		"DROP TABLE lshtable_meta",
+		"CREATE TABLE lshtable_meta (id INTEGER, version INTEGER, k INTEGER, l INTEGER, layout INTEGER, bits INTEGER, index_prefix VARCHAR(255))",
+		"INSERT INTO lshtable_meta VALUES (0, 2, 2, 2, 0, 0, 'lshtable_ht_')",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if lsh, err = NewSqliteLsh(2, 2, "lshtable", db, WithSoftDelete(), WithInsertTime()); err != nil {
+		t.Fatal(err)
+	}
+	if m, err = lsh.ReadMeta(); err != nil || m.Version != schemaVersion || !m.SoftDelete || !m.InsertTime {
+		t.Errorf("Expected the options to be recorded, got %+v, %v", m, err)
+	}
+}
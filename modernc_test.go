@@ -0,0 +1,96 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"reflect"
+	"sort"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func Test_ModerncSqlite(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db, WithSoftDelete(), WithInsertTime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(10, 6)
+	ids := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	found, err := lsh.QueryIDs(sigs[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0] != 3 {
+		t.Errorf("Expected [3], got %v", found)
+	}
+	if err := lsh.Delete(3); err != nil {
+		t.Fatal(err)
+	}
+	out := make(chan Entry)
+	go func() {
+		if err := lsh.Scan(out); err != nil {
+			t.Error(err)
+		}
+		close(out)
+	}()
+	var scanned []int
+	for e := range out {
+		if !reflect.DeepEqual(e.Signature, sigs[e.Id]) {
+			t.Errorf("Expected %v, got %v", sigs[e.Id], e.Signature)
+		}
+		scanned = append(scanned, e.Id)
+	}
+	sort.Ints(scanned)
+	expected := []int{0, 1, 2, 4, 5, 6, 7, 8, 9}
+	if !reflect.DeepEqual(scanned, expected) {
+		t.Errorf("Expected %v, got %v", expected, scanned)
+	}
+	if n, err := lsh.CountCandidates(sigs[4]); err != nil || n != 1 {
+		t.Errorf("Expected 1 candidate, got %d, %v", n, err)
+	}
+	removeTempFile(t, f)
+}
+
+func Test_ModerncWorkers(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithInsertWorkers(4), WithCommitSize(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(1050, 4)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.BulkLoad("load", []int{2000, 2001}, randomSigs(2, 4)); err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM lshtable").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != len(sigs)+2 {
+		t.Errorf("Expected %d rows, got %d", len(sigs)+2, n)
+	}
+}
@@ -0,0 +1,25 @@
+package sqllsh
+
+import "database/sql"
+
+// multiRowSize returns the number of rows inserted per statement by
+// BatchInsert, 0 if rows are inserted one at a time.
+func (lsh *SqlLsh) multiRowSize() int {
+	if lsh.dialect.multiRowValues == 0 {
+		return 0
+	}
+	return lsh.dialect.multiRowValues / len(lsh.insertCols())
+}
+
+// insertRows inserts Signatures with one statement inside tx.
+func (lsh *SqlLsh) insertRows(tx *sql.Tx, ids []int, sigs []Signature) error {
+	args := make([]interface{}, 0, len(sigs)*len(lsh.insertCols()))
+	for i, sig := range sigs {
+		if err := lsh.purge(tx, ids[i]); err != nil {
+			return err
+		}
+		args = append(args, lsh.insertArgs(ids[i], sig)...)
+	}
+	_, err := tx.Exec(lsh.insertRowsStr(len(sigs)), args...)
+	return err
+}
@@ -0,0 +1,163 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// mysqlIndexesQuery is the query of the index names of a table in the
+// current MySQL database.
+const mysqlIndexesQuery = "SELECT DISTINCT index_name FROM information_schema.statistics " +
+	"WHERE table_schema = DATABASE() AND table_name = ?"
+
+// mysqlTablesQuery is the query of the table names of the current
+// MySQL database.
+const mysqlTablesQuery = "SELECT table_name FROM information_schema.tables " +
+	"WHERE table_schema = DATABASE()"
+
+var mysqlDialect = dialect{
+	varFmt: func(i int) string {
+		return "?"
+	},
+	intType:        "BIGINT",
+	blobType:       "BLOB",
+	limitFmt:       " LIMIT %d",
+	createIndexFmt: "CREATE INDEX %s ON %s",
+	dropIndexFmt:   "DROP INDEX %[1]s ON %[2]s",
+	indexMethods:   map[IndexType]string{IndexBTree: ""},
+	reindexFmt:     "OPTIMIZE TABLE %s",
+	analyzeFmt:     "ANALYZE TABLE %s",
+	explainPrefix:  "EXPLAIN FORMAT=TREE ",
+	autoIDType:     "INTEGER AUTO_INCREMENT PRIMARY KEY",
+	plan:           PlanUnion,
+	limitDelete:    true,
+	conflictClause: onDuplicateKeyClause,
+	indexesQuery:   mysqlIndexesQuery,
+	tablesQuery:    mysqlTablesQuery,
+	maxParams:      65535,
+	maxIdent:       64,
+	serializable:   true,
+	snapshot:       sql.LevelRepeatableRead,
+	implicitCommit: true,
+}
+
+// tidbDialect is the MySQL dialect with the limits of TiDB, which
+// fails transactions with too many statements, and cannot rebuild
+// indexes in place.
+var tidbDialect = dialect{
+	varFmt:         mysqlDialect.varFmt,
+	intType:        mysqlDialect.intType,
+	blobType:       mysqlDialect.blobType,
+	limitFmt:       mysqlDialect.limitFmt,
+	createIndexFmt: mysqlDialect.createIndexFmt,
+	dropIndexFmt:   mysqlDialect.dropIndexFmt,
+	indexMethods:   mysqlDialect.indexMethods,
+	analyzeFmt:     mysqlDialect.analyzeFmt,
+	explainPrefix:  `EXPLAIN FORMAT = "tidb_json" `,
+	autoIDType:     "INTEGER AUTO_INCREMENT PRIMARY KEY",
+	plan:           PlanUnion,
+	limitDelete:    true,
+	maxBatch:       2000,
+	conflictClause: onDuplicateKeyClause,
+	indexesQuery:   mysqlIndexesQuery,
+	tablesQuery:    mysqlTablesQuery,
+	maxParams:      65535,
+	maxIdent:       64,
+	snapshot:       sql.LevelRepeatableRead,
+	implicitCommit: true,
+	explainJSON:    tidbPlan,
+}
+
+// NewMysqlLsh creates a new MySQL-backed LSH index.
+// MySQL limits an index to 16 columns and a table to 64 indexes, so k
+// must be at most 16 and l at most 64 for Index to work.
+// MySQL commits the transaction of DDL statements, so Index, Compact
+// and DropIndexes run them one at a time outside of transactions, and
+// AddHashTables and Reshape are not supported.
+// The caller is responsible for closing the database connection
+// object.
+func NewMysqlLsh(k, l int, tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	lsh, err := newSqlLsh(k, l, tableName, db, mysqlDialect, opts)
+	return lsh, err
+}
+
+// NewTidbLsh creates a new TiDB-backed LSH index, which uses the MySQL
+// protocol.
+// Unless WithCommitSize is used, BatchInsert and BulkLoad commit every
+// 2000 rows to stay below the statement count limit of TiDB
+// transactions, so a failed BatchInsert may leave some of the
+// Signatures inserted, see BatchInsert.
+// Compact only purges the deleted entries, as TiDB does not rebuild
+// indexes in place.
+// The DDL statements are run like on MySQL, see NewMysqlLsh.
+// The caller is responsible for closing the database connection
+// object.
+func NewTidbLsh(k, l int, tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	lsh, err := newSqlLsh(k, l, tableName, db, tidbDialect, opts)
+	return lsh, err
+}
+
+// onDuplicateKeyClause returns the ON DUPLICATE KEY UPDATE clause of an
+// insert, used by MySQL for the conflict behavior c.
+// MySQL checks all unique keys, so keys are not used.
+func onDuplicateKeyClause(c Conflict, keys, cols []string) string {
+	switch c {
+	case ConflictIgnore:
+		return " ON DUPLICATE KEY UPDATE id = id"
+	case ConflictReplace:
+		seg := make([]string, len(cols))
+		for i, col := range cols {
+			seg[i] = fmt.Sprintf("%s = VALUES(%s)", col, col)
+		}
+		return " ON DUPLICATE KEY UPDATE " + strings.Join(seg, ", ")
+	}
+	return ""
+}
+
+// OpenMysqlLsh opens an existing MySQL-backed LSH index with its
+// recorded parameters, like OpenSqliteLsh.
+func OpenMysqlLsh(tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	return openSqlLsh(tableName, db, mysqlDialect, opts)
+}
+
+// OpenTidbLsh opens an existing TiDB-backed LSH index with its
+// recorded parameters, like OpenSqliteLsh.
+func OpenTidbLsh(tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	return openSqlLsh(tableName, db, tidbDialect, opts)
+}
+
+// tidbOperator is a step of a plan of TiDB in the tidb_json format.
+type tidbOperator struct {
+	ID           string         `json:"id"`
+	AccessObject string         `json:"accessObject"`
+	OperatorInfo string         `json:"operatorInfo"`
+	SubOperators []tidbOperator `json:"subOperators"`
+}
+
+// tidbPlan converts a plan of TiDB in the tidb_json format to one line
+// per operator, indented under its parent, with the object it reads,
+// such as the index, and its details.
+func tidbPlan(plan string) (string, error) {
+	var ops []tidbOperator
+	if err := json.Unmarshal([]byte(plan), &ops); err != nil {
+		return "", err
+	}
+	var lines []string
+	var add func(ops []tidbOperator, depth int)
+	add = func(ops []tidbOperator, depth int) {
+		for _, op := range ops {
+			line := strings.Repeat("  ", depth) + op.ID
+			for _, s := range []string{op.AccessObject, op.OperatorInfo} {
+				if s != "" {
+					line += " " + s
+				}
+			}
+			lines = append(lines, line)
+			add(op.SubOperators, depth+1)
+		}
+	}
+	add(ops, 0)
+	return strings.Join(lines, "\n"), nil
+}
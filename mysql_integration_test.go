@@ -0,0 +1,132 @@
+//go:build mysql
+
+package sqllsh
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// Test_TidbLsh runs against the MySQL-protocol database given by the
+// DSN in SQLLSH_MYSQL_DSN, such as a TiDB playground started with
+// "tiup playground" and the DSN "root@tcp(127.0.0.1:4000)/test".
+func Test_TidbLsh(t *testing.T) {
+	dsn := os.Getenv("SQLLSH_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("SQLLSH_MYSQL_DSN is not set")
+	}
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("DROP TABLE IF EXISTS lshtable;"); err != nil {
+		t.Fatal(err)
+	}
+	lsh, err := NewTidbLsh(2, 3, "lshtable", db, WithSoftDelete(), WithInsertTime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(5000, 6)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	found, err := lsh.QueryIDs(sigs[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) == 0 {
+		t.Error("Expected to find 3")
+	}
+	// Without arguments, the rows are read with the text protocol
+	it, err := lsh.ScanIter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for it.Next() {
+		e := it.Value()
+		if !sameSig(e.Signature, sigs[e.Id]) {
+			t.Errorf("Expected %v for %d, got %v", sigs[e.Id], e.Id, e.Signature)
+		}
+		n++
+	}
+	if err := it.Err(); err != nil || n != len(sigs) {
+		t.Errorf("Expected %d entries, got %d, %v", len(sigs), n, err)
+	}
+	if err := lsh.Insert(3, sigs[3]); !errors.Is(err, ErrIDExists) {
+		t.Errorf("Expected ErrIDExists, got %v", err)
+	}
+	if err := lsh.Delete(3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lsh.Compact(); err != nil {
+		t.Error(err)
+	}
+	if n, err := lsh.ExpireBefore(time.Now()); err != nil || n != 4999 {
+		t.Errorf("Expected 4999 entries expired, got %d, %v", n, err)
+	}
+}
+
+// Test_MariadbLsh runs against the MariaDB database given by the DSN in
+// SQLLSH_MARIADB_DSN, such as "root@tcp(127.0.0.1:3306)/test".
+func Test_MariadbLsh(t *testing.T) {
+	dsn := os.Getenv("SQLLSH_MARIADB_DSN")
+	if dsn == "" {
+		t.Skip("SQLLSH_MARIADB_DSN is not set")
+	}
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, table := range []string{"lshtable", "lshtable_meta"} {
+		if _, err := db.Exec("DROP TABLE IF EXISTS " + table); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lsh, err := NewMariadbLsh(2, 3, "lshtable", db, WithConflict(ConflictIgnore))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(1000, 6)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	// Index is idempotent on MariaDB
+	for i := 0; i < 2; i++ {
+		if err := lsh.Index(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The existing Signature is kept
+	if err := lsh.Insert(3, sigs[4]); err != nil {
+		t.Fatal(err)
+	}
+	found, err := lsh.QueryIDs(sigs[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsID(found, 3) {
+		t.Errorf("Expected 3 in %v", found)
+	}
+	if err := lsh.DropIndexes(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,85 @@
+package sqllsh
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_MysqlStatements(t *testing.T) {
+	lsh := &SqlLsh{k: 2, l: 2, tableName: "lshtable", dialect: mysqlDialect,
+		conflict: ConflictReplace}
+	expected := "INSERT INTO lshtable (id,hv_0,hv_1,hv_2,hv_3) VALUES(?,?,?,?,?)" +
+		" ON DUPLICATE KEY UPDATE hv_0 = VALUES(hv_0), hv_1 = VALUES(hv_1)," +
+		" hv_2 = VALUES(hv_2), hv_3 = VALUES(hv_3)"
+	if s := lsh.insertStr(); s != expected {
+		t.Errorf("Expected %q, got %q", expected, s)
+	}
+	expected = "CREATE INDEX lshtable_ht_1 ON lshtable (hv_2,hv_3)"
+	if s := lsh.indexStr(1); s != expected {
+		t.Errorf("Expected %q, got %q", expected, s)
+	}
+}
+
+func Test_TidbBatchSize(t *testing.T) {
+	lsh := &SqlLsh{dialect: tidbDialect}
+	if n := lsh.batchSize(10000); n != 2000 {
+		t.Errorf("Expected 2000, got %d", n)
+	}
+	if n := lsh.batchSize(100); n != 100 {
+		t.Errorf("Expected 100, got %d", n)
+	}
+	lsh.commitSize = 5000
+	if n := lsh.batchSize(10000); n != 5000 {
+		t.Errorf("Expected 5000, got %d", n)
+	}
+}
+
+func Test_MysqlImplicitCommit(t *testing.T) {
+	rec := NewRecorder()
+	defer rec.Close()
+	lsh, err := NewMysqlLsh(2, 2, "lshtable", rec, WithSoftDelete())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Reset()
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lsh.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	var ddl int
+	for _, stmt := range rec.Statements() {
+		if strings.HasPrefix(stmt.Query, "CREATE INDEX") ||
+			strings.HasPrefix(stmt.Query, "OPTIMIZE TABLE") {
+			ddl++
+			if stmt.Tx {
+				t.Errorf("Expected %q to run outside of a transaction", stmt.Query)
+			}
+		} else if strings.HasPrefix(stmt.Query, "DELETE") && !stmt.Tx {
+			t.Errorf("Expected %q to run inside a transaction", stmt.Query)
+		}
+	}
+	if ddl != 3 {
+		t.Errorf("Expected 3 DDL statements, got %v", rec.Statements())
+	}
+	if err := lsh.Reshape(1, 4, nil); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
+
+func Test_TidbPlan(t *testing.T) {
+	plan := `[{"id": "HashAgg_10", "operatorInfo": "group by:id",
+		"subOperators": [{"id": "IndexReader_12", "accessObject": "index:lshtable_ht_0(hv_0, hv_1)"}]}]`
+	lines, err := tidbPlan(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "HashAgg_10 group by:id\n  IndexReader_12 index:lshtable_ht_0(hv_0, hv_1)"
+	if lines != expected {
+		t.Errorf("Expected %q, got %q", expected, lines)
+	}
+	if _, err := tidbPlan("id\ttask"); err == nil {
+		t.Error("Expected an error for a plan that is not JSON")
+	}
+}
@@ -0,0 +1,76 @@
+package sqllsh
+
+import "fmt"
+
+// WithNamespaces adds a namespace column to the table, which is part
+// of the primary key together with the id, so that many tenants can
+// share one table, with the same IDs in different namespaces.
+// Use Namespace to insert, query and delete in a namespace; the index
+// itself works on all namespaces, inserting in namespace 0.
+// AddHashTables, Reshape and WithAutoID are not supported.
+// The option changes the table schema, so it must be used every time
+// the same table is opened.
+func WithNamespaces() Option {
+	return func(lsh *SqlLsh) {
+		lsh.namespaces = true
+	}
+}
+
+// Namespace returns a view of the index scoped to the namespace ns:
+// its inserts go to ns, and its queries, scans and deletes only see
+// the entries of ns.
+// The view shares the table and indexes of lsh, and runs its
+// statements without preparing them, so any number of namespaces can
+// be used.
+// The query cache is not used by the view, and results cached by lsh
+// may miss the changes made through the view.
+// With PartitionList, the partition of ns is created if it does not
+// exist.
+// It requires the index to be created using WithNamespaces.
+func (lsh *SqlLsh) Namespace(ns int64) (*SqlLsh, error) {
+	if !lsh.namespaces {
+		return nil, ErrUnsupported
+	}
+	if lsh.partitioning == PartitionList {
+		if _, err := lsh.db.Exec(lsh.listPartitionStr(ns)); err != nil {
+			return nil, wrapErr("namespace", err)
+		}
+	}
+	view := *lsh
+	view.ns = ns
+	view.scoped = true
+	view.adHoc = true
+	view.ownDB = false
+	view.parentCache = lsh.sharedCache()
+	view.cache = nil
+	view.insertStmt = nil
+	view.queryStmt = nil
+	view.countStmt = nil
+	view.scanStmt = nil
+	view.deleteStmt = nil
+	view.purgeStmt = nil
+	return &view, nil
+}
+
+// scopeCond returns the condition, followed by AND, that selects the
+// entries of the namespace or ensemble partition of a view, or an
+// empty string otherwise.
+// The namespace and partition are numbers, so they are written in the
+// SQL directly.
+func (lsh *SqlLsh) scopeCond() string {
+	if lsh.inPart {
+		return fmt.Sprintf("part = %d AND ", lsh.part)
+	}
+	if !lsh.scoped {
+		return ""
+	}
+	return fmt.Sprintf("ns = %d AND ", lsh.ns)
+}
+
+// keyCols returns the columns of the primary key.
+func (lsh *SqlLsh) keyCols() []string {
+	if lsh.namespaces {
+		return []string{"ns", "id"}
+	}
+	return []string{"id"}
+}
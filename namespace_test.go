@@ -0,0 +1,109 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func Test_Namespace(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithNamespaces(), WithSoftDelete())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := lsh.Namespace(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := lsh.Namespace(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigA := Signature{1, 2, 3, 4}
+	sigB := Signature{5, 6, 7, 8}
+	// The same ID in both namespaces
+	if err := a.Insert(1, sigA); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.BatchInsert([]int{1, 2}, []Signature{sigB, sigB}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Insert(1, sigB); !errors.Is(err, ErrIDExists) {
+		t.Errorf("Expected ErrIDExists, got %v", err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := a.QueryIDs(sigB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 0 {
+		t.Errorf("Expected no results in namespace 1, got %v", ids)
+	}
+	ids, err = b.QueryIDs(sigB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 {
+		t.Errorf("Expected 2 results in namespace 2, got %v", ids)
+	}
+	if err := b.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+	ids, err = a.QueryIDs(sigA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("Expected [1] in namespace 1, got %v", ids)
+	}
+	n, err := b.Compact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("Expected 1 purged entry, got %d", n)
+	}
+	out := make(chan Entry)
+	go func() {
+		if err := a.Scan(out); err != nil {
+			t.Error(err)
+		}
+		close(out)
+	}()
+	count := 0
+	for range out {
+		count++
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 entry in namespace 1, got %d", count)
+	}
+}
+
+func Test_NamespaceUnsupported(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lsh.Namespace(1); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+	if _, err := NewSqliteLsh(2, 2, "autotable", db, WithNamespaces(), WithAutoID()); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
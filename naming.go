@@ -0,0 +1,100 @@
+package sqllsh
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// WithIndexPrefix names the indexes built by Index prefix0, prefix1
+// and so on, instead of deriving their names from the table name as
+// <table>_ht_0, <table>_ht_1, ...
+// Index names must be unique in a schema for most databases, so the
+// prefix must differ between the indexes sharing one.
+// The prefix is recorded in the metadata table when the table is
+// created, and used when the table is opened without this option.
+// Indexes built by earlier versions are named ht_0, ht_1, ..., which
+// is recorded when their tables are first opened by this version.
+func WithIndexPrefix(prefix string) Option {
+	return func(lsh *SqlLsh) {
+		lsh.indexPrefix = prefix
+	}
+}
+
+// indexName returns the name of the index of hash table i.
+func (lsh *SqlLsh) indexName(i int) string {
+	prefix := lsh.indexPrefix
+	if prefix == "" {
+		prefix = lsh.defaultIndexPrefix()
+	}
+	return fmt.Sprintf("%s%d", prefix, i)
+}
+
+// defaultIndexPrefix returns the prefix of the index names derived
+// from the table name, <table>_ht_, without the schema since indexes
+// are created in the schema of their table.
+// If the names would be longer than the identifiers of the database,
+// the table name is cut and followed by a hash of it, keeping the names
+// of different tables apart.
+func (lsh *SqlLsh) defaultIndexPrefix() string {
+	base := lsh.baseName()
+	// Room for the hash tables added later by AddHashTables
+	digits := len(strconv.Itoa(lsh.l - 1))
+	if digits < 3 {
+		digits = 3
+	}
+	max := lsh.dialect.maxIdent
+	if max == 0 || len(base)+len("_ht_")+digits <= max {
+		return base + "_ht_"
+	}
+	h := fnv.New32a()
+	h.Write([]byte(base))
+	keep := max - len("_ht_") - digits - 9
+	if keep > len(base) {
+		keep = len(base)
+	}
+	return fmt.Sprintf("%s_%08x_ht_", base[:keep], h.Sum32())
+}
+
+// baseName returns the name of the table without its schema and
+// quotes.
+func (lsh *SqlLsh) baseName() string {
+	name := lsh.tableName[strings.LastIndex(lsh.tableName, ".")+1:]
+	return strings.Trim(name, "\"`[]")
+}
+
+// Names are the names of the database objects of an index.
+type Names struct {
+	Table      string   // Holds the entries
+	Meta       string   // Holds the parameters of the index
+	Checkpoint string   // Holds the progress of BulkLoad, created by its first call
+	Partitions []string // Created with the table by WithPartitions
+	Indexes    []string // Built by Index, one per hash table, empty if the database has none
+}
+
+// Names returns the names of the tables and indexes the index creates,
+// so that they can be granted, monitored or dropped by other tools.
+// The list partitions created later for other namespaces are named
+// <table>_ns<namespace>, with a negative namespace written as m<-ns>.
+func (lsh *SqlLsh) Names() Names {
+	n := Names{
+		Table:      lsh.tableName,
+		Meta:       lsh.metaTable(),
+		Checkpoint: lsh.checkpointTable(),
+	}
+	switch lsh.partitioning {
+	case PartitionList:
+		n.Partitions = []string{lsh.partitionName(0)}
+	case PartitionHash:
+		for i := 0; i < lsh.partitions; i++ {
+			n.Partitions = append(n.Partitions, lsh.hashPartitionName(i))
+		}
+	}
+	if lsh.dialect.createIndexFmt != "" {
+		for i := 0; i < lsh.l; i++ {
+			n.Indexes = append(n.Indexes, lsh.indexName(i))
+		}
+	}
+	return n
+}
@@ -0,0 +1,76 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func Test_IndexNames(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// Two tables in the same database, both indexed
+	a, err := NewSqliteLsh(2, 2, "a", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewSqliteLsh(2, 2, "b", db, WithIndexPrefix("b_idx_"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Index(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Index(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Validate(); err != nil {
+		t.Error(err)
+	}
+	names := b.Names()
+	if names.Table != "b" || names.Meta != "b_meta" || names.Checkpoint != "b_checkpoint" {
+		t.Errorf("Unexpected table names %+v", names)
+	}
+	if len(names.Indexes) != 2 || names.Indexes[0] != "b_idx_0" || names.Indexes[1] != "b_idx_1" {
+		t.Errorf("Expected [b_idx_0 b_idx_1], got %v", names.Indexes)
+	}
+	found, err := b.indexNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names.Indexes {
+		if !found[name] {
+			t.Errorf("Index %s not found in %v", name, found)
+		}
+	}
+	if err := a.DropIndexes(); err != nil {
+		t.Fatal(err)
+	}
+	if found, err = b.indexNames(); err != nil || len(found) != 2 {
+		t.Errorf("Expected the indexes of b to be kept, got %v, %v", found, err)
+	}
+	s := PostgresStatements(2, 2, `public."lsh"`)
+	if s.CreateIndexes[0] != `CREATE INDEX lsh_ht_0 ON public."lsh" USING BTREE (hv_0,hv_1)` {
+		t.Errorf("Unexpected index name in %s", s.CreateIndexes[0])
+	}
+	long := strings.Repeat("a", 70)
+	rec := NewRecorder()
+	defer rec.Close()
+	seen := make(map[string]bool)
+	for _, table := range []string{long + "1", long + "2"} {
+		lsh, err := NewPostgresLsh(2, 2, table, rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := lsh.Names().Indexes[1]
+		if len(name) > 63 || seen[name] {
+			t.Errorf("Expected a unique name of at most 63 characters, got %s", name)
+		}
+		seen[name] = true
+	}
+}
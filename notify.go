@@ -0,0 +1,105 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+)
+
+// notifyPayloadSize is the maximum size of the payload of a
+// notification, below the 8000 bytes allowed by PostgreSQL.
+const notifyPayloadSize = 7900
+
+// InsertListener is called with the IDs of the Signatures once they are
+// inserted.
+type InsertListener func(ids []int)
+
+// WithInsertListener adds l to the listeners called after Signatures
+// are committed by Insert, InsertAuto, InsertIfNovel, BatchInsert and
+// BulkLoad, for
+// example to invalidate the caches of the application or to look for
+// duplicates of the new Signatures as they arrive.
+// With WithCommitSize, BulkLoad and WithInsertWorkers, l is called once
+// per committed chunk, from the goroutine that inserted it, so it may be
+// called concurrently. InsertTx and BatchInsertTx do not call l, as the
+// caller commits their transaction.
+// The IDs of the ignored inserts of ConflictIgnore and
+// ConflictIdempotent are given too, and l must not modify them.
+func WithInsertListener(l InsertListener) Option {
+	return func(lsh *SqlLsh) {
+		lsh.listeners = append(lsh.listeners, l)
+	}
+}
+
+// WithInsertChannel sends the IDs of the inserted Signatures to ch, as
+// a listener of WithInsertListener does.
+// The inserts block until ch receives the IDs, so it must be read
+// concurrently, or buffered.
+func WithInsertChannel(ch chan<- int) Option {
+	return WithInsertListener(func(ids []int) {
+		for _, id := range ids {
+			ch <- id
+		}
+	})
+}
+
+// WithNotify sends the IDs of the inserted Signatures on the PostgreSQL
+// notification channel, so that the clients running LISTEN on it
+// learn about the inserts of all the processes writing to the index.
+// The payload is the IDs in decimal separated by commas, split in
+// several notifications if it would be longer than PostgreSQL allows.
+// The notifications are sent in the transaction of the insert, so they
+// are only delivered if it commits.
+// The constructor returns ErrUnsupported on other databases.
+func WithNotify(channel string) Option {
+	return func(lsh *SqlLsh) {
+		lsh.notify = channel
+	}
+}
+
+// execQuerier runs statements and queries, such as a dbConn or a
+// *sql.Tx.
+type execQuerier interface {
+	rowQuerier
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// afterInsert runs the checks and sends the notifications of the
+// inserts of ids, with q, before the transaction commits.
+func (lsh *SqlLsh) afterInsert(q execQuerier, ids []int, sigs []Signature) error {
+	if err := lsh.checkInserted(q, ids, sigs); err != nil {
+		return err
+	}
+	if lsh.notify == "" {
+		return nil
+	}
+	stmt := fmt.Sprintf(lsh.dialect.notifyFmt, lsh.dialect.varFmt(0), lsh.dialect.varFmt(1))
+	var payload []byte
+	for i, id := range ids {
+		payload = strconv.AppendInt(payload, int64(id), 10)
+		if i < len(ids)-1 && len(payload) < notifyPayloadSize-21 {
+			payload = append(payload, ',')
+			continue
+		}
+		if _, err := q.Exec(stmt, lsh.notify, string(payload)); err != nil {
+			return err
+		}
+		payload = payload[:0]
+	}
+	return nil
+}
+
+// inserted updates the query cache and calls the listeners once ids
+// are inserted.
+func (lsh *SqlLsh) inserted(ids []int, sigs []Signature) {
+	lsh.invalidate(ids, sigs)
+	lsh.callListeners(ids)
+}
+
+// callListeners calls the listeners of WithInsertListener with the
+// committed ids.
+func (lsh *SqlLsh) callListeners(ids []int) {
+	for _, l := range lsh.listeners {
+		l(ids)
+	}
+}
@@ -0,0 +1,125 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func Test_InsertListener(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var got []int
+	ch := make(chan int, 10)
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db,
+		WithInsertListener(func(ids []int) { got = append(got, ids...) }),
+		WithInsertChannel(ch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Insert(0, Signature{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.BatchInsert([]int{1, 2}, []Signature{{1, 2, 5, 6}, {7, 8, 9, 10}}); err != nil {
+		t.Fatal(err)
+	}
+	// A failed insert is not notified
+	if err := lsh.Insert(0, Signature{1, 2, 3, 4}); err == nil {
+		t.Fatal("Expected ErrIDExists")
+	}
+	close(ch)
+	var sent []int
+	for id := range ch {
+		sent = append(sent, id)
+	}
+	for _, ids := range [][]int{got, sent} {
+		if fmt.Sprint(ids) != "[0 1 2]" {
+			t.Errorf("Expected [0 1 2], got %v", ids)
+		}
+	}
+	if _, err := NewSqliteLsh(2, 2, "lshtable", db, WithNotify("inserts")); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
+
+func Test_Notify(t *testing.T) {
+	rec := NewRecorder()
+	defer rec.Close()
+	lsh, err := NewPostgresLsh(2, 2, "lshtable", rec, WithNotify("inserts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]int, 2000)
+	sigs := make([]Signature, len(ids))
+	for i := range ids {
+		ids[i] = 1000000 + i
+		sigs[i] = Signature{1, 2, 3, uint(i)}
+	}
+	rec.Reset()
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	var payloads []string
+	for _, stmt := range rec.Statements() {
+		if stmt.Query == "SELECT pg_notify($1, $2)" {
+			if stmt.Args[0] != "inserts" {
+				t.Errorf("Expected channel inserts, got %v", stmt.Args[0])
+			}
+			payloads = append(payloads, stmt.Args[1].(string))
+		}
+	}
+	if len(payloads) < 2 {
+		t.Fatalf("Expected the payload to be split, got %d notifications", len(payloads))
+	}
+	var sent []string
+	for _, p := range payloads {
+		if len(p) >= 8000 {
+			t.Errorf("Payload of %d bytes is too long", len(p))
+		}
+		sent = append(sent, strings.Split(p, ",")...)
+	}
+	if len(sent) != len(ids) || sent[0] != "1000000" || sent[len(sent)-1] != "1001999" {
+		t.Errorf("Expected all the IDs in order, got %d IDs", len(sent))
+	}
+}
+
+func Test_InsertTxListener(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var got []int
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db,
+		WithInsertListener(func(ids []int) { got = append(got, ids...) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.InsertTx(tx, 0, Signature{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Expected no IDs for an uncommitted insert, got %v", got)
+	}
+	if _, ok, err := lsh.InsertIfNovel(1, Signature{1, 2, 3, 4}, 1); err != nil || !ok {
+		t.Fatalf("Expected the insert, got %v, %v", ok, err)
+	}
+	if fmt.Sprint(got) != "[1]" {
+		t.Errorf("Expected [1] once committed, got %v", got)
+	}
+}
@@ -0,0 +1,119 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// novelRetry is the retry policy of InsertIfNovel without WithRetry,
+// for the serialization failures of concurrent calls.
+var novelRetry = RetryPolicy{MaxAttempts: 5, Backoff: 10 * time.Millisecond, MaxBackoff: time.Second}
+
+// InsertIfNovel inserts sig with id only if no Signature in the index
+// has at least minCollisions hash key collisions with it, which is the
+// check of streaming deduplication.
+// It returns the IDs of those near-duplicate Signatures, and whether
+// sig was inserted.
+// The check and the insert are done in one transaction, which must be
+// serializable so that concurrent calls cannot both insert
+// near-duplicates: on PostgreSQL, MySQL, MariaDB and Oracle it is
+// started at sql.LevelSerializable, and SQLite, DuckDB and Spanner
+// transactions are serializable already; on the other databases the
+// default isolation level of the database is used.
+// The transactions failing with a serialization failure are retried
+// following the policy of WithRetry, or up to 5 times without it.
+// When the DB is a *sql.Tx or a *sql.Conn, failures are not retried,
+// and with a *sql.Tx the isolation level is the caller's.
+func (lsh *SqlLsh) InsertIfNovel(id int, sig Signature, minCollisions int) ([]int, bool, error) {
+	// The hooks run once, so the check and the insert use the same
+	// Signature
+	sigs, err := lsh.beforeInsert("insert", []int{id}, []Signature{sig})
+	if err != nil {
+		return nil, false, err
+	}
+	sig = sigs[0]
+	if len(sig) != lsh.k*lsh.l {
+		return nil, false, ErrSignatureSizeMismatch
+	}
+	if minCollisions < 1 || minCollisions > lsh.l {
+		return nil, false, ErrInvalidParameter
+	}
+	p := lsh.retryPolicy
+	if p == nil {
+		p = &novelRetry
+	}
+	if pinned(lsh.db.DB) {
+		p = nil
+	}
+	var dupIDs []int
+	var inserted bool
+	err = lsh.retryWith(p, "insert", func() (err error) {
+		dupIDs, inserted, err = lsh.insertIfNovel(id, sig, minCollisions)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if inserted {
+		lsh.callListeners([]int{id})
+	}
+	return dupIDs, inserted, nil
+}
+
+// insertIfNovel runs the transaction of InsertIfNovel once.
+func (lsh *SqlLsh) insertIfNovel(id int, sig Signature, minCollisions int) ([]int, bool, error) {
+	var opts *sql.TxOptions
+	if lsh.dialect.serializable {
+		opts = &sql.TxOptions{Isolation: sql.LevelSerializable}
+	}
+	tx, err := lsh.db.BeginTx(opts)
+	if err != nil {
+		return nil, false, wrapErr("insert", err)
+	}
+	args := append(lsh.bandsArgs(sig, lsh.allBands(), lsh.k), minCollisions)
+	rows, err := tx.Query(lsh.novelStr(), args...)
+	if err != nil {
+		tx.Rollback()
+		return nil, false, wrapErr("insert", err)
+	}
+	var dupIDs []int
+	for rows.Next() {
+		var dupID int
+		if err := rows.Scan(&dupID); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, false, wrapErr("insert", err)
+		}
+		dupIDs = append(dupIDs, dupID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return nil, false, wrapErr("insert", err)
+	}
+	if len(dupIDs) > 0 {
+		return dupIDs, false, wrapErr("insert", tx.Rollback())
+	}
+	if err := lsh.insertTx(tx.Tx, id, sig); err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return nil, false, wrapErr("insert", err)
+	}
+	return nil, true, nil
+}
+
+// novelStr returns the query of the IDs with at least a given number
+// of hash key collisions, the last argument after bandsArgs.
+func (lsh *SqlLsh) novelStr() string {
+	selects := lsh.bandSelects(lsh.allBands(), lsh.k, func(int) string {
+		return "id"
+	})
+	return "SELECT id FROM (" + strings.Join(selects, " UNION ALL ") +
+		fmt.Sprintf(") c GROUP BY id HAVING COUNT(*) >= %s",
+			lsh.dialect.varFmt(lsh.l*lsh.bandWidth(lsh.k)))
+}
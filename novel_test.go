@@ -0,0 +1,92 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"sync"
+	"testing"
+)
+
+func Test_InsertIfNovel(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dups, inserted, err := lsh.InsertIfNovel(1, Signature{1, 2, 3, 4, 5, 6}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !inserted || len(dups) != 0 {
+		t.Errorf("Expected the first Signature to be inserted, got %v %v", dups, inserted)
+	}
+	// One collision is below the threshold
+	dups, inserted, err = lsh.InsertIfNovel(2, Signature{1, 2, 9, 9, 9, 9}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !inserted || len(dups) != 0 {
+		t.Errorf("Expected a Signature with one collision to be inserted, got %v %v", dups, inserted)
+	}
+	dups, inserted, err = lsh.InsertIfNovel(3, Signature{1, 2, 3, 4, 0, 0}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inserted || len(dups) != 1 || dups[0] != 1 {
+		t.Errorf("Expected the duplicate of 1 not to be inserted, got %v %v", dups, inserted)
+	}
+	ids, err := lsh.QueryIDs(Signature{1, 2, 3, 4, 0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if containsID(ids, 3) {
+		t.Errorf("Expected 3 not to be in the index, got %v", ids)
+	}
+	if _, _, err := lsh.InsertIfNovel(4, Signature{1, 2, 3, 4, 5, 6}, 4); err != ErrInvalidParameter {
+		t.Errorf("Expected ErrInvalidParameter, got %v", err)
+	}
+}
+
+func Test_InsertIfNovelConcurrent(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Near-duplicates inserted concurrently, of which only one may be
+	// inserted
+	var wg sync.WaitGroup
+	results := make([]bool, 4)
+	errs := make([]error, 4)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, results[i], errs[i] = lsh.InsertIfNovel(i, Signature{1, 2, 3, 4, 5, uint(i)}, 2)
+		}(i)
+	}
+	wg.Wait()
+	n := 0
+	for i, inserted := range results {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		if inserted {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("Expected one Signature to be inserted, got %d", n)
+	}
+}
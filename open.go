@@ -0,0 +1,49 @@
+package sqllsh
+
+import "database/sql"
+
+// openSqlLsh opens the existing index in tableName, with the k, l,
+// layout and options changing the columns recorded in its metadata
+// table.
+// It returns ErrNotFound if the table does not exist or has no
+// metadata, which is the case of tables created by older versions of
+// the package until they are opened once with a constructor.
+func openSqlLsh(tableName string, db DB, d dialect, opts []Option) (*SqlLsh, error) {
+	lsh := &SqlLsh{tableName: tableName, db: dbConn{DB: db}, dialect: d}
+	if !lsh.tableExists(lsh.metaTable()) {
+		return nil, ErrNotFound
+	}
+	m, err := readMeta(lsh.db, lsh.metaTable())
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, wrapErr("open", err)
+	}
+	var layout []Option
+	switch m.Layout {
+	case layoutCompact:
+		layout = append(layout, WithCompactLayout())
+	case layoutBandKeys:
+		layout = append(layout, WithBandKeys())
+	case layoutColumns:
+	default:
+		return nil, ErrSchemaMismatch
+	}
+	if m.Bits != 0 {
+		layout = append(layout, WithBBit(m.Bits))
+	}
+	if m.IDType == idAuto {
+		layout = append(layout, WithAutoID())
+	}
+	if m.SoftDelete {
+		layout = append(layout, WithSoftDelete())
+	}
+	if m.InsertTime {
+		layout = append(layout, WithInsertTime())
+	}
+	if m.Namespaces {
+		layout = append(layout, WithNamespaces())
+	}
+	return newSqlLsh(m.K, m.L, tableName, db, d, append(layout, opts...))
+}
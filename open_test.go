@@ -0,0 +1,48 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_OpenSqliteLsh(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := OpenSqliteLsh("lshtable", db); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound for a missing table, got %v", err)
+	}
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db, WithCompactLayout())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := Signature{1, 2, 3, 4, 5, 6}
+	if err := lsh.Insert(1, sig); err != nil {
+		t.Fatal(err)
+	}
+	opened, err := OpenSqliteLsh("lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened.k != 2 || opened.l != 3 || !opened.packed {
+		t.Errorf("Expected k = 2, l = 3 and the compact layout, got %d, %d, %v",
+			opened.k, opened.l, opened.packed)
+	}
+	got, err := opened.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameSig(got, sig) {
+		t.Errorf("Expected %v, got %v", sig, got)
+	}
+	if _, err := db.Exec("DELETE FROM lshtable_meta"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenSqliteLsh("lshtable", db); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package sqllsh
+
+// Option configures an SqlLsh when it is created.
+type Option func(*SqlLsh)
+
+// WithSoftDelete makes Delete mark entries as deleted using a
+// deleted column, instead of removing the rows.
+// Marking is cheaper than a physical delete when the table has many
+// indexes. The marked rows can be purged later using Compact.
+// The option changes the table schema, so it must be used every time
+// the same table is opened.
+func WithSoftDelete() Option {
+	return func(lsh *SqlLsh) {
+		lsh.softDelete = true
+	}
+}
+
+// WithInsertTime records the time each entry is inserted in an
+// inserted_at column, holding Unix nanoseconds, so that old entries
+// can be removed using ExpireBefore.
+// The option changes the table schema, so it must be used every time
+// the same table is opened.
+func WithInsertTime() Option {
+	return func(lsh *SqlLsh) {
+		lsh.insertTime = true
+	}
+}
+
+// WithCommitSize makes BatchInsert and BulkLoad commit every n rows,
+// instead of inserting everything in one transaction, which for a large
+// batch uses a lot of transaction log space and holds locks for a long
+// time.
+// The trade-off is that a failed BatchInsert is no longer all or
+// nothing, see BatchInsert.
+func WithCommitSize(n int) Option {
+	return func(lsh *SqlLsh) {
+		lsh.commitSize = n
+	}
+}
+
+// batchSize returns the number of rows to insert per transaction, def
+// unless WithCommitSize is used or the database limits it.
+func (lsh *SqlLsh) batchSize(def int) int {
+	if lsh.commitSize > 0 {
+		return lsh.commitSize
+	}
+	limit := lsh.dialect.maxBatch
+	if lsh.dialect.maxValues > 0 {
+		n := lsh.dialect.maxValues / len(lsh.insertCols())
+		if limit == 0 || n < limit {
+			limit = n
+		}
+	}
+	if limit > 0 && def > limit {
+		return limit
+	}
+	return def
+}
@@ -0,0 +1,48 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+var oracleDialect = dialect{
+	varFmt: func(i int) string {
+		return fmt.Sprintf(":%d", i+1)
+	},
+	intType:        "NUMBER(19)",
+	blobType:       "BLOB",
+	limitFmt:       " FETCH FIRST %d ROWS ONLY",
+	createIndexFmt: "CREATE INDEX %s ON %s",
+	dropIndexFmt:   "DROP INDEX %[1]s",
+	indexMethods:   map[IndexType]string{IndexBTree: ""},
+	analyzeFmt:     "BEGIN DBMS_STATS.GATHER_TABLE_STATS(USER, '%s'); END;",
+	plan:           PlanOr,
+	indexesQuery:   "SELECT index_name FROM user_indexes WHERE table_name = UPPER(:1)",
+	tablesQuery:    "SELECT LOWER(table_name) FROM user_tables",
+	maxParams:      1000,
+	maxIdent:       30,
+	serializable:   true,
+	snapshot:       sql.LevelSerializable,
+	implicitCommit: true,
+}
+
+// NewOracleLsh creates a new Oracle-backed LSH index, for example using
+// the github.com/godror/godror driver.
+// It requires Oracle Database 23ai or later, which supports IF NOT
+// EXISTS in CREATE TABLE.
+// Only ConflictError is supported, ExplainQuery is not supported, and
+// Compact only purges the deleted entries.
+// Oracle commits the transaction on DDL statements, so Reshape and
+// AddHashTables are not supported.
+// The caller is responsible for closing the database connection
+// object.
+func NewOracleLsh(k, l int, tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	lsh, err := newSqlLsh(k, l, tableName, db, oracleDialect, opts)
+	return lsh, err
+}
+
+// OpenOracleLsh opens an existing Oracle-backed LSH index with its
+// recorded parameters, like OpenSqliteLsh.
+func OpenOracleLsh(tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	return openSqlLsh(tableName, db, oracleDialect, opts)
+}
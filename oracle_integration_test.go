@@ -0,0 +1,84 @@
+//go:build oracle
+
+package sqllsh
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+
+	_ "github.com/godror/godror"
+)
+
+// Test_OracleLsh runs against the Oracle database given by the
+// connection string in SQLLSH_ORACLE_DSN, such as
+// `user="test" password="test" connectString="localhost:1521/FREEPDB1"`.
+func Test_OracleLsh(t *testing.T) {
+	dsn := os.Getenv("SQLLSH_ORACLE_DSN")
+	if dsn == "" {
+		t.Skip("SQLLSH_ORACLE_DSN is not set")
+	}
+	db, err := sql.Open("godror", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("DROP TABLE IF EXISTS lshtable"); err != nil {
+		t.Fatal(err)
+	}
+	lsh, err := NewOracleLsh(2, 3, "lshtable", db, WithSoftDelete())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(100, 6)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Analyze(); err != nil {
+		t.Error(err)
+	}
+	found, err := lsh.QueryIDs(sigs[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) == 0 {
+		t.Error("Expected to find 3")
+	}
+	// The NUMBER columns are scanned as godror.Number
+	it, err := lsh.ScanIter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for it.Next() {
+		e := it.Value()
+		if !sameSig(e.Signature, sigs[e.Id]) {
+			t.Errorf("Expected %v for %d, got %v", sigs[e.Id], e.Id, e.Signature)
+		}
+		n++
+	}
+	if err := it.Err(); err != nil || n != len(sigs) {
+		t.Errorf("Expected %d entries, got %d, %v", len(sigs), n, err)
+	}
+	page, err := lsh.QueryPage(sigs[3], nil, 1)
+	if err != nil || len(page) != 1 {
+		t.Errorf("Expected a page of 1, got %v, %v", page, err)
+	}
+	if err := lsh.Insert(3, sigs[3]); !errors.Is(err, ErrIDExists) {
+		t.Errorf("Expected ErrIDExists, got %v", err)
+	}
+	if err := lsh.Delete(3); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := lsh.Compact(); err != nil || n != 1 {
+		t.Errorf("Expected 1 entry purged, got %d, %v", n, err)
+	}
+}
@@ -0,0 +1,58 @@
+package sqllsh
+
+import "testing"
+
+func Test_OracleStatements(t *testing.T) {
+	s := newStatements(2, 2, "lshtable", oracleDialect, []Option{WithInsertTime()})
+	expected := "CREATE TABLE IF NOT EXISTS lshtable (\nid INTEGER PRIMARY KEY,\n" +
+		"hv_0 NUMBER(19) NOT NULL,\nhv_1 NUMBER(19) NOT NULL,\nhv_2 NUMBER(19) NOT NULL,\nhv_3 NUMBER(19) NOT NULL,\n" +
+		"inserted_at NUMBER(19) DEFAULT 0 NOT NULL\n)"
+	if s.CreateTable != expected {
+		t.Errorf("Expected %q, got %q", expected, s.CreateTable)
+	}
+	expected = "INSERT INTO lshtable (id,hv_0,hv_1,hv_2,hv_3,inserted_at) VALUES(:1,:2,:3,:4,:5,:6)"
+	if s.Insert != expected {
+		t.Errorf("Expected %q, got %q", expected, s.Insert)
+	}
+}
+
+func Test_OracleImplicitCommit(t *testing.T) {
+	rec := NewRecorder()
+	defer rec.Close()
+	lsh, err := NewOracleLsh(2, 2, "lshtable", rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Oracle commits the transaction on DDL statements
+	if err := lsh.Reshape(1, 4, nil); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+	if err := lsh.AddHashTables(1, nil); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
+
+func Test_OracleConflict(t *testing.T) {
+	if _, err := NewOracleLsh(2, 2, "lshtable", nil, WithConflict(ConflictIgnore)); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
+
+// oracleNumber is like godror.Number, the value of a NUMBER column
+// scanned into an interface{}.
+type oracleNumber string
+
+func (n oracleNumber) String() string { return string(n) }
+
+func Test_OracleNumber(t *testing.T) {
+	if n, err := intValue(oracleNumber("-42")); err != nil || n != -42 {
+		t.Errorf("Expected -42, got %d, %v", n, err)
+	}
+	// A hash value stored with its high bit set
+	if n, err := intValue(oracleNumber("18446744073709551615")); err != nil || uint64(n) != 1<<64-1 {
+		t.Errorf("Expected 1<<64-1, got %d, %v", n, err)
+	}
+	if _, err := intValue(oracleNumber("1.5")); err == nil {
+		t.Error("Expected an error for a fraction")
+	}
+}
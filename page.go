@@ -0,0 +1,59 @@
+package sqllsh
+
+import "fmt"
+
+// QueryPage is like QueryIDs, but returns at most limit IDs, in
+// ascending order, so a query with many collisions can be consumed in
+// pages.
+// If after is nil the first page is returned, otherwise the page of
+// IDs greater than after, which is usually the last ID of the previous
+// page. A page with fewer than limit IDs is the last one.
+// The pages are found using keyset pagination on the ID, so every page
+// costs about the same.
+func (lsh *SqlLsh) QueryPage(sig Signature, after *int, limit int) ([]int, error) {
+	sig, err := lsh.beforeQuery("query page", sig)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != lsh.k*lsh.l {
+		return nil, ErrSignatureSizeMismatch
+	}
+	if limit < 1 {
+		return nil, ErrInvalidParameter
+	}
+	if err := lsh.ready(); err != nil {
+		return nil, err
+	}
+	bands := lsh.maybeBands(sig)
+	if bands == nil {
+		bands = lsh.allBands()
+	}
+	ids := make([]int, 0)
+	if len(bands) == 0 {
+		return ids, nil
+	}
+	args := lsh.queryArgs(sig, bands, lsh.k)
+	cond := lsh.bandsCond(bands, lsh.k) + lsh.filterCond(len(bands)*lsh.bandWidth(lsh.k))
+	if after != nil {
+		cond += " AND id > " + lsh.dialect.varFmt(len(args))
+		args = append(args, *after)
+	}
+	rows, err := lsh.readDB().Query(fmt.Sprintf(
+		"SELECT DISTINCT id FROM %s WHERE %s ORDER BY id"+lsh.dialect.limitFmt,
+		lsh.tableName, cond, limit), args...)
+	if err != nil {
+		return nil, wrapErr("query", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, wrapErr("query", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapErr("query", err)
+	}
+	return ids, nil
+}
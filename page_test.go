@@ -0,0 +1,49 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_QueryPage(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Error(err)
+	}
+	sig := Signature{1, 2, 3, 4}
+	for i := 0; i < 25; i++ {
+		lsh.Insert(i*2, sig)
+	}
+	var all []int
+	var after *int
+	pages := 0
+	for {
+		ids, err := lsh.QueryPage(sig, after, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, ids...)
+		pages++
+		if len(ids) < 10 {
+			break
+		}
+		after = &ids[len(ids)-1]
+	}
+	if pages != 3 || len(all) != 25 {
+		t.Errorf("Expected 25 IDs in 3 pages, got %d in %d", len(all), pages)
+	}
+	for i, id := range all {
+		if id != i*2 {
+			t.Fatalf("Expected ID %d at position %d, got %d", i*2, i, id)
+		}
+	}
+	if _, err := lsh.QueryPage(sig, nil, 0); err != ErrInvalidParameter {
+		t.Error("Fail to raise error")
+	}
+	removeTempFile(t, f)
+}
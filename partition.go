@@ -0,0 +1,103 @@
+package sqllsh
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Partitioning is the way the table of a namespaced index is split
+// into partitions by namespace.
+type Partitioning int
+
+const (
+	// PartitionNone keeps all namespaces in one table.
+	PartitionNone Partitioning = iota
+	// PartitionList keeps each namespace in its own partition, which
+	// is created the first time the namespace is used, so that
+	// DropNamespace only drops the partition.
+	PartitionList
+	// PartitionHash spreads the namespaces over a fixed number of
+	// partitions, created with the table.
+	PartitionHash
+)
+
+// WithPartitions partitions the table by namespace, and implies
+// WithNamespaces.
+// Each partition has its own, smaller, indexes.
+// n is the number of partitions of PartitionHash, and is not used by
+// PartitionList.
+// Only PostgreSQL supports partitioned tables.
+func WithPartitions(p Partitioning, n int) Option {
+	return func(lsh *SqlLsh) {
+		lsh.namespaces = true
+		lsh.partitioning = p
+		lsh.partitions = n
+	}
+}
+
+// DropNamespace removes all the entries of the namespace ns.
+// With PartitionList, the partition of ns is dropped, which is much
+// faster than deleting its entries.
+// It requires the index to be created using WithNamespaces.
+func (lsh *SqlLsh) DropNamespace(ns int64) error {
+	if !lsh.namespaces {
+		return ErrUnsupported
+	}
+	query := fmt.Sprintf("DELETE FROM %s WHERE ns = %d", lsh.tableName, ns)
+	if lsh.partitioning == PartitionList {
+		query = "DROP TABLE IF EXISTS " + lsh.partitionName(ns)
+	}
+	if _, err := lsh.db.Exec(query); err != nil {
+		return wrapErr("drop namespace", err)
+	}
+	lsh.sharedCache().clear()
+	return nil
+}
+
+// partitionClause returns the PARTITION BY clause of the table.
+func (lsh *SqlLsh) partitionClause() string {
+	switch lsh.partitioning {
+	case PartitionList:
+		return " PARTITION BY LIST (ns)"
+	case PartitionHash:
+		return " PARTITION BY HASH (ns)"
+	}
+	return ""
+}
+
+// partitionStrs returns the statements creating the partitions that
+// exist with the table: the partition of namespace 0 used by the index
+// itself, or all the hash partitions.
+func (lsh *SqlLsh) partitionStrs() []string {
+	switch lsh.partitioning {
+	case PartitionList:
+		return []string{lsh.listPartitionStr(0)}
+	case PartitionHash:
+		stmts := make([]string, lsh.partitions)
+		for i := range stmts {
+			stmts[i] = fmt.Sprintf(
+				"CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES WITH (MODULUS %d, REMAINDER %d)",
+				lsh.hashPartitionName(i), lsh.tableName, lsh.partitions, i)
+		}
+		return stmts
+	}
+	return nil
+}
+
+// listPartitionStr returns the statement creating the partition of the
+// namespace ns.
+func (lsh *SqlLsh) listPartitionStr(ns int64) string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES IN (%d)",
+		lsh.partitionName(ns), lsh.tableName, ns)
+}
+
+// partitionName returns the name of the list partition of the
+// namespace ns.
+func (lsh *SqlLsh) partitionName(ns int64) string {
+	return lsh.tableName + "_ns" + strings.Replace(fmt.Sprint(ns), "-", "m", 1)
+}
+
+// hashPartitionName returns the name of the hash partition i.
+func (lsh *SqlLsh) hashPartitionName(i int) string {
+	return fmt.Sprintf("%s_p%d", lsh.tableName, i)
+}
@@ -0,0 +1,77 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func Test_PartitionStrs(t *testing.T) {
+	stmts := PostgresStatements(2, 2, "lshtable", WithPartitions(PartitionList, 0))
+	if !strings.HasSuffix(stmts.CreateTable, ") PARTITION BY LIST (ns)") {
+		t.Errorf("Expected a list partitioned table, got %s", stmts.CreateTable)
+	}
+	lsh := &SqlLsh{k: 2, l: 2, tableName: "lshtable", dialect: postgresDialect}
+	WithPartitions(PartitionHash, 4)(lsh)
+	if !lsh.namespaces {
+		t.Error("Expected WithPartitions to imply WithNamespaces")
+	}
+	parts := lsh.partitionStrs()
+	if len(parts) != 4 {
+		t.Fatalf("Expected 4 partitions, got %d", len(parts))
+	}
+	if parts[3] != "CREATE TABLE IF NOT EXISTS lshtable_p3 PARTITION OF lshtable FOR VALUES WITH (MODULUS 4, REMAINDER 3)" {
+		t.Errorf("Unexpected partition %s", parts[3])
+	}
+	if name := lsh.partitionName(-7); name != "lshtable_nsm7" {
+		t.Errorf("Unexpected partition name %s", name)
+	}
+}
+
+func Test_PartitionsUnsupported(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	_, err = NewSqliteLsh(2, 2, "lshtable", db, WithPartitions(PartitionList, 0))
+	if err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
+
+func Test_DropNamespace(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithNamespaces())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := Signature{1, 2, 3, 4}
+	for ns := int64(1); ns <= 2; ns++ {
+		view, err := lsh.Namespace(ns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := view.Insert(1, sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := lsh.DropNamespace(1); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := lsh.QueryIDs(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 {
+		t.Errorf("Expected the entry of namespace 2, got %v", ids)
+	}
+}
@@ -0,0 +1,135 @@
+package sqllsh
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// QueryPlan is the shape of the SQL query used to find the candidates.
+type QueryPlan int
+
+const (
+	// PlanAuto uses the plan that works best for the database.
+	PlanAuto QueryPlan = iota
+	// PlanOr uses a single SELECT with one condition per hash table,
+	// combined with OR.
+	PlanOr
+	// PlanUnion uses one SELECT per hash table, combined with UNION,
+	// for databases that do not use all the indexes for PlanOr.
+	PlanUnion
+	// PlanParallel runs the SELECT of each hash table concurrently, at
+	// most 8 at a time or as set by WithQueryParallelism, on as many
+	// connections as the database connection object allows, and merges
+	// the IDs found.
+	// It cuts the latency on databases with many cores, at the cost of
+	// l round trips per query.
+	// Methods reading the IDs lazily, such as QueryIter, use PlanUnion
+	// instead.
+	// It is not supported when the DB is a *sql.Tx or a *sql.Conn.
+	PlanParallel
+)
+
+// defaultParallelism is the number of concurrent queries of a
+// PlanParallel query, unless set by WithQueryParallelism.
+const defaultParallelism = 8
+
+// WithQueryParallelism sets the number of hash tables queried
+// concurrently by PlanParallel, each on its own connection, which is 8
+// by default.
+func WithQueryParallelism(n int) Option {
+	return func(lsh *SqlLsh) {
+		lsh.parallelism = n
+	}
+}
+
+// WithQueryPlan sets the shape of the SQL query used by Query and
+// QueryIDs, and the queries built on them.
+func WithQueryPlan(p QueryPlan) Option {
+	return func(lsh *SqlLsh) {
+		lsh.plan = p
+	}
+}
+
+// queryPlan returns the plan used for queries, resolving PlanAuto.
+func (lsh *SqlLsh) queryPlan() QueryPlan {
+	if lsh.plan == PlanAuto {
+		return lsh.dialect.plan
+	}
+	return lsh.plan
+}
+
+// unionQueryStr returns the PlanUnion query for the first prefix hash
+// values of the hash keys of bands.
+func (lsh *SqlLsh) unionQueryStr(bands []int, prefix int) string {
+	selects := lsh.bandSelects(bands, prefix, func(int) string {
+		return "id"
+	})
+	return strings.Join(selects, " UNION ")
+}
+
+// bandSelects returns one SELECT of the columns given by cols for each
+// band, matching the first prefix hash values of the hash key of the
+// band.
+// The arguments are given by bandsArgs.
+func (lsh *SqlLsh) bandSelects(bands []int, prefix int, cols func(band int) string) []string {
+	selects := make([]string, len(bands))
+	for i, band := range bands {
+		selects[i] = fmt.Sprintf("SELECT %s FROM %s WHERE %s%s", cols(band),
+			lsh.tableName, lsh.liveCond(), lsh.bandCond(band, prefix, lsh.bandWidth(prefix)*i))
+	}
+	return selects
+}
+
+// parallelQuery runs the query of each band of sig that may have
+// collisions, on at most lsh.parallelism goroutines, and returns the
+// distinct IDs found, in the order of the bands.
+func (lsh *SqlLsh) parallelQuery(sig Signature) ([]int, error) {
+	if len(sig) != lsh.k*lsh.l {
+		return nil, ErrSignatureSizeMismatch
+	}
+	if err := lsh.ready(); err != nil {
+		return nil, err
+	}
+	bands := lsh.maybeBands(sig)
+	if bands == nil {
+		bands = lsh.allBands()
+	}
+	found := make([][]int, len(bands))
+	errs := make([]error, len(bands))
+	n := lsh.parallelism
+	if n < 1 {
+		n = defaultParallelism
+	}
+	var next int64 = -1
+	var wg sync.WaitGroup
+	for w := 0; w < n && w < len(bands); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= len(bands) {
+					return
+				}
+				found[i], errs[i] = lsh.queryBands(sig, []int{bands[i]}, lsh.k)
+			}
+		}()
+	}
+	wg.Wait()
+	seen := make(map[int]bool)
+	ids := make([]int, 0)
+	for i := range bands {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		for _, id := range found[i] {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids, nil
+}
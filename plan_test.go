@@ -0,0 +1,109 @@
+package sqllsh
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_QueryPlan(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	sigs := randomSigs(100, 8)
+	for i := 50; i < 100; i++ {
+		copy(sigs[i][:2], sigs[i-50][:2])
+	}
+	var results [][]int
+	for _, p := range []QueryPlan{PlanOr, PlanUnion, PlanParallel} {
+		if _, err := db.Exec("DROP TABLE IF EXISTS lshtable;"); err != nil {
+			t.Fatal(err)
+		}
+		lsh, err := NewSqliteLsh(2, 4, "lshtable", db, WithQueryPlan(p), WithSoftDelete())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for id, sig := range sigs {
+			if err := lsh.Insert(id, sig); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := lsh.Delete(50); err != nil {
+			t.Fatal(err)
+		}
+		ids, err := lsh.QueryIDs(sigs[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Ints(ids)
+		results = append(results, ids)
+	}
+	if !reflect.DeepEqual(results[0], results[1]) {
+		t.Errorf("PlanOr found %v, PlanUnion found %v", results[0], results[1])
+	}
+	if len(results[1]) != 1 || results[1][0] != 0 {
+		t.Errorf("Expected [0], got %v", results[1])
+	}
+	removeTempFile(t, f)
+}
+
+// concurrentDB records the largest number of queries running at once.
+type concurrentDB struct {
+	*sql.DB
+	mu      sync.Mutex
+	running int
+	max     int
+}
+
+func (db *concurrentDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	db.mu.Lock()
+	db.running++
+	if db.running > db.max {
+		db.max = db.running
+	}
+	db.mu.Unlock()
+	defer func() {
+		db.mu.Lock()
+		db.running--
+		db.mu.Unlock()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	return db.DB.QueryContext(ctx, query, args...)
+}
+
+func Test_QueryParallelism(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	sqlDB, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+	db := &concurrentDB{DB: sqlDB}
+	lsh, err := NewSqliteLsh(1, 8, "lshtable", db, WithQueryPlan(PlanParallel),
+		WithQueryParallelism(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := randomSigs(1, 8)[0]
+	if err := lsh.Insert(1, sig); err != nil {
+		t.Fatal(err)
+	}
+	db.max = 0
+	ids, err := lsh.QueryIDs(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int{1}) {
+		t.Errorf("Expected [1], got %v", ids)
+	}
+	if db.max < 2 || db.max > 3 {
+		t.Errorf("Expected 2 or 3 concurrent queries, got %d", db.max)
+	}
+}
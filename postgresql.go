@@ -5,14 +5,57 @@ import (
 	"fmt"
 )
 
+var postgresDialect = dialect{
+	varFmt: func(i int) string {
+		return fmt.Sprintf("$%d", i+1)
+	},
+	intType:        "BIGINT",
+	blobType:       "BYTEA",
+	limitFmt:       " LIMIT %d",
+	createIndexFmt: "CREATE INDEX %s ON %s",
+	dropIndexFmt:   "DROP INDEX %[1]s",
+	indexMethods: map[IndexType]string{
+		IndexBTree: " USING BTREE",
+		IndexHash:  " USING HASH",
+	},
+	tableKinds: map[TableKind]string{
+		TableUnlogged:  "UNLOGGED ",
+		TableTemporary: "TEMPORARY ",
+	},
+	reindexFmt:     "REINDEX TABLE %s",
+	conflictClause: onConflictClause,
+	analyzeFmt:     "ANALYZE %s",
+	explainPrefix:  "EXPLAIN ",
+	autoIDType:     "INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+	returning:      true,
+	plan:           PlanUnion,
+	includeClause:  " INCLUDE (id)",
+	partitions:     true,
+	indexesQuery:   "SELECT indexname FROM pg_indexes WHERE tablename = $1",
+	tablesQuery:    "SELECT tablename FROM pg_tables WHERE schemaname = current_schema()",
+	maxParams:      65535,
+	maxIdent:       63,
+	timeoutFmt:     "SET LOCAL statement_timeout = %d",
+	notifyFmt:      "SELECT pg_notify(%s, %s)",
+	serializable:   true,
+	snapshot:       sql.LevelRepeatableRead,
+	setNotNullFmt:  "ALTER TABLE %s ALTER COLUMN %s SET NOT NULL",
+	restartIDFmt:   "SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM %[1]s",
+	variants: map[PostgresVariant]func(dialect) dialect{
+		PostgresYugabyte: yugabyteDialect,
+	},
+}
+
 // NewPostgresLsh creates a new PostgreSQL-backed LSH index.
 // The caller is responsible for closing the database connection
 // object.
-func NewPostgresLsh(k, l int, tableName string, db *sql.DB) (*SqlLsh, error) {
-	varFmt := func(i int) string {
-		return fmt.Sprintf("$%d", i+1)
-	}
-	createIndexFmt := "CREATE INDEX ht_%d ON %s USING BTREE ("
-	lsh, err := newSqlLsh(k, l, tableName, db, varFmt, createIndexFmt)
+func NewPostgresLsh(k, l int, tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	lsh, err := newSqlLsh(k, l, tableName, db, postgresDialect, opts)
 	return lsh, err
 }
+
+// OpenPostgresLsh opens an existing PostgreSQL-backed LSH index with its
+// recorded parameters, like OpenSqliteLsh.
+func OpenPostgresLsh(tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	return openSqlLsh(tableName, db, postgresDialect, opts)
+}
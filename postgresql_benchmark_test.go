@@ -14,7 +14,7 @@ func conn() (*sql.DB, error) {
 	return sql.Open("postgres", "")
 }
 
-func runPostgres(k, l, n, nq int, b *testing.B) {
+func runPostgres(k, l, n, nq int, b *testing.B, opts ...Option) {
 	// Initialize database
 	db, err := conn()
 	if err != nil {
@@ -26,7 +26,7 @@ func runPostgres(k, l, n, nq int, b *testing.B) {
 	}
 
 	// Initialize data
-	lsh, err := NewPostgresLsh(k, l, "lshtable", db)
+	lsh, err := NewPostgresLsh(k, l, "lshtable", db, opts...)
 	if err != nil {
 		b.Fatal(err)
 	}
@@ -92,3 +92,11 @@ func BenchmarkPostgresLsh256(b *testing.B) {
 func BenchmarkPostgresLsh512(b *testing.B) {
 	runPostgres(8, 64, 10000, 100, b)
 }
+
+func BenchmarkPostgresLsh256Or(b *testing.B) {
+	runPostgres(4, 64, 10000, 100, b, WithQueryPlan(PlanOr))
+}
+
+func BenchmarkPostgresLsh256Union(b *testing.B) {
+	runPostgres(4, 64, 10000, 100, b, WithQueryPlan(PlanUnion))
+}
@@ -0,0 +1,124 @@
+//go:build postgres
+
+package sqllsh
+
+import (
+	"database/sql"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Test_PostgresNotify runs against the PostgreSQL database given by the
+// connection string in SQLLSH_POSTGRES_DSN, such as
+// "postgres://postgres@127.0.0.1:5432/postgres?sslmode=disable".
+func Test_PostgresNotify(t *testing.T) {
+	dsn := os.Getenv("SQLLSH_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("SQLLSH_POSTGRES_DSN is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, table := range []string{"lshtable", "lshtable_meta"} {
+		if _, err := db.Exec("DROP TABLE IF EXISTS " + table); err != nil {
+			t.Fatal(err)
+		}
+	}
+	listener := pq.NewListener(dsn, time.Second, time.Second, nil)
+	defer listener.Close()
+	if err := listener.Listen("lshinserts"); err != nil {
+		t.Fatal(err)
+	}
+	lsh, err := NewPostgresLsh(2, 2, "lshtable", db, WithNotify("lshinserts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Insert(1, Signature{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.BatchInsert([]int{2, 3}, []Signature{{1, 2, 5, 6}, {7, 8, 9, 10}}); err != nil {
+		t.Fatal(err)
+	}
+	// A failed insert rolls its notification back
+	if err := lsh.Insert(1, Signature{1, 2, 3, 4}); err == nil {
+		t.Fatal("Expected ErrIDExists")
+	}
+	var ids []int
+	timeout := time.After(10 * time.Second)
+	for len(ids) < 3 {
+		select {
+		case n := <-listener.Notify:
+			if n == nil {
+				continue
+			}
+			for _, s := range strings.Split(n.Extra, ",") {
+				id, err := strconv.Atoi(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ids = append(ids, id)
+			}
+		case <-timeout:
+			t.Fatalf("Expected 3 notified IDs, got %v", ids)
+		}
+	}
+	select {
+	case n := <-listener.Notify:
+		if n != nil {
+			t.Errorf("Unexpected notification %q", n.Extra)
+		}
+	case <-time.After(time.Second):
+	}
+	sort.Ints(ids)
+	if len(ids) != 3 || ids[0] != 1 || ids[2] != 3 {
+		t.Errorf("Expected IDs [1 2 3], got %v", ids)
+	}
+}
+
+// Test_PostgresIntValue converts the integer, NUMERIC and DOUBLE
+// PRECISION values returned by the driver, in the database given by
+// SQLLSH_POSTGRES_DSN.
+func Test_PostgresIntValue(t *testing.T) {
+	dsn := os.Getenv("SQLLSH_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("SQLLSH_POSTGRES_DSN is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, c := range []struct {
+		expr     string
+		expected uint64
+	}{
+		{"CAST(-3 AS BIGINT)", uint64(1<<64 - 3)},
+		{"CAST(-3 AS NUMERIC)", uint64(1<<64 - 3)},
+		{"CAST(9223372036854775808 AS NUMERIC)", 1 << 63},
+		{"CAST(-3 AS DOUBLE PRECISION)", uint64(1<<64 - 3)},
+		{"CAST(1e19 AS DOUBLE PRECISION)", 1e19},
+	} {
+		var v interface{}
+		if err := db.QueryRow("SELECT " + c.expr).Scan(&v); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := intValue(v); err != nil || uint64(n) != c.expected {
+			t.Errorf("Expected %d for %s, got %d, %v", c.expected, c.expr, uint64(n), err)
+		}
+	}
+	var v interface{}
+	if err := db.QueryRow("SELECT CAST(1e20 AS DOUBLE PRECISION)").Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := intValue(v); err == nil {
+		t.Errorf("Expected an error for 1e20, got %d", n)
+	}
+}
@@ -0,0 +1,39 @@
+package sqllsh
+
+import "time"
+
+// progressInterval is the number of rows between two progress reports
+// of an insert.
+const progressInterval = 1000
+
+// Progress describes how far a long running operation has gone.
+type Progress struct {
+	Op      string        // "batch insert", "bulk load" or "index"
+	Done    int           // Number of rows inserted or indexes built
+	Total   int           // Total number of rows or indexes
+	Elapsed time.Duration // Time since the operation started
+}
+
+// WithProgress calls fn to report the progress of BatchInsert, every
+// 1000 rows and once all rows are committed, of BulkLoad, after each
+// batch is committed, and of Index, after each index is built.
+// fn is called from the goroutine running the operation, so it should
+// return quickly.
+func WithProgress(fn func(Progress)) Option {
+	return func(lsh *SqlLsh) {
+		lsh.progress = fn
+	}
+}
+
+// report calls the progress function, if there is one.
+func (lsh *SqlLsh) report(op string, done, total int, start time.Time) {
+	if lsh.progress == nil {
+		return
+	}
+	lsh.progress(Progress{
+		Op:      op,
+		Done:    done,
+		Total:   total,
+		Elapsed: time.Since(start),
+	})
+}
@@ -0,0 +1,49 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_Progress(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	var reports []Progress
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithProgress(func(p Progress) {
+		reports = append(reports, p)
+	}))
+	if err != nil {
+		t.Error(err)
+	}
+	sigs := randomSigs(2500, 4)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []Progress{
+		{Op: "batch insert", Done: 1000, Total: 2500},
+		{Op: "batch insert", Done: 2000, Total: 2500},
+		{Op: "batch insert", Done: 2500, Total: 2500},
+		{Op: "index", Done: 1, Total: 2},
+		{Op: "index", Done: 2, Total: 2},
+	}
+	if len(reports) != len(expected) {
+		t.Fatalf("Expected %d reports, got %d", len(expected), len(reports))
+	}
+	for i := range reports {
+		reports[i].Elapsed = 0
+		if reports[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected[i], reports[i])
+		}
+	}
+	removeTempFile(t, f)
+}
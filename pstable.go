@@ -0,0 +1,107 @@
+package sqllsh
+
+import (
+	"math"
+	"sort"
+)
+
+// WithPStable lets the index take the float Signatures of p-stable LSH
+// for Euclidean distance (E2LSH), the projections of the vectors on
+// random Gaussian directions, putting each one into the bucket
+// floor((v + offset) / w) with BucketQuantizer.
+// A larger bucket width w finds more distant neighbors, at the cost of
+// more candidates.
+// Besides InsertFloat and QueryFloat, QueryFloatProbe also probes the
+// neighboring buckets, which gives the recall of more hash tables
+// without storing them.
+func WithPStable(w, offset float64) Option {
+	return func(lsh *SqlLsh) {
+		lsh.quantizer = BucketQuantizer(w, offset)
+		lsh.buckets = &buckets{w: w, offset: offset}
+	}
+}
+
+// buckets is the bucket width and offset of WithPStable.
+type buckets struct {
+	w, offset float64
+}
+
+// probe is a hash value of a float Signature moved to the neighboring
+// bucket, at the given distance from the bucket boundary in bucket
+// widths.
+type probe struct {
+	i        int
+	bucket   int64
+	distance float64
+}
+
+// QueryFloatProbe is like QueryFloat, but also probes up to probes
+// neighboring buckets, as in multi-probe LSH: each probe moves one
+// projection of fsig to the bucket on the other side of its closest
+// bucket boundary, the projections closest to a boundary first, and
+// queries the hash table of that projection with the moved hash key.
+// It requires the index to be created using WithPStable.
+func (lsh *SqlLsh) QueryFloatProbe(fsig []float64, probes int) ([]int, error) {
+	if lsh.buckets == nil {
+		return nil, ErrUnsupported
+	}
+	if probes < 0 {
+		return nil, ErrInvalidParameter
+	}
+	sig, err := lsh.Quantize(fsig)
+	if err != nil {
+		return nil, err
+	}
+	// The hooks run once, and the probes move the Signature they return
+	sig, err = lsh.beforeQuery("query probe", sig)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0)
+	if err := lsh.runQuery(sig, func(id int) {
+		ids = append(ids, id)
+	}); err != nil {
+		return nil, err
+	}
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+	for _, p := range lsh.buckets.probes(fsig, probes) {
+		moved := append(Signature{}, sig...)
+		moved[p.i] = zigzag(p.bucket)
+		found, err := lsh.queryBands(moved, []int{p.i / lsh.k}, lsh.k)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range found {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// probes returns the n moves of the projections of fsig to their
+// neighboring buckets that are the closest to a bucket boundary.
+func (b *buckets) probes(fsig []float64, n int) []probe {
+	all := make([]probe, len(fsig))
+	for i, v := range fsig {
+		pos := (v + b.offset) / b.w
+		bucket := math.Floor(pos)
+		if frac := pos - bucket; frac < 0.5 {
+			all[i] = probe{i: i, bucket: int64(bucket) - 1, distance: frac}
+		} else {
+			all[i] = probe{i: i, bucket: int64(bucket) + 1, distance: 1 - frac}
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].distance < all[j].distance
+	})
+	if n < len(all) {
+		all = all[:n]
+	}
+	return all
+}
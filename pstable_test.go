@@ -0,0 +1,45 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_QueryFloatProbe(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithPStable(1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.InsertFloat(1, []float64{0.1, 0.1, 0.1, 0.1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.InsertFloat(2, []float64{5, 5, 5, 5}); err != nil {
+		t.Fatal(err)
+	}
+	// Just across the lower boundary of the buckets of 1
+	fsig := []float64{-0.05, 0.1, -0.05, 0.1}
+	found, err := lsh.QueryFloatProbe(fsig, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 0 {
+		t.Errorf("Expected no IDs without probes, got %v", found)
+	}
+	found, err = lsh.QueryFloatProbe(fsig, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0] != 1 {
+		t.Errorf("Expected [1], got %v", found)
+	}
+	if _, err := lsh.QueryFloatProbe(fsig, -1); err != ErrInvalidParameter {
+		t.Errorf("Expected ErrInvalidParameter, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package sqllsh
+
+import "math"
+
+// Quantizer maps a float hash value, such as a p-stable projection,
+// to the integer hash value stored in the index.
+type Quantizer func(v float64) uint
+
+// BucketQuantizer returns the Quantizer of p-stable LSH, which puts v
+// into the bucket floor((v + offset) / w).
+// Negative buckets are mapped to odd numbers and the others to even
+// numbers, so that all the hash values are small integers that every
+// database can store.
+func BucketQuantizer(w, offset float64) Quantizer {
+	return func(v float64) uint {
+		return zigzag(int64(math.Floor((v + offset) / w)))
+	}
+}
+
+// zigzag maps the negative buckets to odd numbers and the others to
+// even numbers.
+func zigzag(b int64) uint {
+	return uint((b << 1) ^ (b >> 63))
+}
+
+// WithQuantizer lets the index take float Signatures, using q to
+// compute the stored hash values, see InsertFloat and QueryFloat.
+func WithQuantizer(q Quantizer) Option {
+	return func(lsh *SqlLsh) {
+		lsh.quantizer = q
+	}
+}
+
+// Quantize returns the Signature of the float Signature fsig.
+// It requires the index to be created using WithQuantizer.
+func (lsh *SqlLsh) Quantize(fsig []float64) (Signature, error) {
+	if lsh.quantizer == nil {
+		return nil, ErrUnsupported
+	}
+	sig := make(Signature, len(fsig))
+	for i, v := range fsig {
+		sig[i] = lsh.quantizer(v)
+	}
+	return sig, nil
+}
+
+// InsertFloat is like Insert, but takes a float Signature.
+// It requires the index to be created using WithQuantizer.
+func (lsh *SqlLsh) InsertFloat(id int, fsig []float64) error {
+	sig, err := lsh.Quantize(fsig)
+	if err != nil {
+		return err
+	}
+	return lsh.Insert(id, sig)
+}
+
+// QueryFloat is like Query, but takes a float Signature.
+// It requires the index to be created using WithQuantizer.
+// The caller is responsible for closing the channel.
+func (lsh *SqlLsh) QueryFloat(fsig []float64, out chan int) error {
+	sig, err := lsh.Quantize(fsig)
+	if err != nil {
+		return err
+	}
+	return lsh.Query(sig, out)
+}
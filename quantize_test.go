@@ -0,0 +1,56 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_BucketQuantizer(t *testing.T) {
+	q := BucketQuantizer(2, 0.5)
+	for _, c := range []struct {
+		v    float64
+		want uint
+	}{
+		{0, 0},
+		{1.4, 0},
+		{1.6, 2},
+		{-0.4, 0},
+		{-0.6, 1},
+		{-2.6, 3},
+	} {
+		if got := q(c.v); got != c.want {
+			t.Errorf("Expected %d for %v, got %d", c.want, c.v, got)
+		}
+	}
+}
+
+func Test_QueryFloat(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithQuantizer(BucketQuantizer(1, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.InsertFloat(1, []float64{-3.2, 0.5, 7.9, 1e6}); err != nil {
+		t.Fatal(err)
+	}
+	out := make(chan int, 1)
+	if err := lsh.QueryFloat([]float64{-3.9, 0.1, 0, 0}, out); err != nil {
+		t.Fatal(err)
+	}
+	if id := <-out; id != 1 {
+		t.Errorf("Expected 1, got %d", id)
+	}
+	plain, err := NewSqliteLsh(2, 2, "plaintable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plain.Quantize([]float64{1, 2, 3, 4}); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
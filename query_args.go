@@ -0,0 +1,11 @@
+package sqllsh
+
+// QueryArgs returns the arguments of the Query statement given by
+// Statements for sig, for running the query through another interface
+// than database/sql.
+func (lsh *SqlLsh) QueryArgs(sig Signature) ([]interface{}, error) {
+	if len(sig) != lsh.k*lsh.l {
+		return nil, ErrSignatureSizeMismatch
+	}
+	return lsh.queryArgs(sig, lsh.allBands(), lsh.k), nil
+}
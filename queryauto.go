@@ -0,0 +1,74 @@
+package sqllsh
+
+import "sort"
+
+// QueryAuto finds the IDs of about target Signatures colliding with
+// sig, so that the batches of candidates passed to the next steps of an
+// application have a stable size whatever the density of the data
+// around sig.
+// If the full hash keys of all the hash tables find more than target
+// candidates, it only uses the fewest hash tables, in order, that find
+// at least target; if they find fewer, it shortens the prefix of the
+// hash keys as QueryAtLeast does, until at least target are found or
+// the prefix is a single hash value.
+// The candidates are counted before being fetched, with a number of
+// counting queries logarithmic in l, so only the returned IDs are sent
+// to the application.
+// With WithCompactLayout, WithBandKeys and WithBBit, the prefix cannot
+// be shortened, so fewer than target IDs can be found.
+func (lsh *SqlLsh) QueryAuto(sig Signature, target int) ([]int, error) {
+	if target < 1 {
+		return nil, ErrInvalidParameter
+	}
+	sig, err := lsh.beforeQuery("query auto", sig)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != lsh.k*lsh.l {
+		return nil, ErrSignatureSizeMismatch
+	}
+	if err := lsh.ready(); err != nil {
+		return nil, err
+	}
+	bands := lsh.maybeBands(sig)
+	if bands == nil {
+		bands = lsh.allBands()
+	}
+	if len(bands) == 0 {
+		return make([]int, 0), nil
+	}
+	n, err := lsh.countBands(nil, sig, bands, lsh.k)
+	if err != nil {
+		return nil, err
+	}
+	if n > int64(target) {
+		// The number of candidates grows with the number of hash tables,
+		// so the fewest reaching target are found by bisection
+		var countErr error
+		m := sort.Search(len(bands)-1, func(i int) bool {
+			if countErr != nil {
+				return true
+			}
+			n, err := lsh.countBands(nil, sig, bands[:i+1], lsh.k)
+			if err != nil {
+				countErr = err
+			}
+			return n >= int64(target)
+		})
+		if countErr != nil {
+			return nil, countErr
+		}
+		return lsh.queryBands(sig, bands[:m+1], lsh.k)
+	}
+	prefix := lsh.k
+	if !lsh.fullKeys() {
+		for n < int64(target) && prefix > 1 {
+			prefix--
+			n, err = lsh.countBands(nil, sig, bands, prefix)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return lsh.queryBands(sig, bands, prefix)
+}
@@ -0,0 +1,45 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_QueryAuto(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	sigs := []Signature{
+		{1, 2, 10, 10}, {1, 2, 11, 11}, {1, 2, 12, 12}, {1, 2, 13, 13},
+		{20, 20, 3, 4}, {21, 21, 3, 4},
+		{1, 5, 30, 30}, {1, 6, 31, 31},
+	}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	query := Signature{1, 2, 3, 4}
+	for _, c := range []struct{ target, expected int }{{3, 4}, {5, 6}, {6, 6}, {7, 8}, {100, 8}} {
+		found, err := lsh.QueryAuto(query, c.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(found) != c.expected {
+			t.Errorf("Expected %d IDs for target %d, got %v", c.expected, c.target, found)
+		}
+	}
+	if _, err := lsh.QueryAuto(query, 0); err != ErrInvalidParameter {
+		t.Errorf("Expected ErrInvalidParameter, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// QueryJoin finds the IDs of the Signatures colliding with sig, as
+// QueryIDs does, and runs the query joinSQL on them in the same round
+// trip, so that applications can fetch the columns of their own tables
+// for the candidates, such as the titles of documents.
+// The distinct IDs are the id column of the table candidates, defined
+// with WITH before joinSQL, for example:
+//
+//	SELECT d.id, d.title FROM candidates c JOIN docs d ON d.id = c.id WHERE d.lang = ?
+//
+// The arguments of joinSQL are given by args, with ? placeholders,
+// which are numbered for the databases using $1 or :1 placeholders.
+// joinSQL is written in the SQL as is, so it must not come from
+// untrusted input.
+// The caller must close the returned rows.
+// The query cache and WithQueryTimeout are not used.
+func (lsh *SqlLsh) QueryJoin(sig Signature, joinSQL string, args ...interface{}) (*sql.Rows, error) {
+	sig, err := lsh.beforeQuery("query join", sig)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != lsh.k*lsh.l {
+		return nil, ErrSignatureSizeMismatch
+	}
+	if err := lsh.ready(); err != nil {
+		return nil, err
+	}
+	bands := lsh.maybeBands(sig)
+	if bands == nil {
+		bands = lsh.allBands()
+	}
+	candidates := fmt.Sprintf("SELECT id FROM %s WHERE 1 = 0", lsh.tableName)
+	var queryArgs []interface{}
+	if len(bands) > 0 {
+		candidates = lsh.bandsQueryStr(bands, lsh.k)
+		queryArgs = lsh.queryArgs(sig, bands, lsh.k)
+	}
+	query := fmt.Sprintf("WITH candidates AS (%s) %s", candidates,
+		lsh.numberVars(joinSQL, len(queryArgs)))
+	rows, err := lsh.readDB().Query(query, append(queryArgs, args...)...)
+	if err != nil {
+		return nil, wrapErr("query join", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,56 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_QueryJoin(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE TABLE docs (id INTEGER PRIMARY KEY, title TEXT, lang TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	docs := []struct{ title, lang string }{{"a", "en"}, {"b", "fr"}, {"c", "en"}}
+	for i, d := range docs {
+		if _, err := db.Exec("INSERT INTO docs VALUES (?, ?, ?)", i, d.title, d.lang); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := lsh.BatchInsert([]int{0, 1, 2}, []Signature{{1, 2, 3, 4}, {1, 2, 5, 6}, {7, 8, 9, 10}}); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := lsh.QueryJoin(Signature{1, 2, 0, 0},
+		"SELECT d.id, d.title FROM candidates c JOIN docs d ON d.id = c.id WHERE d.lang = ? ORDER BY d.id", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var titles []string
+	for rows.Next() {
+		var id int
+		var title string
+		if err := rows.Scan(&id, &title); err != nil {
+			t.Fatal(err)
+		}
+		titles = append(titles, title)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(titles) != 1 || titles[0] != "a" {
+		t.Errorf("Expected [a], got %v", titles)
+	}
+	if _, err := lsh.QueryJoin(Signature{1}, "SELECT id FROM candidates"); err != ErrSignatureSizeMismatch {
+		t.Errorf("Expected ErrSignatureSizeMismatch, got %v", err)
+	}
+}
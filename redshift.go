@@ -0,0 +1,71 @@
+package sqllsh
+
+import (
+	"fmt"
+	"strings"
+)
+
+// redshiftMaxSortKey is the number of columns of an interleaved sort
+// key allowed by Redshift.
+const redshiftMaxSortKey = 8
+
+var redshiftDialect = dialect{
+	varFmt:        postgresDialect.varFmt,
+	intType:       "BIGINT",
+	blobType:      "VARBYTE",
+	limitFmt:      " LIMIT %d",
+	indexMethods:  map[IndexType]string{IndexBTree: ""},
+	sortFmt:       "VACUUM SORT ONLY %s",
+	analyzeFmt:    "ANALYZE %s",
+	explainPrefix: "EXPLAIN ",
+	tablesQuery:   postgresDialect.tablesQuery,
+	plan:          PlanOr,
+	tableOptions:  redshiftTableOptions,
+	// SORT ONLY does not re-sort an interleaved sort key
+	interleavedSortFmt: "VACUUM REINDEX %s",
+}
+
+// NewRedshiftLsh creates a new Amazon Redshift-backed LSH index, which
+// uses the PostgreSQL protocol, for batch near-duplicate detection
+// where the data already is.
+// Redshift has no secondary indexes, so the table is distributed on
+// the first hash value of the first hash table, and sorted with an
+// interleaved sort key on the first hash value of up to 8 hash tables;
+// Index sorts the rows loaded since the last sort, with VACUUM REINDEX
+// for an interleaved sort key, which takes longer as it analyzes the
+// distribution of the values first, and Reindex sorts them again.
+// Redshift does not enforce primary keys, so inserting an existing ID
+// adds a second row instead of failing with ErrIDExists, and only
+// ConflictError is supported.
+// Inserting rows one by one is slow on Redshift: BatchInsert should be
+// used with a BatchWriter loading the rows from Amazon S3 with COPY.
+// The caller is responsible for closing the database connection
+// object.
+func NewRedshiftLsh(k, l int, tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	lsh, err := newSqlLsh(k, l, tableName, db, redshiftDialect, opts)
+	return lsh, err
+}
+
+// OpenRedshiftLsh opens an existing Amazon Redshift-backed LSH index
+// with its recorded parameters, like OpenSqliteLsh.
+func OpenRedshiftLsh(tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	return openSqlLsh(tableName, db, redshiftDialect, opts)
+}
+
+// redshiftTableOptions returns the distribution and sort keys of the
+// table, on the first column of the hash keys.
+func redshiftTableOptions(lsh *SqlLsh) string {
+	n := lsh.l
+	if n > redshiftMaxSortKey {
+		n = redshiftMaxSortKey
+	}
+	cols := make([]string, n)
+	for i := range cols {
+		cols[i] = lsh.bandCols(i)[0]
+	}
+	if n == 1 {
+		return fmt.Sprintf(" DISTKEY (%s) SORTKEY (%s)", cols[0], cols[0])
+	}
+	return fmt.Sprintf(" DISTKEY (%s) INTERLEAVED SORTKEY (%s)", cols[0],
+		strings.Join(cols, ", "))
+}
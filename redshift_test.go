@@ -0,0 +1,47 @@
+package sqllsh
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_RedshiftStatements(t *testing.T) {
+	lsh := &SqlLsh{k: 2, l: 3, tableName: "lshtable", dialect: redshiftDialect}
+	expected := ") DISTKEY (hv_0) INTERLEAVED SORTKEY (hv_0, hv_2, hv_4)"
+	if s := lsh.createTableStr(); !strings.HasSuffix(s, expected) {
+		t.Errorf("Expected %q, got %q", expected, s)
+	}
+	lsh.l = 1
+	expected = ") DISTKEY (hv_0) SORTKEY (hv_0)"
+	if s := lsh.createTableStr(); !strings.HasSuffix(s, expected) {
+		t.Errorf("Expected %q, got %q", expected, s)
+	}
+	lsh.l = 20
+	WithCompactLayout()(lsh)
+	if s := redshiftTableOptions(lsh); strings.Count(s, "key_") != 9 {
+		t.Errorf("Expected 8 sort key columns, got %q", s)
+	}
+	if err := lsh.DropIndexes(); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_RedshiftSort(t *testing.T) {
+	for _, c := range []struct {
+		l        int
+		expected string
+	}{{1, "VACUUM SORT ONLY lshtable"}, {3, "VACUUM REINDEX lshtable"}} {
+		rec := NewRecorder()
+		lsh, err := NewRedshiftLsh(2, c.l, "lshtable", rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec.Reset()
+		if err := lsh.Index(); err != nil {
+			t.Fatal(err)
+		}
+		if s := rec.Statements(); len(s) == 0 || s[0].Query != c.expected {
+			t.Errorf("Expected %q for l = %d, got %v", c.expected, c.l, s)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"sync/atomic"
+)
+
+// WithReadReplicas sends the read-only queries, such as Query, Scan and
+// SimilarityJoin, to the given read replicas in turn, instead of the
+// database connection object given to the constructor, which is then
+// only used for creating the table, writing, and building indexes.
+// The replicas must have the table, so they need to be in sync with
+// the primary database before querying.
+// The caller is responsible for closing the replicas.
+func WithReadReplicas(dbs ...*sql.DB) Option {
+	return func(lsh *SqlLsh) {
+		lsh.replicas = make([]dbConn, len(dbs))
+		for i, db := range dbs {
+			lsh.replicas[i] = dbConn{DB: db}
+		}
+	}
+}
+
+// readDB returns the database connection object for the next read-only
+// query.
+func (lsh *SqlLsh) readDB() dbConn {
+	if len(lsh.replicas) == 0 {
+		return lsh.db
+	}
+	i := atomic.AddUint32(&lsh.next, 1)
+	return lsh.replicas[int(i%uint32(len(lsh.replicas)))]
+}
+
+// read runs a read-only query.
+// Without read replicas, the prepared statement stmt is used unless it
+// is nil, otherwise the query string is run on the next replica.
+func (lsh *SqlLsh) read(stmt *sql.Stmt, query string, args ...interface{}) (*sql.Rows, error) {
+	if len(lsh.replicas) == 0 && stmt != nil {
+		return stmt.Query(args...)
+	}
+	query, args = lsh.inline(query, args)
+	return lsh.readDB().Query(query, args...)
+}
@@ -0,0 +1,47 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_ReadReplicas(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	replicas := make([]*sql.DB, 2)
+	for i := range replicas {
+		replicas[i], err = sql.Open("sqlite3", f.Name())
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithReadReplicas(replicas...))
+	if err != nil {
+		t.Error(err)
+	}
+	sigs := randomSigs(10, 4)
+	for i := range sigs {
+		lsh.Insert(i, sigs[i])
+	}
+	for i := range sigs {
+		ids, err := lsh.QueryIDs(sigs[i])
+		if err != nil {
+			t.Error(err)
+		}
+		if len(ids) != 1 || ids[0] != i {
+			t.Error("Error in query")
+		}
+	}
+	// Closing the primary must not affect queries
+	db.Close()
+	if _, err := lsh.QueryIDs(sigs[0]); err != nil {
+		t.Error(err)
+	}
+	if err := lsh.Insert(len(sigs), sigs[0]); err == nil {
+		t.Error("Insert should use the closed primary")
+	}
+	removeTempFile(t, f)
+}
@@ -0,0 +1,212 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// reshapePageSize is the number of rows Reshape reads at a time when
+// rehashing.
+const reshapePageSize = 10000
+
+// Reshape changes k and l of the index.
+// A new table is created with the new parameters and filled from the
+// existing one, then the new table replaces the existing one in the
+// same transaction, so the index can still be used during the reshape.
+// If rehash is nil, k*l must not change, and the stored hash values
+// are only grouped into hash keys differently.
+// Otherwise rehash is called with every entry to get its Signature of
+// size k*l under the new parameters; in that case deleted entries are
+// not copied and insertion times are reset.
+// The indexes are built once the new table is in place.
+// With WithAutoID, the ids generated afterwards follow the existing
+// ones.
+// Reshape must not be called concurrently with other methods, and
+// every other SqlLsh using the same table must be created again with
+// the new parameters afterwards.
+// It is not supported on MySQL, MariaDB, TiDB, Vitess, Oracle and
+// Snowflake, which commit the transaction when creating and renaming the
+// tables, nor when users have added their own columns to the table, as
+// the new table only has the columns of the index.
+func (lsh *SqlLsh) Reshape(k, l int, rehash func(id int, sig Signature) Signature) error {
+	if k < 1 || l < 1 || (rehash == nil && k*l != lsh.k*lsh.l) {
+		return ErrInvalidParameter
+	}
+	if lsh.dialect.ddl != nil || lsh.dialect.implicitCommit || lsh.namespaces || lsh.ensemble != nil || lsh.fullKeys() {
+		return ErrUnsupported
+	}
+	if err := lsh.checkOwnColumns(); err != nil {
+		return err
+	}
+	next := &SqlLsh{
+		k:          k,
+		l:          l,
+		tableName:  lsh.tableName + "_reshape",
+		db:         lsh.db,
+		dialect:    lsh.dialect,
+		softDelete: lsh.softDelete,
+		insertTime: lsh.insertTime,
+		conflict:   lsh.conflict,
+		tableKind:  lsh.tableKind,
+		sqlite:     lsh.sqlite,
+		autoID:     lsh.autoID,
+	}
+	old := lsh.tableName + "_reshape_old"
+	tx, err := lsh.db.Begin()
+	if err != nil {
+		return wrapErr("reshape", err)
+	}
+	stmts := []string{
+		fmt.Sprintf("DROP TABLE IF EXISTS %s", next.tableName),
+		next.createTableStr(),
+	}
+	if rehash == nil {
+		cols := lsh.columnList()
+		if lsh.softDelete {
+			cols += ",deleted"
+		}
+		if lsh.insertTime {
+			cols += ",inserted_at"
+		}
+		stmts = append(stmts, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s",
+			next.tableName, cols, cols, lsh.tableName))
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			tx.Rollback()
+			return wrapErr("reshape", err)
+		}
+	}
+	if rehash != nil {
+		if err := lsh.rehashInto(next, tx.Tx, rehash); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if lsh.autoID && lsh.dialect.restartIDFmt != "" {
+		// The ids generated for the new table follow the copied ones
+		if _, err := tx.Exec(fmt.Sprintf(lsh.dialect.restartIDFmt, next.tableName)); err != nil {
+			tx.Rollback()
+			return wrapErr("reshape", err)
+		}
+	}
+	stmts = []string{
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", lsh.tableName, old),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", next.tableName, lsh.tableName),
+		fmt.Sprintf("DROP TABLE %s", old),
+	}
+	if _, err := tx.Exec(lsh.updateMetaStr(), k, l); err != nil {
+		tx.Rollback()
+		return wrapErr("reshape", err)
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			tx.Rollback()
+			return wrapErr("reshape", err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return wrapErr("reshape", err)
+	}
+	lsh.closeStmts()
+	lsh.k, lsh.l = k, l
+	if err := lsh.prepare(); err != nil {
+		return err
+	}
+	lsh.cache.clear()
+	if lsh.bloom != nil {
+		lsh.bloom = newBandBloom(lsh.l, lsh.bloom.n, lsh.bloom.fpRate)
+		if err := lsh.loadBloom(); err != nil {
+			return err
+		}
+	}
+	if lsh.hot != nil {
+		// The hash keys have changed
+		if _, err := lsh.RefreshHotKeys(); err != nil {
+			return err
+		}
+	}
+	return lsh.Index()
+}
+
+// checkOwnColumns returns ErrUnsupported if the table has columns
+// other than those of the index, which Reshape would lose.
+func (lsh *SqlLsh) checkOwnColumns() error {
+	if lsh.dryRun {
+		return nil
+	}
+	cols, err := lsh.tableColumns()
+	if err != nil {
+		return wrapErr("reshape", err)
+	}
+	own := map[string]bool{"deleted": lsh.softDelete, "inserted_at": lsh.insertTime}
+	for _, col := range strings.Split(lsh.columnList(), ",") {
+		own[col] = true
+	}
+	for _, col := range cols {
+		if !own[strings.ToLower(col)] {
+			return ErrUnsupported
+		}
+	}
+	return nil
+}
+
+// rehashInto inserts the rehashed entries of lsh into the table of
+// next inside tx, reading one page of entries at a time.
+func (lsh *SqlLsh) rehashInto(next *SqlLsh, tx *sql.Tx, rehash func(int, Signature) Signature) error {
+	insert, err := tx.Prepare(next.insertStr())
+	if err != nil {
+		return wrapErr("reshape", err)
+	}
+	defer insert.Close()
+	var entries []Entry
+	for {
+		conds := make([]string, 0, 2)
+		args := make([]interface{}, 0, 1)
+		if lsh.softDelete {
+			conds = append(conds, "deleted = 0")
+		}
+		if len(entries) > 0 {
+			// Continue after the last entry of the previous page
+			conds = append(conds, "id > "+lsh.dialect.varFmt(0))
+			args = append(args, entries[len(entries)-1].Id)
+		}
+		where := ""
+		if len(conds) > 0 {
+			where = " WHERE " + strings.Join(conds, " AND ")
+		}
+		rows, err := tx.Query(fmt.Sprintf("SELECT %s FROM %s%s ORDER BY id"+lsh.dialect.limitFmt,
+			lsh.columnList(), lsh.tableName, where, reshapePageSize), args...)
+		if err != nil {
+			return wrapErr("reshape", err)
+		}
+		entries = entries[:0]
+		for rows.Next() {
+			e, err := lsh.scanEntry(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			entries = append(entries, e)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return wrapErr("reshape", err)
+		}
+		for _, e := range entries {
+			sig := rehash(e.Id, e.Signature)
+			if len(sig) != next.k*next.l {
+				return ErrSignatureSizeMismatch
+			}
+			if _, err := insert.Exec(next.insertArgs(e.Id, sig)...); err != nil {
+				return wrapErr("reshape", err)
+			}
+		}
+		if len(entries) < reshapePageSize {
+			return nil
+		}
+	}
+}
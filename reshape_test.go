@@ -0,0 +1,122 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func Test_Reshape(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithSoftDelete())
+	if err != nil {
+		t.Error(err)
+	}
+	sigs := randomSigs(10, 4)
+	for i := range sigs {
+		lsh.Insert(i, sigs[i])
+	}
+	lsh.Delete(9)
+	if err := lsh.Index(); err != nil {
+		t.Error(err)
+	}
+	if err := lsh.Reshape(2, 3, nil); err != ErrInvalidParameter {
+		t.Error("Fail to raise error")
+	}
+	// Regroup the stored hash values
+	if err := lsh.Reshape(1, 4, nil); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 9; i++ {
+		// One hash value is now a hash key
+		ids, err := lsh.QueryIDs(Signature{0, 0, 0, sigs[i][3]})
+		if err != nil {
+			t.Error(err)
+		}
+		if len(ids) != 1 || ids[0] != i {
+			t.Error("Error in query after regrouping")
+		}
+	}
+	// Rehash into a larger signature
+	err = lsh.Reshape(3, 2, func(id int, sig Signature) Signature {
+		return append(Signature{sig[0], sig[1], sig[2], sig[3]}, 0, 0)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 9; i++ {
+		ids, err := lsh.QueryIDs(Signature{sigs[i][0], sigs[i][1], sigs[i][2], 1, 1, 1})
+		if err != nil {
+			t.Error(err)
+		}
+		if len(ids) != 1 || ids[0] != i {
+			t.Error("Error in query after rehashing")
+		}
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM lshtable").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 9 {
+		t.Errorf("Expected the deleted entry to be dropped, got %d rows", count)
+	}
+	if _, err := NewSqliteLsh(3, 2, "lshtable", db, WithSoftDelete()); err != nil {
+		t.Error(err)
+	}
+	removeTempFile(t, f)
+}
+
+func Test_ReshapeUserColumns(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithSoftDelete())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Insert(0, Signature{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("ALTER TABLE lshtable ADD COLUMN label TEXT"); err != nil {
+		t.Fatal(err)
+	}
+	// The column and its data would be lost
+	if err := lsh.Reshape(1, 4, nil); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+	ids, err := lsh.QueryIDs(Signature{1, 2, 5, 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 {
+		t.Errorf("Expected 1 ID, got %d", len(ids))
+	}
+}
+
+func Test_ReshapeAutoID(t *testing.T) {
+	rec := NewRecorder()
+	defer rec.Close()
+	lsh, err := NewPostgresLsh(2, 2, "lshtable", rec, WithAutoID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Reset()
+	if err := lsh.Reshape(1, 4, nil); err != nil {
+		t.Fatal(err)
+	}
+	script := rec.Script()
+	if !strings.Contains(script, "id INTEGER GENERATED BY DEFAULT AS IDENTITY") {
+		t.Errorf("Expected the new table to generate the IDs, got %s", script)
+	}
+	if !strings.Contains(script, "setval(pg_get_serial_sequence('lshtable_reshape', 'id')") {
+		t.Errorf("Expected the IDs to follow the copied ones, got %s", script)
+	}
+}
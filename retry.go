@@ -0,0 +1,108 @@
+package sqllsh
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"time"
+)
+
+// RetryPolicy is the way inserts and queries are retried after a
+// transient database error, see WithRetry.
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts of an operation, including the first one
+	Backoff     time.Duration // Wait before the first retry, doubled after each retry
+	MaxBackoff  time.Duration // Longest wait between retries, no limit if zero
+	// Retryable reports whether an error is transient, IsTransient if
+	// nil
+	Retryable func(err error) bool
+}
+
+// WithRetry retries Insert, each transaction of BatchInsert, and the
+// collision queries of Query and QueryIDs following p, when they fail
+// with a transient error such as a deadlock, a serialization failure
+// or a dropped connection.
+// A query is not retried once it has started returning IDs.
+// A retried Insert may fail with ErrIDExists if the first attempt
+// was committed although the database reported an error.
+// The retried errors are logged with Retried set in the Event.
+// The constructor returns ErrUnsupported if the DB is a *sql.Tx or a
+// *sql.Conn, whose transaction or connection cannot be replaced.
+func WithRetry(p RetryPolicy) Option {
+	return func(lsh *SqlLsh) {
+		lsh.retryPolicy = &p
+	}
+}
+
+// transientErrors are parts of the messages of transient errors of the
+// supported databases.
+var transientErrors = []string{
+	"database is locked",         // SQLite
+	"database table is locked",   // SQLite
+	"deadlock detected",          // PostgreSQL
+	"could not serialize access", // PostgreSQL
+	"Deadlock found",             // MySQL
+	"Lock wait timeout exceeded", // MySQL
+	"try restarting transaction", // MySQL and TiDB
+	"ORA-00060",                  // Oracle deadlock
+	"ORA-08177",                  // Oracle serialization failure
+	"ORA-03113",                  // Oracle lost connection
+	"code = \"Aborted\"",         // Spanner
+	"code = Aborted",             // Spanner
+	"bad connection",             // database/sql
+	"connection reset by peer",   // Network
+	"broken pipe",                // Network
+	"Transaction conflict",       // DuckDB
+}
+
+// IsTransient reports whether err is likely to go away if the operation
+// is retried, as deadlocks, serialization failures and dropped
+// connections do.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	msg := err.Error()
+	for _, s := range transientErrors {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// retry runs f until it succeeds, fails with an error that is not
+// transient, or has been run MaxAttempts times.
+func (lsh *SqlLsh) retry(op string, f func() error) error {
+	return lsh.retryWith(lsh.retryPolicy, op, f)
+}
+
+// retryWith is retry following p, running f once if p is nil.
+func (lsh *SqlLsh) retryWith(p *RetryPolicy, op string, f func() error) error {
+	if p == nil {
+		return f()
+	}
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsTransient
+	}
+	backoff := p.Backoff
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		err := f()
+		if err == nil || attempt >= p.MaxAttempts || !retryable(err) {
+			return err
+		}
+		if lsh.logger != nil {
+			lsh.logger.Log(Event{Op: op, Duration: time.Since(start), Err: err, Retried: true})
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+		if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package sqllsh
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func Test_IsTransient(t *testing.T) {
+	for _, c := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{ErrIDExists, false},
+		{errors.New("database is locked"), true},
+		{&OpError{Op: "insert", Err: errors.New("pq: deadlock detected")}, true},
+		{fmt.Errorf("query: %w", driver.ErrBadConn), true},
+	} {
+		if got := IsTransient(c.err); got != c.want {
+			t.Errorf("Expected %v for %v, got %v", c.want, c.err, got)
+		}
+	}
+}
+
+func Test_Retry(t *testing.T) {
+	var events []Event
+	lsh := &SqlLsh{}
+	WithLogger(LoggerFunc(func(e Event) {
+		events = append(events, e)
+	}))(lsh)
+	WithRetry(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})(lsh)
+	locked := errors.New("database is locked")
+	attempts := 0
+	err := lsh.retry("insert", func() error {
+		attempts++
+		if attempts < 3 {
+			return locked
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Errorf("Expected success after 3 attempts, got %v after %d", err, attempts)
+	}
+	if len(events) != 2 || !events[0].Retried || events[0].Err != locked {
+		t.Errorf("Expected 2 retried events, got %v", events)
+	}
+	attempts = 0
+	err = lsh.retry("insert", func() error {
+		attempts++
+		return locked
+	})
+	if err != locked || attempts != 3 {
+		t.Errorf("Expected to give up after 3 attempts, got %v after %d", err, attempts)
+	}
+	attempts = 0
+	err = lsh.retry("insert", func() error {
+		attempts++
+		return ErrIDExists
+	})
+	if err != ErrIDExists || attempts != 1 {
+		t.Errorf("Expected no retry of ErrIDExists, got %v after %d", err, attempts)
+	}
+}
@@ -0,0 +1,141 @@
+package sqllsh
+
+import "sync"
+
+// ShardedLsh partitions an LSH index across multiple SqlLsh, possibly
+// in different databases.
+// Each ID is assigned to one shard by its value, and queries are sent to
+// all shards concurrently.
+type ShardedLsh struct {
+	shards []*SqlLsh
+}
+
+// NewShardedLsh creates a sharded index from the given shards, which
+// must all have the same k and l.
+// The order of the shards determines which shard an ID is assigned to,
+// so the same shards must be given in the same order every time.
+func NewShardedLsh(shards ...*SqlLsh) (*ShardedLsh, error) {
+	if len(shards) == 0 {
+		return nil, ErrInvalidParameter
+	}
+	for _, shard := range shards {
+		if shard.k != shards[0].k || shard.l != shards[0].l {
+			return nil, ErrIncompatible
+		}
+	}
+	return &ShardedLsh{shards: shards}, nil
+}
+
+// shard returns the shard that id is assigned to.
+func (s *ShardedLsh) shard(id int) *SqlLsh {
+	i := id % len(s.shards)
+	if i < 0 {
+		i += len(s.shards)
+	}
+	return s.shards[i]
+}
+
+// Index builds the indexes of all shards.
+func (s *ShardedLsh) Index() error {
+	return s.each(func(shard *SqlLsh) error {
+		return shard.Index()
+	})
+}
+
+// Analyze refreshes the statistics of all shards.
+func (s *ShardedLsh) Analyze() error {
+	return s.each(func(shard *SqlLsh) error {
+		return shard.Analyze()
+	})
+}
+
+// Insert appends a new Signature with id to its shard.
+func (s *ShardedLsh) Insert(id int, sig Signature) error {
+	return s.shard(id).Insert(id, sig)
+}
+
+// BatchInsert partitions the Signatures by shard and inserts each
+// partition using the BatchInsert of the shard.
+// The input is validated before anything is inserted, but the shards
+// commit independently, so if an error occurs some shards may
+// have inserted their partitions.
+func (s *ShardedLsh) BatchInsert(ids []int, sigs []Signature) error {
+	if err := validateBatch(ids, sigs, s.shards[0].k*s.shards[0].l); err != nil {
+		return err
+	}
+	shardIds := make(map[*SqlLsh][]int)
+	shardSigs := make(map[*SqlLsh][]Signature)
+	for i := range ids {
+		shard := s.shard(ids[i])
+		shardIds[shard] = append(shardIds[shard], ids[i])
+		shardSigs[shard] = append(shardSigs[shard], sigs[i])
+	}
+	return s.each(func(shard *SqlLsh) error {
+		if len(shardIds[shard]) == 0 {
+			return nil
+		}
+		return shard.BatchInsert(shardIds[shard], shardSigs[shard])
+	})
+}
+
+// Delete removes the Signature with id from its shard.
+func (s *ShardedLsh) Delete(id int) error {
+	return s.shard(id).Delete(id)
+}
+
+// Query finds the IDs of the Signatures in all shards that have at least
+// one hash key collison with the query Signature, then writes the
+// IDs to a given output channel.
+// The caller is responsible for closing the channel.
+func (s *ShardedLsh) Query(sig Signature, out chan int) error {
+	return s.each(func(shard *SqlLsh) error {
+		return shard.Query(sig, out)
+	})
+}
+
+// QueryIDs is like Query, but returns the IDs in a slice
+// once the query has finished.
+func (s *ShardedLsh) QueryIDs(sig Signature) ([]int, error) {
+	var mu sync.Mutex
+	ids := make([]int, 0)
+	err := s.each(func(shard *SqlLsh) error {
+		found, err := shard.QueryIDs(sig)
+		mu.Lock()
+		ids = append(ids, found...)
+		mu.Unlock()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Scan writes the Entries of all shards to a given output channel.
+// The caller is responsible for closing the channel.
+func (s *ShardedLsh) Scan(out chan Entry) error {
+	return s.each(func(shard *SqlLsh) error {
+		return shard.Scan(out)
+	})
+}
+
+// each calls fn on all shards concurrently, and returns the first
+// error.
+func (s *ShardedLsh) each(fn func(*SqlLsh) error) error {
+	errs := make([]error, len(s.shards))
+	var wg sync.WaitGroup
+	for i := range s.shards {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = fn(s.shards[i])
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
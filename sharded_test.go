@@ -0,0 +1,66 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_ShardedLsh(t *testing.T) {
+	shards := make([]*SqlLsh, 3)
+	for i := range shards {
+		f := creatTempFile(t)
+		defer removeTempFile(t, f)
+		db, err := sql.Open("sqlite3", f.Name())
+		if err != nil {
+			t.Error(err)
+		}
+		shards[i], err = NewSqliteLsh(2, 2, "lshtable", db)
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	s, err := NewShardedLsh(shards...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(10, 4)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	if err := s.BatchInsert(ids, sigs); err != nil {
+		t.Error(err)
+	}
+	if err := s.Insert(-1, Signature{1, 2, 3, 4}); err != nil {
+		t.Error(err)
+	}
+	if err := s.Index(); err != nil {
+		t.Error(err)
+	}
+	for i := range sigs {
+		found, err := s.QueryIDs(sigs[i])
+		if err != nil {
+			t.Error(err)
+		}
+		if len(found) != 1 || found[0] != i {
+			t.Error("Error in query")
+		}
+	}
+	if err := s.Delete(-1); err != nil {
+		t.Error(err)
+	}
+	out := make(chan Entry)
+	go func() {
+		if err := s.Scan(out); err != nil {
+			t.Error(err)
+		}
+		close(out)
+	}()
+	count := 0
+	for _ = range out {
+		count++
+	}
+	if count != len(sigs) {
+		t.Errorf("Expected %d entries, got %d", len(sigs), count)
+	}
+}
@@ -0,0 +1,97 @@
+package sqllsh
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Similar is an ID found by QuerySimilar, with the Jaccard similarity
+// between its set and the query set estimated from their MinHash
+// Signatures.
+type Similar struct {
+	Id         int
+	Similarity float64
+}
+
+// QuerySimilar finds the candidates of sig like QueryIDs, and returns
+// those whose estimated Jaccard similarity with sig is at least
+// threshold, the most similar first, and by ascending ID between equal
+// similarities.
+// The similarity is the fraction of the k*l hash values equal in sig
+// and in the stored MinHash Signature; it is computed by the database
+// in the same query as the candidates, so the Signatures are not sent
+// to the application.
+// threshold must be between 0 and 1.
+// It needs the hash values in their own columns, so it is not supported
+// with WithCompactLayout, WithBandKeys and WithBBit.
+// The query cache and WithQueryTimeout are not used.
+func (lsh *SqlLsh) QuerySimilar(sig Signature, threshold float64) ([]Similar, error) {
+	if lsh.packed || lsh.bits > 0 {
+		return nil, ErrUnsupported
+	}
+	if threshold < 0 || threshold > 1 || math.IsNaN(threshold) {
+		return nil, ErrInvalidParameter
+	}
+	sig, err := lsh.beforeQuery("query similar", sig)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != lsh.k*lsh.l {
+		return nil, ErrSignatureSizeMismatch
+	}
+	if err := lsh.ready(); err != nil {
+		return nil, err
+	}
+	bands := lsh.maybeBands(sig)
+	if bands == nil {
+		bands = lsh.allBands()
+	}
+	if len(bands) == 0 {
+		return nil, nil
+	}
+	args := lsh.queryArgs(sig, bands, lsh.k)
+	query := lsh.similarStr(bands, len(args))
+	for band := 0; band < lsh.l; band++ {
+		args = append(args, lsh.bandValues(sig, band)...)
+	}
+	n := len(sig)
+	args = append(args, int(math.Ceil(threshold*float64(n))))
+	rows, err := lsh.readDB().Query(query, args...)
+	if err != nil {
+		return nil, wrapErr("query similar", err)
+	}
+	defer rows.Close()
+	var similar []Similar
+	for rows.Next() {
+		var id, matches int
+		if err := rows.Scan(&id, &matches); err != nil {
+			return nil, wrapErr("query similar", err)
+		}
+		similar = append(similar, Similar{Id: id, Similarity: float64(matches) / float64(n)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapErr("query similar", err)
+	}
+	return similar, nil
+}
+
+// similarStr returns the query of QuerySimilar on bands: the
+// candidates of bandsQueryStr with the number of hash values equal to
+// those of the query, at least the last argument. The placeholders of
+// the hash values are numbered from offset, after those of the
+// candidates.
+func (lsh *SqlLsh) similarStr(bands []int, offset int) string {
+	cols := lsh.hashCols()
+	terms := make([]string, len(cols))
+	for i, col := range cols {
+		terms[i] = fmt.Sprintf("CASE WHEN %s = %s THEN 1 ELSE 0 END",
+			col, lsh.dialect.varFmt(offset+i))
+	}
+	return fmt.Sprintf("WITH candidates AS (%s) "+
+		"SELECT id, matches FROM (SELECT id, %s AS matches FROM %s "+
+		"WHERE %sid IN (SELECT id FROM candidates)) s "+
+		"WHERE matches >= %s ORDER BY matches DESC, id",
+		lsh.bandsQueryStr(bands, lsh.k), strings.Join(terms, " + "),
+		lsh.tableName, lsh.liveCond(), lsh.dialect.varFmt(offset+len(cols)))
+}
@@ -0,0 +1,53 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_QuerySimilar(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := []Signature{{1, 2, 3, 4}, {1, 2, 3, 5}, {1, 2, 6, 7}, {8, 9, 10, 11}}
+	if err := lsh.BatchInsert([]int{0, 1, 2, 3}, sigs); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	similar, err := lsh.QuerySimilar(Signature{1, 2, 3, 4}, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Similar{{0, 1}, {1, 0.75}, {2, 0.5}}
+	if len(similar) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, similar)
+	}
+	for i := range expected {
+		if similar[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, similar)
+		}
+	}
+	similar, err = lsh.QuerySimilar(Signature{1, 2, 3, 4}, 0.8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(similar) != 1 || similar[0].Id != 0 {
+		t.Errorf("Expected only 0, got %v", similar)
+	}
+	if _, err := lsh.QuerySimilar(Signature{1, 2, 3, 4}, 1.5); err != ErrInvalidParameter {
+		t.Errorf("Expected ErrInvalidParameter, got %v", err)
+	}
+	if _, err := lsh.QuerySimilar(Signature{1, 2}, 0.5); err != ErrSignatureSizeMismatch {
+		t.Errorf("Expected ErrSignatureSizeMismatch, got %v", err)
+	}
+}
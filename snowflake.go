@@ -0,0 +1,66 @@
+package sqllsh
+
+import (
+	"fmt"
+	"strings"
+)
+
+// snowflakeMaxClusterKey is the number of columns of the clustering
+// key, beyond which Snowflake recommends against adding more.
+const snowflakeMaxClusterKey = 4
+
+var snowflakeDialect = dialect{
+	varFmt: func(i int) string {
+		return "?"
+	},
+	intType:        "BIGINT",
+	blobType:       "BINARY",
+	limitFmt:       " LIMIT %d",
+	indexMethods:   map[IndexType]string{IndexBTree: ""},
+	explainPrefix:  "EXPLAIN ",
+	tablesQuery:    "SELECT LOWER(table_name) FROM information_schema.tables WHERE table_schema = CURRENT_SCHEMA()",
+	plan:           PlanOr,
+	multiRowValues: 16384,
+	tableOptions:   snowflakeTableOptions,
+	implicitCommit: true,
+}
+
+// NewSnowflakeLsh creates a new Snowflake-backed LSH index, to be used
+// with the github.com/snowflakedb/gosnowflake driver.
+// Snowflake has no indexes, so the table is clustered on the first
+// hash value of up to 4 hash tables, and Index does nothing; Analyze is
+// not supported.
+// BatchInsert inserts many rows per statement, and Snowflake does not
+// enforce primary keys, so inserting an existing ID adds a second row
+// instead of failing with ErrIDExists, and only ConflictError is
+// supported.
+// For very large loads, the rows should rather be copied from a stage
+// by a BatchWriter.
+// Snowflake commits the transaction on DDL statements, so Reshape and
+// AddHashTables are not supported.
+// The caller is responsible for closing the database connection
+// object.
+func NewSnowflakeLsh(k, l int, tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	lsh, err := newSqlLsh(k, l, tableName, db, snowflakeDialect, opts)
+	return lsh, err
+}
+
+// OpenSnowflakeLsh opens an existing Snowflake-backed LSH index with
+// its recorded parameters, like OpenSqliteLsh.
+func OpenSnowflakeLsh(tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	return openSqlLsh(tableName, db, snowflakeDialect, opts)
+}
+
+// snowflakeTableOptions returns the clustering key of the table, on the
+// first column of the hash keys.
+func snowflakeTableOptions(lsh *SqlLsh) string {
+	n := lsh.l
+	if n > snowflakeMaxClusterKey {
+		n = snowflakeMaxClusterKey
+	}
+	cols := make([]string, n)
+	for i := range cols {
+		cols[i] = lsh.bandCols(i)[0]
+	}
+	return fmt.Sprintf(" CLUSTER BY (%s)", strings.Join(cols, ", "))
+}
@@ -0,0 +1,78 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func Test_SnowflakeStatements(t *testing.T) {
+	lsh := &SqlLsh{k: 2, l: 6, tableName: "lshtable", dialect: snowflakeDialect}
+	expected := ") CLUSTER BY (hv_0, hv_2, hv_4, hv_6)"
+	if s := lsh.createTableStr(); !strings.HasSuffix(s, expected) {
+		t.Errorf("Expected %q, got %q", expected, s)
+	}
+	lsh.l = 1
+	expected = "INSERT INTO lshtable (id,hv_0,hv_1) VALUES(?,?,?),(?,?,?)"
+	if s := lsh.insertRowsStr(2); s != expected {
+		t.Errorf("Expected %q, got %q", expected, s)
+	}
+	if n := lsh.multiRowSize(); n != 16384/3 {
+		t.Errorf("Expected %d rows per insert, got %d", 16384/3, n)
+	}
+}
+
+func Test_SnowflakeImplicitCommit(t *testing.T) {
+	rec := NewRecorder()
+	defer rec.Close()
+	lsh, err := NewSnowflakeLsh(2, 2, "lshtable", rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Snowflake commits the transaction on DDL statements
+	if err := lsh.Reshape(1, 4, nil); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+	if err := lsh.AddHashTables(1, nil); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
+
+func Test_MultiRowInsert(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := sqliteDialect
+	d.multiRowValues = 70
+	lsh, err := newSqlLsh(2, 3, "lshtable", db, d, []Option{WithSoftDelete()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(25, 6)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	// 10 rows of 7 values per statement
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Delete(24); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.BatchInsert([]int{24}, sigs[24:]); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{0, 9, 10, 24} {
+		sig, err := lsh.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameSig(sig, sigs[id]) {
+			t.Errorf("Expected %v for %d, got %v", sigs[id], id, sig)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package sqllsh
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+var spannerDialect = dialect{
+	varFmt: func(i int) string {
+		return fmt.Sprintf("@p%d", i+1)
+	},
+	intType:        "INT64",
+	blobType:       "BYTES(MAX)",
+	limitFmt:       " LIMIT %d",
+	createIndexFmt: "CREATE INDEX %s ON %s",
+	dropIndexFmt:   "DROP INDEX %[1]s",
+	indexMethods:   map[IndexType]string{IndexBTree: ""},
+	plan:           PlanOr,
+	maxValues:      80000,
+	createTable:    spannerCreateTableStr,
+	ddl:            spannerDDL,
+	indexesQuery:   "SELECT index_name FROM information_schema.indexes WHERE table_name = @p1",
+	tablesQuery:    "SELECT table_name FROM information_schema.tables WHERE table_schema = ''",
+	maxParams:      950,
+	maxIdent:       128,
+}
+
+// NewSpannerLsh creates a new LSH index on a Google Cloud Spanner
+// database using the GoogleSQL dialect, through the
+// github.com/googleapis/go-sql-spanner driver.
+// Spanner cannot run DDL statements in a transaction, so the table and
+// the indexes are created in DDL batches, and AddHashTables, Reshape
+// and BulkLoad are not supported.
+// Unless WithCommitSize is used, BatchInsert commits at most 80000
+// values at a time to stay below the mutation limit of Spanner
+// transactions, see BatchInsert.
+// Only ConflictError is supported, and Analyze and ExplainQuery are
+// not supported, as Spanner collects the statistics by itself.
+// The caller is responsible for closing the database connection
+// object.
+func NewSpannerLsh(k, l int, tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	lsh, err := newSqlLsh(k, l, tableName, db, spannerDialect, opts)
+	return lsh, err
+}
+
+// spannerCreateTableStr returns the CREATE TABLE statement of Spanner,
+// where the primary key follows the columns.
+func spannerCreateTableStr(lsh *SqlLsh) string {
+	createSeg := append([]string{"id INT64 NOT NULL"}, lsh.valueColDefs("INT64", "BYTES(MAX)")...)
+	if lsh.softDelete {
+		createSeg = append(createSeg, "deleted INT64 NOT NULL DEFAULT (0)")
+	}
+	if lsh.insertTime {
+		createSeg = append(createSeg, "inserted_at INT64 NOT NULL DEFAULT (0)")
+	}
+	if lsh.ensemble != nil {
+		createSeg = append(createSeg, "part INT64 NOT NULL DEFAULT (0)")
+	}
+	if lsh.namespaces {
+		createSeg = append(createSeg, "ns INT64 NOT NULL DEFAULT (0)")
+	}
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n", lsh.tableName) +
+		strings.Join(createSeg, ",\n") + "\n) PRIMARY KEY (" + strings.Join(lsh.keyCols(), ", ") + ")"
+}
+
+// spannerDDL runs stmts in one DDL batch of the go-sql-spanner driver,
+// which cannot run DDL inside a transaction.
+func spannerDDL(db DB, stmts []string) error {
+	ctx := context.Background()
+	if rec, ok := db.(*Recorder); ok {
+		db = rec.DB
+	}
+	conn, ok := db.(*sql.Conn)
+	if pool, isPool := db.(*sql.DB); isPool {
+		var err error
+		if conn, err = pool.Conn(ctx); err != nil {
+			return err
+		}
+		defer conn.Close()
+	} else if !ok {
+		return ErrUnsupported
+	}
+	if _, err := conn.ExecContext(ctx, "START BATCH DDL"); err != nil {
+		return err
+	}
+	for _, stmt := range stmts {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			conn.ExecContext(ctx, "ABORT BATCH")
+			return err
+		}
+	}
+	_, err := conn.ExecContext(ctx, "RUN BATCH")
+	return err
+}
+
+// OpenSpannerLsh opens an existing Spanner-backed LSH index with its
+// recorded parameters, like OpenSqliteLsh.
+func OpenSpannerLsh(tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	return openSqlLsh(tableName, db, spannerDialect, opts)
+}
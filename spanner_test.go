@@ -0,0 +1,53 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func Test_SpannerStatements(t *testing.T) {
+	s := newStatements(1, 2, "lshtable", spannerDialect, []Option{WithSoftDelete()})
+	expected := "CREATE TABLE IF NOT EXISTS lshtable (\nid INT64 NOT NULL,\n" +
+		"hv_0 INT64 NOT NULL,\nhv_1 INT64 NOT NULL,\ndeleted INT64 NOT NULL DEFAULT (0)\n) PRIMARY KEY (id)"
+	if s.CreateTable != expected {
+		t.Errorf("Expected %q, got %q", expected, s.CreateTable)
+	}
+	expected = "UPDATE lshtable SET deleted = 1 WHERE id = @p1"
+	if s.Delete != expected {
+		t.Errorf("Expected %q, got %q", expected, s.Delete)
+	}
+	lsh := &SqlLsh{k: 32, l: 8, dialect: spannerDialect}
+	if n := lsh.batchSize(10000); n != 80000/257 {
+		t.Errorf("Expected %d, got %d", 80000/257, n)
+	}
+}
+
+func Test_BatchWriter(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	var written [][]interface{}
+	writer := func(table string, cols []string, rows [][]interface{}, c Conflict) error {
+		if table != "lshtable" || !reflect.DeepEqual(cols, []string{"id", "hv_0", "hv_1"}) {
+			t.Errorf("Unexpected table %s and columns %v", table, cols)
+		}
+		written = append(written, rows...)
+		return nil
+	}
+	lsh, err := NewSqliteLsh(1, 2, "lshtable", db, WithBatchWriter(writer),
+		WithCommitSize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.BatchInsert([]int{0, 1, 2}, []Signature{{1, 2}, {3, 4}, {5, 6}}); err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]interface{}{{0, uint(1), uint(2)}, {1, uint(3), uint(4)}, {2, uint(5), uint(6)}}
+	if !reflect.DeepEqual(written, expected) {
+		t.Errorf("Expected %v, got %v", expected, written)
+	}
+	removeTempFile(t, f)
+}
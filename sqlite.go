@@ -1,15 +1,131 @@
 package sqllsh
 
-import "database/sql"
+import (
+	"database/sql"
+	"fmt"
+)
+
+var sqliteDialect = dialect{
+	varFmt: func(i int) string {
+		return "?"
+	},
+	intType:        "BIGINT",
+	blobType:       "BLOB",
+	limitFmt:       " LIMIT %d",
+	createIndexFmt: "CREATE INDEX %s ON %s",
+	dropIndexFmt:   "DROP INDEX %[1]s",
+	indexMethods:   map[IndexType]string{IndexBTree: ""},
+	reindexFmt:     "REINDEX %s",
+	conflictClause: onConflictClause,
+	analyzeFmt:     "ANALYZE %s",
+	explainPrefix:  "EXPLAIN QUERY PLAN ",
+	autoIDType:     "INTEGER PRIMARY KEY",
+	returning:      true,
+	plan:           PlanOr,
+	indexesQuery:   "SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = ?",
+	tablesQuery:    "SELECT name FROM sqlite_master WHERE type = 'table'",
+	maxParams:      999,
+	pragmas:        true,
+}
+
+// sqliteBusyTimeout is the time in milliseconds the transactions of
+// WithInsertWorkers wait for the lock of an SQLite database, as
+// github.com/mattn/go-sqlite3 does by default.
+const sqliteBusyTimeout = 5000
 
 // NewSqliteLsh creates a new Sqlite3-backed LSH index.
+// It works with the github.com/mattn/go-sqlite3 driver and the
+// cgo-free modernc.org/sqlite driver, whose connections do not wait for
+// the lock of the database unless the busy_timeout pragma is set in the
+// data source name; the transactions of WithInsertWorkers set it if
+// needed.
 // The caller is responsible for closing the database connection
 // object.
-func NewSqliteLsh(k, l int, tableName string, db *sql.DB) (*SqlLsh, error) {
-	varFmt := func(i int) string {
-		return "?"
-	}
-	createIndexFmt := "CREATE INDEX ht_%d ON %s ("
-	lsh, err := newSqlLsh(k, l, tableName, db, varFmt, createIndexFmt)
+func NewSqliteLsh(k, l int, tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	lsh, err := newSqlLsh(k, l, tableName, db, sqliteDialect, opts)
 	return lsh, err
 }
+
+// OpenSqliteLsh opens an existing Sqlite3-backed LSH index, with the k, l
+// and layout recorded when the table was created, so that they do not
+// have to be given again.
+// It returns ErrNotFound if the table does not exist or has no recorded
+// parameters.
+// The caller is responsible for closing the database connection
+// object.
+func OpenSqliteLsh(tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	return openSqlLsh(tableName, db, sqliteDialect, opts)
+}
+
+// SqliteOptions are the storage parameters of an SQLite index, which
+// matter with the wide rows of the table.
+type SqliteOptions struct {
+	// WithoutRowid stores the rows in the B-Tree of the id instead of a
+	// separate rowid B-Tree, which saves a lookup per row; it cannot be
+	// used with WithAutoID.
+	WithoutRowid bool
+	// PageSize is the page size in bytes, a power of two from 512 to
+	// 65536, 0 for the default.
+	// It only applies to a new database file, or after a VACUUM.
+	PageSize int
+	// MmapSize is the number of bytes of the database file accessed
+	// through memory mapping, 0 for the default.
+	// It only applies to the connection used by the constructor, so
+	// with a pool of several connections it should rather be set in
+	// the data source name, if the driver supports it.
+	MmapSize int64
+}
+
+// WithSqliteOptions sets the storage parameters of an SQLite index.
+// WithoutRowid changes the table schema, so it must be used every time
+// the same table is opened.
+// The constructor returns ErrUnsupported for other databases.
+func WithSqliteOptions(o SqliteOptions) Option {
+	return func(lsh *SqlLsh) {
+		lsh.sqlite = &o
+	}
+}
+
+// withoutRowid returns the WITHOUT ROWID clause of an SQLite table, if
+// it is used.
+func (lsh *SqlLsh) withoutRowid() string {
+	if lsh.sqlite != nil && lsh.sqlite.WithoutRowid {
+		return " WITHOUT ROWID"
+	}
+	return ""
+}
+
+// setPragmas sets the SQLite pragmas of the SqliteOptions, if any.
+func (lsh *SqlLsh) setPragmas() error {
+	if lsh.sqlite == nil {
+		return nil
+	}
+	var pragmas []string
+	if lsh.sqlite.PageSize > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA page_size = %d", lsh.sqlite.PageSize))
+	}
+	if lsh.sqlite.MmapSize > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA mmap_size = %d", lsh.sqlite.MmapSize))
+	}
+	for _, pragma := range pragmas {
+		if _, err := lsh.db.Exec(pragma); err != nil {
+			return wrapErr("create table", err)
+		}
+	}
+	return nil
+}
+
+// waitLock makes the connection of tx wait for the lock of the SQLite
+// database held by the other workers of WithInsertWorkers, if it has no
+// busy timeout, as with the modernc.org/sqlite driver.
+func (lsh *SqlLsh) waitLock(tx *sql.Tx) error {
+	if !lsh.dialect.pragmas || lsh.workers <= 1 {
+		return nil
+	}
+	var timeout int
+	if err := tx.QueryRow("PRAGMA busy_timeout").Scan(&timeout); err != nil || timeout > 0 {
+		return err
+	}
+	_, err := tx.Exec(fmt.Sprintf("PRAGMA busy_timeout = %d", sqliteBusyTimeout))
+	return err
+}
@@ -26,7 +26,7 @@ func removeTempFileBench(t *testing.B, tempfile *os.File) {
 	}
 }
 
-func runSqlite(k, l, n, nq int, b *testing.B) {
+func runSqlite(k, l, n, nq int, b *testing.B, opts ...Option) {
 	// Inialize database
 	f := creatTempFileBench(b)
 	db, err := sql.Open("sqlite3", f.Name())
@@ -35,7 +35,7 @@ func runSqlite(k, l, n, nq int, b *testing.B) {
 	}
 
 	// Initalize data
-	lsh, err := NewSqliteLsh(k, l, "lshtable", db)
+	lsh, err := NewSqliteLsh(k, l, "lshtable", db, opts...)
 	if err != nil {
 		b.Fatal(err)
 	}
@@ -96,3 +96,11 @@ func BenchmarkSqliteLsh256(b *testing.B) {
 func BenchmarkSqliteLsh512(b *testing.B) {
 	runSqlite(8, 64, 10000, 100, b)
 }
+
+func BenchmarkSqliteLsh256Or(b *testing.B) {
+	runSqlite(4, 64, 10000, 100, b, WithQueryPlan(PlanOr))
+}
+
+func BenchmarkSqliteLsh256Union(b *testing.B) {
+	runSqlite(4, 64, 10000, 100, b, WithQueryPlan(PlanUnion))
+}
@@ -0,0 +1,60 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func Test_SqliteOptions(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db, WithSqliteOptions(SqliteOptions{
+		WithoutRowid: true,
+		PageSize:     16384,
+		MmapSize:     1 << 20,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema string
+	err = db.QueryRow("SELECT sql FROM sqlite_master WHERE name = 'lshtable'").Scan(&schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(schema, "WITHOUT ROWID") {
+		t.Errorf("Expected a WITHOUT ROWID table, got %s", schema)
+	}
+	var pageSize int
+	if err := db.QueryRow("PRAGMA page_size").Scan(&pageSize); err != nil {
+		t.Fatal(err)
+	}
+	if pageSize != 16384 {
+		t.Errorf("Expected page size 16384, got %d", pageSize)
+	}
+	sigs := randomSigs(10, 6)
+	if err := lsh.BatchInsert([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, sigs); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := lsh.QueryIDs(sigs[4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsID(ids, 4) {
+		t.Errorf("Expected 4 in %v", ids)
+	}
+	_, err = NewSqliteLsh(2, 3, "autotable", db, WithAutoID(),
+		WithSqliteOptions(SqliteOptions{WithoutRowid: true}))
+	if err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported with WithAutoID, got %v", err)
+	}
+}
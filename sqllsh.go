@@ -19,9 +19,11 @@ package sqllsh
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Signature is a list of integer hash values from
@@ -32,82 +34,391 @@ type Signature []uint
 
 // SqlLsh is the entry point to the on-disk LSH index.
 type SqlLsh struct {
-	k              int              // Hash key size
-	l              int              // Number of hash tables, or number of hash keys
-	tableName      string           // Name of the database table used
-	db             *sql.DB          // Database connection
-	varFmt         func(int) string // Database specific formatter for placehoder
-	insertStmt     *sql.Stmt
-	queryStmt      *sql.Stmt
-	scanStmt       *sql.Stmt
-	indexStmts     []*sql.Stmt
-	createIndexFmt string
-}
-
-func newSqlLsh(k, l int, tableName string, db *sql.DB,
-	varFmt func(int) string,
-	createIndexFmt string) (*SqlLsh, error) {
+	k            int                   // Hash key size
+	l            int                   // Number of hash tables, or number of hash keys
+	tableName    string                // Name of the database table used
+	db           dbConn                // Database connection
+	ownDB        bool                  // Whether the database connection object is closed by Close
+	dialect      dialect               // Database specific parts of the SQL
+	softDelete   bool                  // Mark entries deleted instead of removing them
+	insertTime   bool                  // Record the insertion time of entries
+	autoID       bool                  // Let the database generate the IDs
+	namespaces   bool                  // Add a namespace column to the primary key
+	ensemble     []int                 // Upper set sizes of the partitions of WithEnsemble
+	part         int                   // Partition of the entries of an ensemble view
+	inPart       bool                  // Whether the view is scoped to a partition
+	scoped       bool                  // Whether the index is a view of the namespace ns
+	ns           int64                 // Namespace of a view
+	partitioning Partitioning          // How the table is partitioned by namespace
+	partitions   int                   // Number of hash partitions
+	packed       bool                  // Store each Signature in one column, and one key per band
+	keysOnly     bool                  // Do not store the Signatures with packed
+	keyHash      func(b []byte) uint64 // Hash of the hash keys with packed, nil for FNV-1a
+	quantizer    Quantizer             // Maps float hash values, nil if not used
+	buckets      *buckets              // Bucket width and offset of WithPStable, nil if not used
+	bits         int                   // Lowest bits stored of each hash value, 0 to store all
+	retryPolicy  *RetryPolicy          // Retries of transient errors, nil if not used
+	replicas     []dbConn              // Read replicas used for queries
+	next         uint32                // Counter for choosing the next read replica
+	cache        *queryCache           // Cache of query results, nil if not used
+	parentCache  *queryCache           // Cache of the index a view was made from, nil if none
+	bloom        *bandBloom            // Bloom filters of hash keys, nil if not used
+	hot          *hotKeys              // Hot hash keys left out of queries, nil if not used
+	progress     func(Progress)        // Reports the progress of long operations, nil if not used
+	logger       Logger                // Records the operations, nil if not used
+	tracer       Tracer                // Traces the operations, nil if not used
+	insertHooks  []InsertHook          // Called before inserting
+	queryHooks   []QueryHook           // Called before querying
+	listeners    []InsertListener      // Called after inserting
+	notify       string                // Notification channel of the inserts, empty if not used
+	dryRun       bool                  // Whether the DB is a Recorder
+	indexPrefix  string                // Prefix of the index names, derived from the table name if empty
+	filter       *filter               // Extra condition of the queries of a view made by Where, nil if none
+	latency      *latencyRecorder      // Recent durations of inserts and queries, nil if not used
+	writer       PurgeWriter           // Writes the rows of BatchInsert, nil if not used
+	writerPurges bool                  // Whether the writer purges the deleted entries itself
+	commitSize   int                   // Rows per transaction in BatchInsert and BulkLoad
+	conflict     Conflict              // Behavior when inserting an existing ID
+	plan         QueryPlan             // Shape of the query used to find candidates
+	adHoc        bool                  // Run queries without prepared statements
+	indexType    IndexType             // Kind of index created for each hash table
+	covering     bool                  // Include the id in the indexes
+	partial      bool                  // Leave deleted entries out of the indexes
+	autoAnalyze  bool                  // Run Analyze at the end of Index
+	workers      int                   // Goroutines inserting the chunks of a batch
+	parallelism  int                   // Hash tables queried concurrently by PlanParallel, 0 for the default
+	deferred     *deferredIndexes      // Whether queries wait for Index, nil if not used
+	tableKind    TableKind             // Durability of the table
+	sqlite       *SqliteOptions        // Storage parameters of SQLite, nil if not used
+	variant      PostgresVariant       // Compatible database used instead of PostgreSQL
+	placeholder  *Placeholder          // Style of the query parameters, nil for the one of the dialect
+	queryTimeout time.Duration         // Timeout of the collision queries, none if zero
+	insertStmt   *sql.Stmt
+	queryStmt    *sql.Stmt
+	countStmt    *sql.Stmt
+	scanStmt     *sql.Stmt
+	deleteStmt   *sql.Stmt
+	purgeStmt    *sql.Stmt
+}
+
+func newSqlLsh(k, l int, tableName string, db DB, d dialect,
+	opts []Option) (*SqlLsh, error) {
 	lsh := &SqlLsh{
-		k:              k,
-		l:              l,
-		tableName:      tableName,
-		db:             db,
-		varFmt:         varFmt,
-		createIndexFmt: createIndexFmt,
-	}
-	tx, err := db.Begin()
-	if err != nil {
+		k:         k,
+		l:         l,
+		tableName: tableName,
+		db:        dbConn{DB: db},
+		dialect:   d,
+	}
+	_, lsh.dryRun = db.(*Recorder)
+	for _, opt := range opts {
+		opt(lsh)
+	}
+	if lsh.variant != PostgresDefault {
+		adjust, ok := d.variants[lsh.variant]
+		if !ok {
+			return nil, ErrUnsupported
+		}
+		d = adjust(d)
+		lsh.dialect = d
+	}
+	if lsh.placeholder != nil {
+		f, ok := placeholderFmts[*lsh.placeholder]
+		if !ok || !d.placeholders {
+			return nil, ErrUnsupported
+		}
+		d.varFmt = f
+		lsh.dialect = d
+	}
+	if _, ok := d.indexMethods[lsh.indexType]; !ok {
+		return nil, ErrUnsupported
+	}
+	if lsh.conflict != ConflictError && d.conflictClause == nil && d.insertVerb == nil {
+		return nil, ErrUnsupported
+	}
+	if lsh.conflict == ConflictIdempotent && (lsh.keysOnly || lsh.writer != nil) {
+		return nil, ErrUnsupported
+	}
+	if pinned(db) && (lsh.workers > 1 || lsh.plan == PlanParallel || lsh.retryPolicy != nil) {
+		// They need several connections, or new transactions
+		return nil, ErrUnsupported
+	}
+	if lsh.notify != "" && d.notifyFmt == "" {
+		return nil, ErrUnsupported
+	}
+	if lsh.covering && lsh.indexType == IndexHash {
+		return nil, ErrUnsupported
+	}
+	if lsh.autoID && (d.autoIDType == "" || lsh.namespaces) {
+		return nil, ErrUnsupported
+	}
+	if lsh.ensemble != nil && !validBounds(lsh.ensemble) {
+		return nil, ErrInvalidParameter
+	}
+	if lsh.ensemble != nil && lsh.namespaces {
+		return nil, ErrUnsupported
+	}
+	if lsh.keysOnly && lsh.bloom != nil {
+		return nil, ErrUnsupported
+	}
+	if lsh.bits != 0 && (!validBits(lsh.bits) || lsh.packed) {
+		return nil, ErrInvalidParameter
+	}
+	if lsh.bits != 0 && (lsh.bloom != nil || lsh.cache != nil) {
+		// They compare full hash keys, which may differ when truncated
+		return nil, ErrUnsupported
+	}
+	if lsh.partitioning != PartitionNone && !d.partitions {
+		return nil, ErrUnsupported
+	}
+	if _, ok := d.tableKinds[lsh.tableKind]; lsh.tableKind != TablePermanent &&
+		(!ok || lsh.partitioning != PartitionNone) {
+		return nil, ErrUnsupported
+	}
+	if lsh.partitioning == PartitionHash && lsh.partitions < 1 {
+		return nil, ErrInvalidParameter
+	}
+	if lsh.sqlite != nil && (!d.pragmas || (lsh.sqlite.WithoutRowid && lsh.autoID)) {
+		return nil, ErrUnsupported
+	}
+	if err := lsh.setPragmas(); err != nil {
 		return nil, err
 	}
-	_, err = tx.Exec(lsh.createTableStr())
-	if err != nil {
-		tx.Rollback()
+	if err := lsh.createTable(); err != nil {
 		return nil, err
 	}
-	err = tx.Commit()
-	if err != nil {
-		tx.Rollback()
+	if err := lsh.checkTable(); err != nil {
 		return nil, err
 	}
-	// Prepare statments for later use
+	if err := lsh.migrate(); err != nil {
+		return nil, err
+	}
+	if err := lsh.loadBloom(); err != nil {
+		return nil, err
+	}
+	if err := lsh.prepare(); err != nil {
+		return nil, err
+	}
+	if lsh.deferred != nil {
+		lsh.deferred.built = lsh.checkIndexes() == nil
+	}
+	return lsh, nil
+}
+
+// prepare prepares the statments for later use.
+func (lsh *SqlLsh) prepare() error {
+	if lsh.adHoc {
+		return nil
+	}
+	var err error
 	lsh.insertStmt, err = lsh.createInsertStmt()
 	if err != nil {
-		return nil, err
+		return wrapErr("prepare", err)
 	}
 	lsh.queryStmt, err = lsh.createQueryStmt()
 	if err != nil {
-		return nil, err
+		return wrapErr("prepare", err)
+	}
+	lsh.countStmt, err = lsh.db.Prepare(lsh.countStr(lsh.allBands(), lsh.k))
+	if err != nil {
+		return wrapErr("prepare", err)
 	}
 	lsh.scanStmt, err = lsh.createScanStmt()
 	if err != nil {
-		return nil, err
+		return wrapErr("prepare", err)
 	}
-	lsh.indexStmts, err = lsh.createIndexStmts()
+	lsh.deleteStmt, err = lsh.createDeleteStmt()
+	if err != nil {
+		return wrapErr("prepare", err)
+	}
+	if lsh.softDelete {
+		lsh.purgeStmt, err = lsh.createPurgeStmt()
+		if err != nil {
+			return wrapErr("prepare", err)
+		}
+	}
+	return nil
+}
+
+// closeStmts closes the prepared statements.
+func (lsh *SqlLsh) closeStmts() {
+	stmts := []*sql.Stmt{lsh.insertStmt, lsh.queryStmt, lsh.countStmt,
+		lsh.scanStmt, lsh.deleteStmt, lsh.purgeStmt}
+	for _, stmt := range stmts {
+		if stmt != nil {
+			stmt.Close()
+		}
+	}
+}
+
+// createTable creates the table if it does not exist.
+func (lsh *SqlLsh) createTable() error {
+	if lsh.dialect.ddl != nil {
+		return wrapErr("create table", lsh.dialect.ddl(lsh.db.DB,
+			[]string{lsh.createTableStr(), lsh.metaTableStr()}))
+	}
+	stmts := append([]string{lsh.createTableStr()}, lsh.partitionStrs()...)
+	stmts = append(stmts, lsh.metaTableStr())
+	if lsh.dialect.probeTables {
+		stmts = nil
+		if !lsh.tableExists(lsh.tableName) {
+			stmts = append(stmts, lsh.createTableStr())
+		}
+		if !lsh.tableExists(lsh.metaTable()) {
+			stmts = append(stmts, lsh.metaTableStr())
+		}
+	}
+	tx, err := lsh.db.Begin()
+	if err != nil {
+		return wrapErr("create table", err)
+	}
+	for _, stmt := range stmts {
+		_, err = tx.Exec(stmt)
+		if err != nil {
+			tx.Rollback()
+			return wrapErr("create table", err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return wrapErr("create table", err)
+	}
+	return nil
+}
+
+// checkTable verifies that the table, which may have existed before,
+// has exactly the hash value columns of k and l.
+// The other columns are ignored, in any order, so users may add their
+// own columns to the table, as long as they are nullable or have a
+// default, since the index only writes and reads its own columns.
+func (lsh *SqlLsh) checkTable() error {
+	if lsh.dryRun {
+		// A Recorder returns no columns
+		return nil
+	}
+	cols, err := lsh.tableColumns()
+	if err != nil {
+		return wrapErr("check table", err)
+	}
+	want := make(map[string]bool)
+	for _, col := range lsh.hashCols() {
+		want[col] = true
+	}
+	prefix := strings.TrimRight(lsh.bandCols(0)[0], "0123456789")
+	n := 0
+	for _, col := range cols {
+		col = strings.ToLower(col)
+		if want[col] {
+			n++
+		} else if isHashCol(col, prefix) {
+			// A hash value column of other parameters
+			return ErrTableExists
+		}
+	}
+	if n != len(want) {
+		return ErrTableExists
+	}
+	return nil
+}
+
+// tableColumns returns the names of the columns of the table.
+func (lsh *SqlLsh) tableColumns() ([]string, error) {
+	rows, err := lsh.db.Query(fmt.Sprintf("SELECT * FROM %s WHERE 1 = 0", lsh.tableName))
 	if err != nil {
 		return nil, err
 	}
-	return lsh, nil
+	defer rows.Close()
+	return rows.Columns()
+}
+
+// isHashCol returns whether col is named like a hash value column,
+// the prefix followed by a number, rather than a column added to the
+// table by its users.
+func isHashCol(col, prefix string) bool {
+	n := strings.TrimPrefix(col, prefix)
+	return n != col && n != "" && strings.Trim(n, "0123456789") == ""
 }
 
 // Index builds l B-Tree multi-column indexes, each covers a
 // concatenated hash key.
 // This can improve the query performance of the LSH index.
 func (lsh *SqlLsh) Index() error {
+	done := lsh.observe("index")
+	err := lsh.index()
+	if err == nil {
+		lsh.deferred.set(true)
+	}
+	done(int64(lsh.l), err)
+	return err
+}
+
+func (lsh *SqlLsh) index() error {
+	start := time.Now()
+	if lsh.dialect.createIndexFmt == "" {
+		// The rows are sorted by hash keys instead
+		sortFmt := lsh.dialect.sortFmt
+		if lsh.l > 1 && lsh.dialect.interleavedSortFmt != "" {
+			sortFmt = lsh.dialect.interleavedSortFmt
+		}
+		if sortFmt != "" {
+			if _, err := lsh.db.Exec(fmt.Sprintf(sortFmt, lsh.tableName)); err != nil {
+				return wrapErr("index", err)
+			}
+		}
+		lsh.report("index", lsh.l, lsh.l, start)
+		if lsh.autoAnalyze {
+			return lsh.Analyze()
+		}
+		return nil
+	}
+	if lsh.dialect.ddl != nil {
+		stmts := make([]string, lsh.l)
+		for i := range stmts {
+			stmts[i] = lsh.indexStr(i)
+		}
+		if err := lsh.dialect.ddl(lsh.db.DB, stmts); err != nil {
+			return wrapErr("index", err)
+		}
+		lsh.report("index", lsh.l, lsh.l, start)
+		if lsh.autoAnalyze {
+			return lsh.Analyze()
+		}
+		return nil
+	}
+	if lsh.dialect.implicitCommit {
+		// Each CREATE INDEX commits, so they run one at a time, and
+		// those created before a failure are kept
+		for i := 0; i < lsh.l; i++ {
+			if _, err := lsh.db.Exec(lsh.indexStr(i)); err != nil {
+				return wrapErr("index", err)
+			}
+			lsh.report("index", i+1, lsh.l, start)
+		}
+		if lsh.autoAnalyze {
+			return lsh.Analyze()
+		}
+		return nil
+	}
 	tx, err := lsh.db.Begin()
 	if err != nil {
-		return err
+		return wrapErr("index", err)
 	}
-	for i := range lsh.indexStmts {
-		_, err = tx.Stmt(lsh.indexStmts[i]).Exec()
+	for i := 0; i < lsh.l; i++ {
+		_, err = tx.Exec(lsh.indexStr(i))
 		if err != nil {
 			tx.Rollback()
-			return err
+			return wrapErr("index", err)
 		}
+		lsh.report("index", i+1, lsh.l, start)
 	}
 	err = tx.Commit()
 	if err != nil {
 		tx.Rollback()
-		return err
+		return wrapErr("index", err)
+	}
+	if lsh.autoAnalyze {
+		return lsh.Analyze()
 	}
 	return nil
 }
@@ -115,29 +426,41 @@ func (lsh *SqlLsh) Index() error {
 // Insert appends a new Signature with id to the table.
 // The size of the new Signature must equal to k*l.
 func (lsh *SqlLsh) Insert(id int, sig Signature) error {
-	if len(sig) != lsh.k*lsh.l {
-		return errors.New("Signature size mismatch")
+	done := lsh.observe("insert")
+	sigs, err := lsh.beforeInsert("insert", []int{id}, []Signature{sig})
+	if err == nil {
+		err = lsh.retry("insert", func() error {
+			return lsh.insert(id, sigs[0])
+		})
 	}
-	row := make([]interface{}, len(sig)+1)
-	row[0] = interface{}(id)
-	for i := 0; i < len(sig); i++ {
-		row[i+1] = interface{}(sig[i])
+	done(1, err)
+	return err
+}
+
+func (lsh *SqlLsh) insert(id int, sig Signature) error {
+	if len(sig) != lsh.k*lsh.l {
+		return ErrSignatureSizeMismatch
 	}
+	lsh.bloom.add(lsh.k, sig)
 	// Begin transcation for insert
 	tx, err := lsh.db.Begin()
 	if err != nil {
-		return err
+		return wrapErr("insert", err)
+	}
+	err = lsh.insertRow(tx.Tx, id, sig)
+	if err == nil {
+		err = lsh.afterInsert(tx, []int{id}, []Signature{sig})
 	}
-	_, err = tx.Stmt(lsh.insertStmt).Exec(row...)
 	if err != nil {
 		tx.Rollback()
-		return err
+		return wrapErr("insert", err)
 	}
 	err = tx.Commit()
 	if err != nil {
 		tx.Rollback()
-		return err
+		return wrapErr("insert", err)
 	}
+	lsh.inserted([]int{id}, []Signature{sig})
 	return nil
 }
 
@@ -146,35 +469,126 @@ func (lsh *SqlLsh) Insert(id int, sig Signature) error {
 // same position.
 // BatchInsert is more efficient than Insert for inserting multiple
 // Signatures at the same time.
+// By default all Signatures are inserted in one transaction.
+// With WithCommitSize the Signatures are committed in chunks instead,
+// so if an error occurs after the first chunk, the Signatures before
+// the failed chunk stay inserted, and a *PartialInsertError is
+// returned.
+// With WithInsertWorkers the chunks are inserted concurrently.
 func (lsh *SqlLsh) BatchInsert(ids []int, sigs []Signature) error {
-	if len(sigs) != len(ids) {
-		return errors.New("Number of signatures and ids mismatch")
+	done := lsh.observe("batch insert")
+	sigs, err := lsh.beforeInsert("batch insert", ids, sigs)
+	if err == nil {
+		err = lsh.insertBatch(ids, sigs)
+	}
+	done(int64(len(sigs)), err)
+	return err
+}
+
+func (lsh *SqlLsh) insertBatch(ids []int, sigs []Signature) error {
+	if err := validateBatch(ids, sigs, lsh.k*lsh.l); err != nil {
+		return err
+	}
+	lsh.bloom.add(lsh.k, sigs...)
+	start := time.Now()
+	if lsh.workers > 1 {
+		return lsh.insertParallel(ids, sigs, start)
+	}
+	size := lsh.batchSize(len(sigs))
+	for i := 0; i < len(sigs); i += size {
+		end := i + size
+		if end > len(sigs) {
+			end = len(sigs)
+		}
+		err := lsh.retry("batch insert", func() error {
+			return lsh.batchInsert(ids, sigs, i, end, start)
+		})
+		if err != nil {
+			if i > 0 {
+				return &PartialInsertError{Committed: i, Err: err}
+			}
+			return err
+		}
 	}
-	if len(sigs[0]) != lsh.k*lsh.l {
-		return errors.New("Signature size mismatch")
+	lsh.report("batch insert", len(sigs), len(sigs), start)
+	return nil
+}
+
+// batchInsert inserts the Signatures from position begin to end in one
+// transaction.
+func (lsh *SqlLsh) batchInsert(ids []int, sigs []Signature, begin, end int,
+	start time.Time) error {
+	if lsh.writer != nil {
+		return lsh.writeBatch(ids, sigs, begin, end)
 	}
 	// Begin transcation for insert
 	tx, err := lsh.db.Begin()
 	if err != nil {
-		return err
+		return wrapErr("batch insert", err)
 	}
-	for i := range sigs {
-		row := make([]interface{}, lsh.l*lsh.k+1)
-		row[0] = interface{}(ids[i])
-		for j := 0; j < len(sigs[i]); j++ {
-			row[j+1] = interface{}(sigs[i][j])
+	if err := lsh.waitLock(tx.Tx); err != nil {
+		tx.Rollback()
+		return wrapErr("batch insert", err)
+	}
+	step := 1
+	if n := lsh.multiRowSize(); n > 1 {
+		step = n
+	}
+	for i := begin; i < end; i += step {
+		j := i + step
+		if j > end {
+			j = end
+		}
+		if step == 1 {
+			err = lsh.insertRow(tx.Tx, ids[i], sigs[i])
+		} else {
+			err = lsh.insertRows(tx.Tx, ids[i:j], sigs[i:j])
 		}
-		_, err = tx.Stmt(lsh.insertStmt).Exec(row...)
 		if err != nil {
 			tx.Rollback()
-			return err
+			return wrapErr("batch insert", err)
+		}
+		if lsh.workers <= 1 && j/progressInterval > i/progressInterval && j < len(sigs) {
+			lsh.report("batch insert", j, len(sigs), start)
 		}
 	}
+	err = lsh.afterInsert(tx, ids[begin:end], sigs[begin:end])
+	if err != nil {
+		tx.Rollback()
+		return wrapErr("batch insert", err)
+	}
 	err = tx.Commit()
 	if err != nil {
 		tx.Rollback()
+		return wrapErr("batch insert", err)
+	}
+	lsh.inserted(ids[begin:end], sigs[begin:end])
+	return nil
+}
+
+// insertRow inserts a Signature inside tx.
+func (lsh *SqlLsh) insertRow(tx *sql.Tx, id int, sig Signature) error {
+	if err := lsh.purge(tx, id); err != nil {
 		return err
 	}
+	_, err := lsh.exec(tx, lsh.insertStmt, lsh.insertStr(), lsh.insertArgs(id, sig)...)
+	return err
+}
+
+// validateBatch checks the input of a batch insert before anything is
+// written.
+func validateBatch(ids []int, sigs []Signature, size int) error {
+	if len(sigs) != len(ids) {
+		return ErrCountMismatch
+	}
+	if len(sigs) == 0 {
+		return ErrEmptyBatch
+	}
+	for i := range sigs {
+		if len(sigs[i]) != size {
+			return &SignatureSizeError{Index: i, Size: len(sigs[i]), Want: size}
+		}
+	}
 	return nil
 }
 
@@ -183,118 +597,507 @@ func (lsh *SqlLsh) BatchInsert(ids []int, sigs []Signature) error {
 // IDs to a given output channel.
 // The caller is responsible for closing the channel.
 func (lsh *SqlLsh) Query(sig Signature, out chan int) error {
+	return lsh.query(sig, func(id int) {
+		out <- id
+	})
+}
+
+// QueryIDs is like Query, but returns the IDs in a slice
+// once the query has finished.
+func (lsh *SqlLsh) QueryIDs(sig Signature) ([]int, error) {
+	ids := make([]int, 0)
+	err := lsh.query(sig, func(id int) {
+		ids = append(ids, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// query runs the collision query and calls emit for each ID found.
+// Results are served from and added to the query cache, if it is used.
+func (lsh *SqlLsh) query(sig Signature, emit func(int)) error {
+	done := lsh.observe("query")
+	var n int64
+	sig, err := lsh.beforeQuery("query", sig)
+	if err == nil {
+		err = lsh.runQuery(sig, func(id int) {
+			n++
+			emit(id)
+		})
+	}
+	done(n, err)
+	return err
+}
+
+func (lsh *SqlLsh) runQuery(sig Signature, emit func(int)) error {
+	// Checked before the cache, which only reads whole hash keys
 	if len(sig) != lsh.k*lsh.l {
-		return errors.New("Signature size mismatch")
+		return ErrSignatureSizeMismatch
 	}
-	row := make([]interface{}, len(sig))
-	for i := 0; i < len(sig); i++ {
-		row[i] = interface{}(sig[i])
+	if ids, ok := lsh.cache.get(sig, lsh.k); ok {
+		for _, id := range ids {
+			emit(id)
+		}
+		return nil
+	}
+	gen := lsh.cache.generation()
+	if lsh.queryPlan() == PlanParallel {
+		var ids []int
+		err := lsh.retry("query", func() error {
+			var err error
+			ids, err = lsh.parallelQuery(sig)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			emit(id)
+		}
+		lsh.cache.put(sig, lsh.k, ids, gen)
+		return nil
 	}
-	rows, err := lsh.queryStmt.Query(row...)
+	// Each attempt has its own session, as a failed query aborts the
+	// transaction of a session on PostgreSQL
+	var s *querySession
+	defer func() {
+		s.end()
+	}()
+	var rows *sql.Rows
+	err := lsh.retry("query", func() error {
+		s.end()
+		var err error
+		if s, err = lsh.beginQuery(); err != nil {
+			return err
+		}
+		rows, err = lsh.queryRows(s, sig)
+		return err
+	})
 	if err != nil {
 		return err
 	}
+	if rows == nil {
+		return nil
+	}
 	defer rows.Close()
+	var ids []int
 	for rows.Next() {
 		var id int
 		err = rows.Scan(&id)
 		if err != nil {
-			return err
+			return wrapErr("query", err)
 		}
-		out <- id
+		if lsh.cache != nil {
+			ids = append(ids, id)
+		}
+		emit(id)
 	}
-	err = rows.Err()
-	return err
+	if err := rows.Err(); err != nil {
+		return wrapErr("query", err)
+	}
+	lsh.cache.put(sig, lsh.k, ids, gen)
+	return nil
+}
+
+// queryRows runs the collision query in the session s, the caller must
+// close the rows.
+// The rows are nil if the Bloom filters and the hot hash keys leave no
+// hash table to query.
+func (lsh *SqlLsh) queryRows(s *querySession, sig Signature) (*sql.Rows, error) {
+	if len(sig) != lsh.k*lsh.l {
+		return nil, ErrSignatureSizeMismatch
+	}
+	if err := lsh.ready(); err != nil {
+		return nil, err
+	}
+	bands := lsh.maybeBands(sig)
+	if bands != nil && len(bands) == 0 {
+		return nil, nil
+	}
+	if bands != nil && len(bands) < lsh.l {
+		rows, err := s.query(nil, lsh.bandsQueryStr(bands, lsh.k),
+			lsh.queryArgs(sig, bands, lsh.k)...)
+		if err != nil {
+			return nil, wrapErr("query", err)
+		}
+		return rows, nil
+	}
+	rows, err := s.query(lsh.queryStmt, lsh.queryStr(), lsh.queryArgs(sig, lsh.allBands(), lsh.k)...)
+	if err != nil {
+		return nil, wrapErr("query", err)
+	}
+	return rows, nil
+}
+
+// bandsArgs returns the arguments of the query on the given bands,
+// using the first prefix hash values of each hash key.
+func (lsh *SqlLsh) bandsArgs(sig Signature, bands []int, prefix int) []interface{} {
+	args := make([]interface{}, 0, len(bands)*prefix)
+	for _, band := range bands {
+		args = append(args, lsh.bandValues(sig, band)[:lsh.bandWidth(prefix)]...)
+	}
+	return args
 }
 
+// Entry is an ID and its Signature.
+// The struct tags name the columns of the compact layout, so that the
+// rows of the Scan statement can be read with github.com/jmoiron/sqlx.
 type Entry struct {
-	Id        int
-	Signature Signature
+	Id        int       `db:"id"`
+	Signature Signature `db:"sig"`
 }
 
 func (lsh *SqlLsh) Scan(out chan Entry) error {
-	row := make([]interface{}, lsh.k*lsh.l+1)
-	rowPtr := make([]interface{}, lsh.k*lsh.l+1)
-	for i := range row {
-		rowPtr[i] = &row[i]
-	}
-	rows, err := lsh.scanStmt.Query()
+	rows, err := lsh.read(lsh.scanStmt, lsh.scanStr())
 	if err != nil {
-		return err
+		return wrapErr("scan", err)
 	}
 	defer rows.Close()
 	for rows.Next() {
-		if err := rows.Scan(rowPtr...); err != nil {
+		e, err := lsh.scanEntry(rows)
+		if err != nil {
 			return err
 		}
-		id := int(row[0].(int64))
-		sig := make(Signature, len(row)-1)
-		for i := range sig {
-			sig[i] = uint(row[i+1].(int64))
+		out <- e
+	}
+	return wrapErr("scan", rows.Err())
+}
+
+// scanEntry reads the Entry at the current row.
+func (lsh *SqlLsh) scanEntry(rows *sql.Rows) (Entry, error) {
+	e, err := lsh.scanRow(rows)
+	if err != nil {
+		return Entry{}, wrapErr("scan", err)
+	}
+	return e, nil
+}
+
+// scanRow reads the Entry in row, selected using entryCols.
+func (lsh *SqlLsh) scanRow(row interface{ Scan(...interface{}) error }) (Entry, error) {
+	if lsh.keysOnly {
+		var e Entry
+		err := row.Scan(&e.Id)
+		return e, err
+	}
+	if lsh.packed {
+		var e Entry
+		var blob []byte
+		if err := row.Scan(&e.Id, &blob); err != nil {
+			return Entry{}, err
 		}
-		out <- Entry{
-			Id:        id,
-			Signature: sig,
+		if blob == nil {
+			return Entry{}, &NullValueError{Id: e.Id, Column: "sig"}
 		}
+		e.Signature = decodeSignature(blob)
+		return e, nil
 	}
-	if err := rows.Err(); err != nil {
-		return err
+	cols := make([]interface{}, len(lsh.bandCols(0))*lsh.l+1)
+	colPtr := make([]interface{}, len(cols))
+	for i := range cols {
+		colPtr[i] = &cols[i]
 	}
-	return nil
+	if err := row.Scan(colPtr...); err != nil {
+		return Entry{}, err
+	}
+	n, err := intValue(cols[0])
+	if err != nil {
+		return Entry{}, err
+	}
+	sig := make(Signature, len(cols)-1)
+	for i := range sig {
+		if cols[i+1] == nil {
+			return Entry{}, &NullValueError{Id: int(n), Column: lsh.hashCols()[i]}
+		}
+		v, err := intValue(cols[i+1])
+		if err != nil {
+			return Entry{}, err
+		}
+		sig[i] = uint(v)
+	}
+	if lsh.bits > 0 {
+		sig = lsh.unpackBits(sig)
+	}
+	return Entry{
+		Id:        int(n),
+		Signature: sig,
+	}, nil
+}
+
+// intValue converts a value of an integer column, as returned by the
+// driver, to an int64.
+// Drivers return integers as int64, as other integer types, as float64,
+// or as decimal text in []byte or string, such as the text protocol of
+// MySQL or NUMBER columns of Oracle. An unsigned value beyond the range
+// of int64 keeps its bits, as the hash values are stored, and a value
+// beyond the range of uint64 is an error.
+func intValue(v interface{}) (int64, error) {
+	switch v := v.(type) {
+	case int64:
+		return v, nil
+	case int32:
+		return int64(v), nil
+	case int:
+		return int64(v), nil
+	case uint64:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case uint:
+		return int64(v), nil
+	case float64:
+		switch {
+		case v != math.Trunc(v):
+		case v >= -(1<<63) && v < 1<<63:
+			return int64(v), nil
+		case v >= 0 && v < 1<<64:
+			return int64(uint64(v)), nil
+		}
+	case []byte:
+		return parseInt(string(v))
+	case string:
+		return parseInt(v)
+	case fmt.Stringer:
+		return parseInt(v.String())
+	}
+	return 0, fmt.Errorf("sqllsh: cannot convert %T to an integer", v)
+}
+
+// parseInt parses a decimal integer, which may be unsigned beyond the
+// range of int64.
+func parseInt(s string) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err == nil {
+		return n, nil
+	}
+	u, uerr := strconv.ParseUint(s, 10, 64)
+	if uerr != nil {
+		return 0, err
+	}
+	return int64(u), nil
 }
 
 func (lsh *SqlLsh) createTableStr() string {
-	createSeg := make([]string, lsh.k*lsh.l+1)
-	createSeg[0] = "id INTEGER PRIMARY KEY"
-	for i := 0; i < lsh.k*lsh.l; i++ {
-		createSeg[i+1] = fmt.Sprintf("hv_%d BIGINT", i)
+	if lsh.dialect.createTable != nil {
+		return lsh.dialect.createTable(lsh)
+	}
+	createSeg := []string{"id INTEGER PRIMARY KEY"}
+	if lsh.autoID {
+		createSeg[0] = "id " + lsh.dialect.autoIDType
+	}
+	if lsh.namespaces {
+		createSeg[0] = "id INTEGER NOT NULL"
+	}
+	createSeg = append(createSeg, lsh.valueColDefs(lsh.dialect.intType, lsh.dialect.blobType)...)
+	if lsh.softDelete {
+		createSeg = append(createSeg, "deleted INTEGER DEFAULT 0 NOT NULL")
 	}
-	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n", lsh.tableName) +
-		strings.Join(createSeg, ",\n") + "\n);\n"
+	if lsh.insertTime {
+		createSeg = append(createSeg, "inserted_at "+lsh.dialect.intType+" DEFAULT 0 NOT NULL")
+	}
+	if lsh.ensemble != nil {
+		createSeg = append(createSeg, "part "+lsh.dialect.intType+" DEFAULT 0 NOT NULL")
+	}
+	if lsh.namespaces {
+		createSeg = append(createSeg, "ns "+lsh.dialect.intType+" DEFAULT 0 NOT NULL",
+			"PRIMARY KEY (ns, id)")
+	}
+	return fmt.Sprintf("%s %s (\n", lsh.createTablePrefix(), lsh.tableName) +
+		strings.Join(createSeg, ",\n") + "\n)" + lsh.partitionClause() + lsh.withoutRowid() +
+		lsh.tableOptions()
 }
 
-func (lsh *SqlLsh) createIndexStmts() ([]*sql.Stmt, error) {
-	indexStmts := make([]*sql.Stmt, lsh.l)
-	seg := make([]string, lsh.k)
-	for i := 0; i < lsh.l; i++ {
-		for j := 0; j < lsh.k; j++ {
-			seg[j] = fmt.Sprintf("hv_%d", lsh.k*i+j)
-		}
-		stmt, err := lsh.db.Prepare(fmt.Sprintf(lsh.createIndexFmt, i, lsh.tableName) +
-			strings.Join(seg, ",") + ");")
-		if err != nil {
-			return nil, err
-		}
-		indexStmts[i] = stmt
+// tableOptions returns the database specific clause of CREATE TABLE.
+func (lsh *SqlLsh) tableOptions() string {
+	if lsh.dialect.tableOptions == nil {
+		return ""
 	}
-	return indexStmts, nil
+	return lsh.dialect.tableOptions(lsh)
+}
+
+// indexStr returns the statement creating the index of hash table i.
+// It is not prepared, as some databases check at preparation that the
+// index does not exist yet.
+func (lsh *SqlLsh) indexStr(i int) string {
+	seg := lsh.bandCols(i)[:lsh.indexCols()]
+	if lsh.namespaces && lsh.indexType == IndexBTree {
+		// Queries of a view are scoped to a namespace
+		seg = append([]string{"ns"}, seg...)
+	}
+	if lsh.ensemble != nil && lsh.indexType == IndexBTree {
+		// Queries of an ensemble are scoped to a partition
+		seg = append([]string{"part"}, seg...)
+	}
+	return fmt.Sprintf(lsh.dialect.createIndexFmt, lsh.indexName(i), lsh.tableName) +
+		lsh.dialect.indexMethods[lsh.indexType] + " (" + strings.Join(seg, ",") + ")" +
+		lsh.indexSuffix()
 }
 
 func (lsh *SqlLsh) createInsertStmt() (*sql.Stmt, error) {
-	insertSeg := make([]string, lsh.k*lsh.l+1)
-	for i := range insertSeg {
-		insertSeg[i] = lsh.varFmt(i)
+	return lsh.db.Prepare(lsh.insertStr())
+}
+
+// insertCols returns the columns set by an insert, in the order of
+// insertArgs.
+func (lsh *SqlLsh) insertCols() []string {
+	cols := strings.Split(lsh.columnList(), ",")
+	if lsh.insertTime {
+		cols = append(cols, "inserted_at")
 	}
-	stmt, err := lsh.db.Prepare(fmt.Sprintf("INSERT INTO %s VALUES(", lsh.tableName) +
-		strings.Join(insertSeg, ",") + ");")
-	return stmt, err
+	if lsh.scoped {
+		cols = append(cols, "ns")
+	}
+	if lsh.inPart {
+		cols = append(cols, "part")
+	}
+	return cols
+}
+
+func (lsh *SqlLsh) insertStr() string {
+	return lsh.insertRowsStr(1)
+}
+
+// insertRowsStr returns the statement inserting n rows, with the
+// arguments of insertArgs for each row in turn.
+func (lsh *SqlLsh) insertRowsStr(n int) string {
+	cols := lsh.insertCols()
+	rowSegs := make([]string, n)
+	for r := range rowSegs {
+		insertSeg := make([]string, len(cols))
+		for i := range insertSeg {
+			insertSeg[i] = lsh.dialect.varFmt(r*len(cols) + i)
+		}
+		rowSegs[r] = "(" + strings.Join(insertSeg, ",") + ")"
+	}
+	verb := "INSERT INTO"
+	if lsh.dialect.insertVerb != nil {
+		verb = lsh.dialect.insertVerb(lsh.sqlConflict())
+	}
+	s := fmt.Sprintf("%s %s (%s) VALUES", verb,
+		lsh.tableName, strings.Join(cols, ",")) +
+		strings.Join(rowSegs, ",")
+	if lsh.dialect.conflictClause != nil {
+		s += lsh.dialect.conflictClause(lsh.sqlConflict(), lsh.keyCols(), cols[1:])
+	}
+	return s
 }
 
 func (lsh *SqlLsh) createQueryStmt() (*sql.Stmt, error) {
-	querySeg := make([]string, lsh.l)
-	seg := make([]string, lsh.k)
-	for i := 0; i < lsh.l; i++ {
-		for j := 0; j < lsh.k; j++ {
-			k := lsh.k*i + j
-			seg[j] = fmt.Sprintf("hv_%d = %s", k, lsh.varFmt(k))
+	return lsh.db.Prepare(lsh.queryStr())
+}
+
+func (lsh *SqlLsh) queryStr() string {
+	return lsh.bandsQueryStr(lsh.allBands(), lsh.k)
+}
+
+// allBands returns the numbers of all hash tables.
+func (lsh *SqlLsh) allBands() []int {
+	bands := make([]int, lsh.l)
+	for i := range bands {
+		bands[i] = i
+	}
+	return bands
+}
+
+// bandsQueryStr returns the collision query on the given bands only,
+// using the first prefix hash values of each hash key.
+// The arguments are given by queryArgs.
+func (lsh *SqlLsh) bandsQueryStr(bands []int, prefix int) string {
+	filter := lsh.filterCond(len(bands) * lsh.bandWidth(prefix))
+	if p := lsh.queryPlan(); p == PlanUnion || p == PlanParallel {
+		if filter == "" {
+			return lsh.unionQueryStr(bands, prefix)
 		}
-		querySeg[i] = "(" + strings.Join(seg, " AND ") + ")"
+		return fmt.Sprintf("SELECT DISTINCT id FROM %s WHERE %sid IN (%s)%s",
+			lsh.tableName, lsh.liveCond(), lsh.unionQueryStr(bands, prefix), filter)
 	}
-	stmt, err := lsh.db.Prepare(fmt.Sprintf("SELECT DISTINCT id FROM %s WHERE", lsh.tableName) +
-		strings.Join(querySeg, " OR ") + ";")
-	return stmt, err
+	return fmt.Sprintf("SELECT DISTINCT id FROM %s WHERE %s%s",
+		lsh.tableName, lsh.bandsCond(bands, prefix), filter)
+}
+
+// bandsCond returns the condition of the collision query on the given
+// bands, using the first prefix hash values of each hash key.
+// The arguments are given by bandsArgs.
+func (lsh *SqlLsh) bandsCond(bands []int, prefix int) string {
+	querySeg := make([]string, len(bands))
+	for i, band := range bands {
+		querySeg[i] = "(" + lsh.bandCond(band, prefix, lsh.bandWidth(prefix)*i) + ")"
+	}
+	return lsh.liveCond() + "(" + strings.Join(querySeg, " OR ") + ")"
+}
+
+// bandCond returns the condition matching the first prefix hash values
+// of the hash key of band, with placeholders numbered from offset.
+func (lsh *SqlLsh) bandCond(band, prefix, offset int) string {
+	cols := lsh.bandCols(band)[:lsh.bandWidth(prefix)]
+	seg := make([]string, len(cols))
+	for j, col := range cols {
+		seg[j] = fmt.Sprintf("%s = %s", col, lsh.dialect.varFmt(offset+j))
+	}
+	return strings.Join(seg, " AND ")
 }
 
 func (lsh *SqlLsh) createScanStmt() (*sql.Stmt, error) {
-	return lsh.db.Prepare(fmt.Sprintf("SELECT * FROM %s;", lsh.tableName))
+	return lsh.db.Prepare(lsh.scanStr())
+}
+
+func (lsh *SqlLsh) scanStr() string {
+	where := ""
+	if cond := lsh.liveCond(); cond != "" {
+		where = " WHERE " + strings.TrimSuffix(cond, " AND ")
+	}
+	return fmt.Sprintf("SELECT %s FROM %s%s", lsh.entryCols(), lsh.tableName, where)
+}
+
+// insertArgs returns the arguments of the insert statement.
+func (lsh *SqlLsh) insertArgs(id int, sig Signature) []interface{} {
+	row := []interface{}{id}
+	if lsh.packed && !lsh.keysOnly {
+		row = append(row, encodeSignature(sig))
+	}
+	for i := 0; i < lsh.l; i++ {
+		row = append(row, lsh.bandValues(sig, i)...)
+	}
+	if lsh.insertTime {
+		row = append(row, time.Now().UnixNano())
+	}
+	if lsh.scoped {
+		row = append(row, lsh.ns)
+	}
+	if lsh.inPart {
+		row = append(row, lsh.part)
+	}
+	return row
+}
+
+// columnList returns the comma-separated id and hash value columns.
+func (lsh *SqlLsh) columnList() string {
+	cols := []string{"id"}
+	if lsh.packed && !lsh.keysOnly {
+		cols = append(cols, "sig")
+	}
+	cols = append(cols, lsh.hashCols()...)
+	return strings.Join(cols, ",")
+}
+
+// entryCols returns the comma-separated columns read by scanRow.
+func (lsh *SqlLsh) entryCols() string {
+	if lsh.keysOnly {
+		return "id"
+	}
+	if lsh.packed {
+		return "id,sig"
+	}
+	return lsh.columnList()
+}
+
+// liveCond returns the condition, followed by AND, that excludes
+// deleted entries and the entries outside the namespace of a view, or
+// an empty string if neither applies.
+func (lsh *SqlLsh) liveCond() string {
+	if lsh.softDelete {
+		return lsh.scopeCond() + "deleted = 0 AND "
+	}
+	return lsh.scopeCond()
 }
@@ -2,7 +2,9 @@ package sqllsh
 
 import (
 	"database/sql"
+	"errors"
 	"io/ioutil"
+	"math"
 	"math/rand"
 	"os"
 	"testing"
@@ -110,6 +112,41 @@ func Test_Query(t *testing.T) {
 	removeTempFile(t, f)
 }
 
+func Test_QueryIDs(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Error(err)
+	}
+	sigs := randomSigs(10, 4)
+	for i := range sigs {
+		lsh.Insert(i, sigs[i])
+	}
+	for i := range sigs {
+		ids, err := lsh.QueryIDs(sigs[i])
+		if err != nil {
+			t.Error(err)
+		}
+		found := false
+		for _, id := range ids {
+			if id == i {
+				found = true
+			}
+		}
+		if !found {
+			t.Error("Error in query")
+		}
+	}
+	if _, err := lsh.QueryIDs(Signature{1, 2}); err != ErrSignatureSizeMismatch {
+		t.Error("Fail to raise error")
+	}
+	removeTempFile(t, f)
+}
+
 func Test_Scan(t *testing.T) {
 	f := creatTempFile(t)
 	db, err := sql.Open("sqlite3", f.Name())
@@ -128,7 +165,7 @@ func Test_Scan(t *testing.T) {
 	go func() {
 		err := lsh.Scan(out)
 		if err != nil {
-			t.Fatal(err)
+			t.Error(err)
 		}
 		close(out)
 	}()
@@ -144,3 +181,212 @@ func Test_Scan(t *testing.T) {
 	}
 	removeTempFile(t, f)
 }
+
+func Test_BatchInsert(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Error(err)
+	}
+	err = lsh.BatchInsert([]int{}, []Signature{})
+	if err != ErrEmptyBatch {
+		t.Error("Fail to raise error on empty batch")
+	}
+	sigs := randomSigs(3, 4)
+	sigs[2] = sigs[2][:3]
+	err = lsh.BatchInsert([]int{0, 1, 2}, sigs)
+	if sizeErr, ok := err.(*SignatureSizeError); !ok || sizeErr.Index != 2 {
+		t.Errorf("Expected SignatureSizeError at index 2, got %v", err)
+	}
+	if !errors.Is(err, ErrSignatureSizeMismatch) {
+		t.Error("SignatureSizeError should unwrap to ErrSignatureSizeMismatch")
+	}
+	out := make(chan Entry)
+	go func() {
+		if err := lsh.Scan(out); err != nil {
+			t.Error(err)
+		}
+		close(out)
+	}()
+	for _ = range out {
+		t.Error("Invalid batch should not be inserted")
+	}
+	err = lsh.BatchInsert([]int{0, 1}, randomSigs(2, 4))
+	if err != nil {
+		t.Error(err)
+	}
+	removeTempFile(t, f)
+}
+
+func Test_InsertDuplicate(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Error(err)
+	}
+	if err := lsh.Insert(1, []uint{0, 1, 2, 3}); err != nil {
+		t.Error(err)
+	}
+	err = lsh.Insert(1, []uint{4, 5, 6, 7})
+	if !errors.Is(err, ErrIDExists) {
+		t.Errorf("Expected ErrIDExists, got %v", err)
+	}
+	if opErr, ok := err.(*OpError); !ok || opErr.Op != "insert" {
+		t.Errorf("Expected OpError for insert, got %v", err)
+	}
+	removeTempFile(t, f)
+}
+
+func Test_TableExists(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	if _, err := NewSqliteLsh(2, 2, "lshtable", db); err != nil {
+		t.Error(err)
+	}
+	if _, err := NewSqliteLsh(2, 2, "lshtable", db); err != nil {
+		t.Error(err)
+	}
+	if _, err := NewSqliteLsh(2, 4, "lshtable", db); err != ErrTableExists {
+		t.Errorf("Expected ErrTableExists, got %v", err)
+	}
+	removeTempFile(t, f)
+}
+
+func Test_ExtraColumns(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// The columns are out of order, with columns added by users
+	if _, err := db.Exec(`CREATE TABLE lshtable (title TEXT, hv_3 INTEGER, hv_note TEXT,
+		id INTEGER PRIMARY KEY, hv_1 INTEGER, hv_0 INTEGER, hv_2 INTEGER)`); err != nil {
+		t.Fatal(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := Signature{1, 2, 3, 4}
+	if err := lsh.Insert(1, sig); err != nil {
+		t.Fatal(err)
+	}
+	it, err := lsh.ScanIter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for it.Next() {
+		if e := it.Value(); e.Id != 1 || !sameSig(e.Signature, sig) {
+			t.Errorf("Expected 1 %v, got %d %v", sig, e.Id, e.Signature)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Error(err)
+	}
+	ids, err := lsh.QueryIDs(Signature{1, 2, 5, 6})
+	if err != nil || len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("Expected [1], got %v, %v", ids, err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Validate(); err != nil {
+		t.Error(err)
+	}
+	// A hash value column of other parameters is not a user column
+	if _, err := db.Exec("ALTER TABLE lshtable ADD COLUMN hv_4 INTEGER"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSqliteLsh(2, 2, "lshtable", db); err != ErrTableExists {
+		t.Errorf("Expected ErrTableExists, got %v", err)
+	}
+}
+
+// Test_IntValue covers the values returned for BIGINT columns by the
+// Sqlite, PostgreSQL and MySQL drivers, and by the text protocols.
+func Test_IntValue(t *testing.T) {
+	values := []interface{}{int64(-3), int32(-3), -3, []byte("-3"), "-3", float64(-3)}
+	for _, v := range values {
+		if n, err := intValue(v); err != nil || n != -3 {
+			t.Errorf("Expected -3 for %T, got %d, %v", v, n, err)
+		}
+	}
+	// A hash value stored with its high bit set
+	for _, v := range []interface{}{uint64(1 << 63), []byte("9223372036854775808")} {
+		if n, err := intValue(v); err != nil || uint(n) != 1<<63 {
+			t.Errorf("Expected 1<<63 for %T, got %d, %v", v, n, err)
+		}
+	}
+	for _, v := range []interface{}{nil, []byte("x"), 1.5, true} {
+		if _, err := intValue(v); err == nil {
+			t.Errorf("Expected an error for %v", v)
+		}
+	}
+}
+
+// Test_IntValueFloat covers the floating-point values of the drivers
+// returning NUMBER and DOUBLE PRECISION columns as float64.
+func Test_IntValueFloat(t *testing.T) {
+	if n, err := intValue(float64(1 << 63)); err != nil || uint64(n) != 1<<63 {
+		t.Errorf("Expected 1<<63, got %d, %v", n, err)
+	}
+	if n, err := intValue(float64(-1 << 63)); err != nil || n != math.MinInt64 {
+		t.Errorf("Expected %d, got %d, %v", int64(math.MinInt64), n, err)
+	}
+	if n, err := intValue(1e19); err != nil || uint64(n) != 1e19 {
+		t.Errorf("Expected 1e19, got %d, %v", n, err)
+	}
+	for _, v := range []float64{1 << 64, 1e20, -1e19, math.Inf(1), math.Inf(-1), math.NaN()} {
+		if n, err := intValue(v); err == nil {
+			t.Errorf("Expected an error for %v, got %d", v, n)
+		}
+	}
+}
+
+func Test_BatchInsertCommitSize(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithCommitSize(4))
+	if err != nil {
+		t.Error(err)
+	}
+	sigs := randomSigs(10, 4)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	// The duplicate id fails the last chunk
+	ids[9] = 0
+	err = lsh.BatchInsert(ids, sigs)
+	partialErr, ok := err.(*PartialInsertError)
+	if !ok || partialErr.Committed != 8 {
+		t.Fatalf("Expected PartialInsertError after 8 signatures, got %v", err)
+	}
+	if !errors.Is(err, ErrIDExists) {
+		t.Error("PartialInsertError should unwrap to ErrIDExists")
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM lshtable").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 8 {
+		t.Errorf("Expected 8 entries, got %d", count)
+	}
+	removeTempFile(t, f)
+}
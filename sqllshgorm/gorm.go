@@ -0,0 +1,21 @@
+// Package sqllshgorm creates sqllsh indexes on the connection pools of
+// GORM, from gorm.io/gorm.
+package sqllshgorm
+
+import (
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+	"gorm.io/gorm"
+)
+
+// New creates a new LSH index on the database/sql connection pool of
+// db, picking the dialect from its driver like sqllsh.NewAutoLsh.
+// The index does not go through GORM, so its hooks, callbacks and
+// logger are not used.
+// The caller is responsible for closing db.
+func New(k, l int, tableName string, db *gorm.DB, opts ...sqllsh.Option) (*sqllsh.SqlLsh, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return sqllsh.NewAutoLsh(k, l, tableName, sqlDB, opts...)
+}
@@ -0,0 +1,37 @@
+package sqllshgorm
+
+import (
+	"testing"
+
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func Test_Gorm(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+	sqlDB.SetMaxOpenConns(1)
+	lsh, err := New(2, 3, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := sqllsh.Signature{1, 2, 3, 4, 5, 6}
+	if err := lsh.Insert(7, sig); err != nil {
+		t.Fatal(err)
+	}
+	found, err := lsh.QueryIDs(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0] != 7 {
+		t.Errorf("Expected [7], got %v", found)
+	}
+}
@@ -0,0 +1,43 @@
+// Package sqllshotel traces the operations of sqllsh indexes with
+// OpenTelemetry.
+package sqllshotel
+
+import (
+	"context"
+
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewTracer returns a sqllsh.Tracer starting spans with t, named
+// "sqllsh." followed by the operation, such as "sqllsh.query".
+func NewTracer(t trace.Tracer) sqllsh.Tracer {
+	return &tracer{t}
+}
+
+type tracer struct {
+	t trace.Tracer
+}
+
+func (t *tracer) Start(ctx context.Context, op string) sqllsh.Span {
+	_, s := t.t.Start(ctx, "sqllsh."+op)
+	return &span{s}
+}
+
+type span struct {
+	s trace.Span
+}
+
+func (s *span) SetInt(key string, value int64) {
+	s.s.SetAttributes(attribute.Int64(key, value))
+}
+
+func (s *span) End(err error) {
+	if err != nil {
+		s.s.RecordError(err)
+		s.s.SetStatus(codes.Error, err.Error())
+	}
+	s.s.End()
+}
@@ -0,0 +1,53 @@
+package sqllshotel
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+	_ "github.com/mattn/go-sqlite3"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func Test_NewTracer(t *testing.T) {
+	f, err := ioutil.TempFile("", "_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	lsh, err := sqllsh.NewSqliteLsh(2, 3, "lshtable", db,
+		sqllsh.WithTracer(NewTracer(provider.Tracer("test"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := sqllsh.Signature{1, 2, 3, 4, 5, 6}
+	lsh.BatchInsert([]int{0, 1}, []sqllsh.Signature{sig, sig})
+	lsh.QueryIDs(sig)
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	if spans[1].Name() != "sqllsh.query" {
+		t.Errorf("Expected sqllsh.query, got %s", spans[1].Name())
+	}
+	found := false
+	for _, attr := range spans[1].Attributes() {
+		if attr == attribute.Int64(sqllsh.AttrRows, 2) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected 2 rows in %v", spans[1].Attributes())
+	}
+}
@@ -0,0 +1,143 @@
+// Package sqllshpgx uses the native interface of the pgx driver for
+// PostgreSQL-backed sqllsh indexes, to load rows with COPY and to send
+// many queries in one round trip.
+// The index itself is created with sqllsh.NewPostgresLsh on a
+// database/sql connection pool, which can use pgx through its stdlib
+// package, for example sql.Open("pgx", dsn).
+package sqllshpgx
+
+import (
+	"context"
+	"strings"
+
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+	"github.com/jackc/pgx/v5"
+)
+
+// Conn is a pgx connection, such as *pgx.Conn or *pgxpool.Pool.
+type Conn interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	CopyFrom(ctx context.Context, table pgx.Identifier, cols []string, src pgx.CopyFromSource) (int64, error)
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// CopyWriter returns a sqllsh.PurgeWriter loading the rows with COPY
+// through conn, which is much faster than inserting them one at a time.
+// The deleted entries are purged in the transaction of the COPY.
+// COPY cannot skip or replace existing rows, so only
+// sqllsh.ConflictError is supported.
+// To be used with sqllsh.WithPurgeWriter:
+//
+//	lsh, err := sqllsh.NewPostgresLsh(k, l, "lshtable", db,
+//		sqllsh.WithPurgeWriter(sqllshpgx.CopyWriter(pool)))
+func CopyWriter(conn Conn) sqllsh.PurgeWriter {
+	return func(table string, cols []string, rows [][]interface{}, c sqllsh.Conflict,
+		purge string, args [][]interface{}) error {
+		if c != sqllsh.ConflictError {
+			return sqllsh.ErrUnsupported
+		}
+		ctx := context.Background()
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+		if purge != "" {
+			for _, a := range args {
+				if _, err := tx.Exec(ctx, purge, int64Values(a)...); err != nil {
+					return err
+				}
+			}
+		}
+		_, err = tx.CopyFrom(ctx, identifier(table), cols, pgx.CopyFromRows(int64Rows(rows)))
+		if err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	}
+}
+
+// identifier splits the table name, which may be qualified by a schema
+// and quoted, into its parts, as pgx quotes each of them.
+func identifier(table string) pgx.Identifier {
+	var id pgx.Identifier
+	var part strings.Builder
+	quoted := false
+	for i := 0; i < len(table); i++ {
+		switch ch := table[i]; {
+		case ch == '"' && quoted && i+1 < len(table) && table[i+1] == '"':
+			part.WriteByte('"')
+			i++
+		case ch == '"':
+			quoted = !quoted
+		case ch == '.' && !quoted:
+			id = append(id, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(ch)
+		}
+	}
+	return append(id, part.String())
+}
+
+// QueryBatch runs the query of lsh for each of sigs, sending all the
+// queries to the database in one round trip.
+// The result at position i holds the IDs of the candidates of sigs[i].
+func QueryBatch(ctx context.Context, conn Conn, lsh *sqllsh.SqlLsh, sigs []sqllsh.Signature) ([][]int, error) {
+	query := lsh.Statements().Query
+	b := &pgx.Batch{}
+	for _, sig := range sigs {
+		args, err := lsh.QueryArgs(sig)
+		if err != nil {
+			return nil, err
+		}
+		b.Queue(query, int64Values(args)...)
+	}
+	results := conn.SendBatch(ctx, b)
+	defer results.Close()
+	found := make([][]int, len(sigs))
+	for i := range sigs {
+		rows, err := results.Query()
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			found[i] = append(found[i], id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return found, results.Close()
+}
+
+// int64Rows converts the values of rows to int64, as pgx does not
+// encode uint into BIGINT.
+func int64Rows(rows [][]interface{}) [][]interface{} {
+	converted := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		converted[i] = int64Values(row)
+	}
+	return converted
+}
+
+func int64Values(values []interface{}) []interface{} {
+	converted := make([]interface{}, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case int:
+			converted[i] = int64(v)
+		case uint:
+			converted[i] = int64(v)
+		default:
+			converted[i] = v
+		}
+	}
+	return converted
+}
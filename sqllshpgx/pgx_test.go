@@ -0,0 +1,82 @@
+package sqllshpgx
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"reflect"
+	"testing"
+
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+func Test_Int64Values(t *testing.T) {
+	values := int64Values([]interface{}{1, uint(2), int64(3)})
+	expected := []interface{}{int64(1), int64(2), int64(3)}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected %v, got %v", expected, values)
+	}
+}
+
+func Test_Identifier(t *testing.T) {
+	cases := map[string]pgx.Identifier{
+		"lshtable":        {"lshtable"},
+		"s.lshtable":      {"s", "lshtable"},
+		`"s"."lsh.table"`: {"s", "lsh.table"},
+		`"a""b".t`:        {`a"b`, "t"},
+	}
+	for table, expected := range cases {
+		if id := identifier(table); !reflect.DeepEqual(id, expected) {
+			t.Errorf("Expected %v for %s, got %v", expected, table, id)
+		}
+	}
+}
+
+// Test_Pgx runs against the PostgreSQL database given by the connection
+// string in SQLLSH_POSTGRES_DSN.
+func Test_Pgx(t *testing.T) {
+	dsn := os.Getenv("SQLLSH_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("SQLLSH_POSTGRES_DSN is not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("DROP TABLE IF EXISTS lshtable"); err != nil {
+		t.Fatal(err)
+	}
+	lsh, err := sqllsh.NewPostgresLsh(2, 3, "lshtable", db,
+		sqllsh.WithPurgeWriter(CopyWriter(pool)), sqllsh.WithSoftDelete())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := []sqllsh.Signature{{1, 2, 3, 4, 5, 6}, {1, 2, 0, 0, 0, 0}, {0, 0, 0, 0, 0, 0}}
+	if err := lsh.BatchInsert([]int{0, 1, 2}, sigs); err != nil {
+		t.Fatal(err)
+	}
+	// The deleted entry is purged before being copied again
+	if err := lsh.Delete(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.BatchInsert([]int{0}, sigs[:1]); err != nil {
+		t.Fatal(err)
+	}
+	found, err := QueryBatch(ctx, pool, lsh, sigs[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || len(found[0]) != 2 || len(found[1]) != 3 {
+		t.Errorf("Unexpected candidates %v", found)
+	}
+}
@@ -0,0 +1,103 @@
+package sqllshserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+)
+
+// Client uses an index served by the handler of NewHandler.
+// It implements sqllsh.LshIndex, so that code using an embedded index
+// can use a remote one instead.
+// The sqllsh errors are returned as the same values, such as
+// sqllsh.ErrIDExists, so they can be checked with errors.Is.
+type Client struct {
+	url  string
+	http *http.Client
+}
+
+// NewClient returns a Client of the index served at url, sending the
+// requests with c, or http.DefaultClient if c is nil.
+func NewClient(url string, c *http.Client) *Client {
+	if c == nil {
+		c = http.DefaultClient
+	}
+	return &Client{url: strings.TrimSuffix(url, "/"), http: c}
+}
+
+// Insert is like sqllsh.SqlLsh.Insert.
+func (c *Client) Insert(id int, sig sqllsh.Signature) error {
+	return c.call("/insert", InsertRequest{Id: id, Signature: sig}, nil)
+}
+
+// BatchInsert is like sqllsh.SqlLsh.BatchInsert.
+func (c *Client) BatchInsert(ids []int, sigs []sqllsh.Signature) error {
+	return c.call("/batch_insert", BatchInsertRequest{Ids: ids, Signatures: sigs}, nil)
+}
+
+// Query is like sqllsh.SqlLsh.Query.
+// The caller is responsible for closing the channel.
+func (c *Client) Query(sig sqllsh.Signature, out chan int) error {
+	ids, err := c.QueryIDs(sig)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		out <- id
+	}
+	return nil
+}
+
+// QueryIDs is like sqllsh.SqlLsh.QueryIDs.
+func (c *Client) QueryIDs(sig sqllsh.Signature) ([]int, error) {
+	var res QueryResponse
+	if err := c.call("/query", QueryRequest{Signature: sig}, &res); err != nil {
+		return nil, err
+	}
+	if res.Ids == nil {
+		res.Ids = make([]int, 0)
+	}
+	return res.Ids, nil
+}
+
+// Delete is like sqllsh.SqlLsh.Delete.
+func (c *Client) Delete(id int) error {
+	return c.call("/delete", DeleteRequest{Id: id}, nil)
+}
+
+// Index is like sqllsh.SqlLsh.Index.
+func (c *Client) Index() error {
+	return c.call("/index", struct{}{}, nil)
+}
+
+// call posts req to the path of an operation, and decodes the response
+// into res unless it is nil.
+func (c *Client) call(path string, req, res interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Post(c.url+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&e); err != nil {
+			return errors.New(resp.Status)
+		}
+		if err := CodeError(e.Code); err != nil {
+			return err
+		}
+		return errors.New(e.Error)
+	}
+	if res == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(res)
+}
@@ -0,0 +1,49 @@
+package sqllshserver
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+)
+
+var _ sqllsh.LshIndex = (*Client)(nil)
+
+func Test_Client(t *testing.T) {
+	srv := httptest.NewServer(NewHandler(newIndex(t)))
+	defer srv.Close()
+	var c Index = NewClient(srv.URL+"/", nil)
+	if err := c.Insert(1, sqllsh.Signature{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.BatchInsert([]int{2, 3}, []sqllsh.Signature{{1, 2, 0, 0}, {5, 6, 7, 8}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Insert(1, sqllsh.Signature{1, 2, 3, 4}); !errors.Is(err, sqllsh.ErrIDExists) {
+		t.Errorf("Expected ErrIDExists, got %v", err)
+	}
+	if err := c.Index(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Delete(2); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := c.QueryIDs(sqllsh.Signature{1, 2, 7, 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 {
+		t.Errorf("Expected 2 IDs, got %v", ids)
+	}
+	out := make(chan int, 2)
+	if err := NewClient(srv.URL, nil).Query(sqllsh.Signature{9, 9, 9, 9}, out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 0 {
+		t.Errorf("Expected no IDs, got %d", len(out))
+	}
+	if _, err := c.QueryIDs(sqllsh.Signature{1}); err != sqllsh.ErrSignatureSizeMismatch {
+		t.Errorf("Expected ErrSignatureSizeMismatch, got %v", err)
+	}
+}
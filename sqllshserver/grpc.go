@@ -0,0 +1,230 @@
+package sqllshserver
+
+import (
+	"context"
+	"encoding/json"
+
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// ServiceName is the name of the gRPC service registered by
+// RegisterService.
+//
+// Its methods Insert, BatchInsert, Query, Delete and Index take the
+// request bodies of the HTTP handler, and answer with its responses,
+// encoded in JSON rather than protocol buffers so that no generated
+// code is needed: clients send them with the content subtype "json",
+// that is the content type "application/grpc+json", and the server
+// must be created with the option of ServerCodec.
+// Failures are answered with the gRPC code of the sqllsh error, such as
+// AlreadyExists for sqllsh.ErrIDExists, and its code of Code as
+// message; malformed requests are answered with InvalidArgument and
+// "bad_request", and other errors with the code Internal and a generic
+// message.
+const ServiceName = "sqllsh.Index"
+
+// codecName is the content subtype of the messages of the service.
+const codecName = "json"
+
+// ServerCodec returns the option of grpc.NewServer decoding the
+// messages of the service in JSON.
+// The codec is not registered globally, so that it does not replace
+// another "json" codec of the process; as a consequence, it applies to
+// every service of the server.
+func ServerCodec() grpc.ServerOption {
+	return grpc.ForceServerCodec(jsonCodec{})
+}
+
+// jsonCodec encodes the gRPC messages in JSON.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return codecName
+}
+
+// grpcCodes are the gRPC codes of the sqllsh errors.
+var grpcCodes = map[error]codes.Code{
+	sqllsh.ErrSignatureSizeMismatch: codes.InvalidArgument,
+	sqllsh.ErrCountMismatch:         codes.InvalidArgument,
+	sqllsh.ErrEmptyBatch:            codes.InvalidArgument,
+	sqllsh.ErrInvalidParameter:      codes.InvalidArgument,
+	sqllsh.ErrIDExists:              codes.AlreadyExists,
+	sqllsh.ErrNotFound:              codes.NotFound,
+	sqllsh.ErrUnsupported:           codes.Unimplemented,
+}
+
+// RegisterService registers the gRPC service of ServiceName on s, with
+// idx as its index.
+// s must be created with the option of ServerCodec.
+func RegisterService(s grpc.ServiceRegistrar, idx Index) {
+	s.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*Index)(nil),
+		Methods: []grpc.MethodDesc{
+			unary("Insert", func(idx Index, dec func(interface{}) error) (interface{}, error) {
+				var req InsertRequest
+				if err := dec(&req); err != nil {
+					return nil, err
+				}
+				return struct{}{}, idx.Insert(req.Id, req.Signature)
+			}),
+			unary("BatchInsert", func(idx Index, dec func(interface{}) error) (interface{}, error) {
+				var req BatchInsertRequest
+				if err := dec(&req); err != nil {
+					return nil, err
+				}
+				return struct{}{}, idx.BatchInsert(req.Ids, req.Signatures)
+			}),
+			unary("Query", func(idx Index, dec func(interface{}) error) (interface{}, error) {
+				var req QueryRequest
+				if err := dec(&req); err != nil {
+					return nil, err
+				}
+				ids, err := idx.QueryIDs(req.Signature)
+				if ids == nil {
+					ids = make([]int, 0)
+				}
+				return QueryResponse{Ids: ids}, err
+			}),
+			unary("Delete", func(idx Index, dec func(interface{}) error) (interface{}, error) {
+				var req DeleteRequest
+				if err := dec(&req); err != nil {
+					return nil, err
+				}
+				return struct{}{}, idx.Delete(req.Id)
+			}),
+			unary("Index", func(idx Index, dec func(interface{}) error) (interface{}, error) {
+				var req struct{}
+				if err := dec(&req); err != nil {
+					return nil, err
+				}
+				return struct{}{}, idx.Index()
+			}),
+		},
+		Streams: []grpc.StreamDesc{},
+	}, idx)
+}
+
+// unary returns the description of the method name of the service,
+// which decodes its request and runs it with op.
+func unary(name string, op func(idx Index, dec func(interface{}) error) (interface{}, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			handler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+				res, err := op(srv.(Index), func(v interface{}) error {
+					if err := dec(v); err != nil {
+						return errBadRequest
+					}
+					return nil
+				})
+				if err != nil {
+					return nil, grpcError(err)
+				}
+				return res, nil
+			}
+			if interceptor == nil {
+				return handler(ctx, nil)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + ServiceName + "/" + name}
+			return interceptor(ctx, nil, info, handler)
+		},
+	}
+}
+
+// grpcError returns the gRPC status error of err.
+// Only the sqllsh errors are passed on, the others, including those
+// carrying a gRPC status such as the errors of the Spanner driver,
+// have their details hidden from the clients.
+func grpcError(err error) error {
+	if err == errBadRequest {
+		return grpcstatus.Error(codes.InvalidArgument, code(err))
+	}
+	c := Code(err)
+	if c == "internal" {
+		return grpcstatus.Error(codes.Internal, "Internal error")
+	}
+	return grpcstatus.Error(grpcCodes[CodeError(c)], c)
+}
+
+// GRPCClient uses an index served by the gRPC service of
+// RegisterService.
+// Like Client, it implements sqllsh.LshIndex and returns the sqllsh
+// errors as the same values.
+type GRPCClient struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewGRPCClient returns a GRPCClient of the service reached by conn.
+func NewGRPCClient(conn grpc.ClientConnInterface) *GRPCClient {
+	return &GRPCClient{conn: conn}
+}
+
+// Insert is like sqllsh.SqlLsh.Insert.
+func (c *GRPCClient) Insert(id int, sig sqllsh.Signature) error {
+	return c.call("Insert", InsertRequest{Id: id, Signature: sig}, &struct{}{})
+}
+
+// BatchInsert is like sqllsh.SqlLsh.BatchInsert.
+func (c *GRPCClient) BatchInsert(ids []int, sigs []sqllsh.Signature) error {
+	return c.call("BatchInsert", BatchInsertRequest{Ids: ids, Signatures: sigs}, &struct{}{})
+}
+
+// Query is like sqllsh.SqlLsh.Query.
+// The caller is responsible for closing the channel.
+func (c *GRPCClient) Query(sig sqllsh.Signature, out chan int) error {
+	ids, err := c.QueryIDs(sig)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		out <- id
+	}
+	return nil
+}
+
+// QueryIDs is like sqllsh.SqlLsh.QueryIDs.
+func (c *GRPCClient) QueryIDs(sig sqllsh.Signature) ([]int, error) {
+	var res QueryResponse
+	if err := c.call("Query", QueryRequest{Signature: sig}, &res); err != nil {
+		return nil, err
+	}
+	if res.Ids == nil {
+		res.Ids = make([]int, 0)
+	}
+	return res.Ids, nil
+}
+
+// Delete is like sqllsh.SqlLsh.Delete.
+func (c *GRPCClient) Delete(id int) error {
+	return c.call("Delete", DeleteRequest{Id: id}, &struct{}{})
+}
+
+// Index is like sqllsh.SqlLsh.Index.
+func (c *GRPCClient) Index() error {
+	return c.call("Index", struct{}{}, &struct{}{})
+}
+
+// call invokes the method of the service with req, and decodes the
+// response into res.
+func (c *GRPCClient) call(method string, req, res interface{}) error {
+	err := c.conn.Invoke(context.Background(), "/"+ServiceName+"/"+method, req, res,
+		grpc.ForceCodec(jsonCodec{}))
+	if st, ok := grpcstatus.FromError(err); ok && st.Code() != codes.OK {
+		if err := CodeError(st.Message()); err != nil {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package sqllshserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	grpcstatus "google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var _ sqllsh.LshIndex = (*GRPCClient)(nil)
+
+func Test_GRPC(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(ServerCodec())
+	RegisterService(s, newIndex(t))
+	go s.Serve(lis)
+	defer s.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c := NewGRPCClient(conn)
+	if err := c.Insert(1, sqllsh.Signature{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.BatchInsert([]int{2, 3}, []sqllsh.Signature{{1, 2, 0, 0}, {5, 6, 7, 8}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Insert(1, sqllsh.Signature{1, 2, 3, 4}); !errors.Is(err, sqllsh.ErrIDExists) {
+		t.Errorf("Expected ErrIDExists, got %v", err)
+	}
+	if err := c.Index(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Delete(2); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := c.QueryIDs(sqllsh.Signature{1, 2, 7, 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 {
+		t.Errorf("Expected 2 IDs, got %v", ids)
+	}
+	if ids, err := c.QueryIDs(sqllsh.Signature{9, 9, 9, 9}); err != nil || ids == nil || len(ids) != 0 {
+		t.Errorf("Expected no IDs, got %v, %v", ids, err)
+	}
+	if _, err := c.QueryIDs(sqllsh.Signature{1}); err != sqllsh.ErrSignatureSizeMismatch {
+		t.Errorf("Expected ErrSignatureSizeMismatch, got %v", err)
+	}
+}
+
+// statusIndex fails with an error carrying a gRPC status, like those of
+// the Spanner driver.
+type statusIndex struct {
+	*sqllsh.SqlLsh
+}
+
+func (statusIndex) Index() error {
+	return grpcstatus.Error(codes.PermissionDenied, "spanner: permission denied on database lsh")
+}
+
+func Test_GRPCErrors(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(ServerCodec())
+	RegisterService(s, statusIndex{newIndex(t)})
+	go s.Serve(lis)
+	defer s.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// The status of the driver is not passed on
+	st, _ := grpcstatus.FromError(NewGRPCClient(conn).Index())
+	if st.Code() != codes.Internal || st.Message() != "Internal error" {
+		t.Errorf("Expected Internal with a generic message, got %v", st)
+	}
+	err = conn.Invoke(context.Background(), "/"+ServiceName+"/Insert", "1", &struct{}{},
+		grpc.ForceCodec(jsonCodec{}))
+	if st, _ := grpcstatus.FromError(err); st.Code() != codes.InvalidArgument || st.Message() != "bad_request" {
+		t.Errorf("Expected InvalidArgument for a malformed request, got %v", st)
+	}
+}
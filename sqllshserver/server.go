@@ -0,0 +1,225 @@
+// Package sqllshserver serves a sqllsh index over HTTP with JSON
+// requests, and over gRPC with RegisterService, so that services not
+// written in Go can use the same index.
+//
+// Each operation is a POST request to its path, with a JSON body:
+//
+//	/insert        {"id": 1, "signature": [1, 2, 3, 4]}
+//	/batch_insert  {"ids": [1, 2], "signatures": [[1, 2, 3, 4], [5, 6, 7, 8]]}
+//	/query         {"signature": [1, 2, 3, 4]}, answered with {"ids": [1]}
+//	/delete        {"id": 1}
+//	/index         {}
+//
+// Successful writes are answered with an empty JSON object. Failures
+// are answered with {"error": "...", "code": "..."}, where code names
+// the sqllsh error, see Code. The other errors, such as those of the
+// database, are answered with a generic message and the code
+// "internal", so that their details are not sent to the clients.
+// Request bodies are limited to MaxBodySize bytes.
+// JSON numbers are exact up to 2^53 in most languages other than Go, so
+// clients in those languages should use smaller hash values.
+package sqllshserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+)
+
+// MaxBodySize is the maximum size in bytes of a request body; larger
+// requests are answered with the code "too_large".
+const MaxBodySize = 32 << 20
+
+// Index is the part of a sqllsh index served by the handler.
+type Index interface {
+	Insert(id int, sig sqllsh.Signature) error
+	BatchInsert(ids []int, sigs []sqllsh.Signature) error
+	QueryIDs(sig sqllsh.Signature) ([]int, error)
+	Delete(id int) error
+	Index() error
+}
+
+// Request bodies and responses of the operations.
+type (
+	InsertRequest struct {
+		Id        int              `json:"id"`
+		Signature sqllsh.Signature `json:"signature"`
+	}
+	BatchInsertRequest struct {
+		Ids        []int              `json:"ids"`
+		Signatures []sqllsh.Signature `json:"signatures"`
+	}
+	QueryRequest struct {
+		Signature sqllsh.Signature `json:"signature"`
+	}
+	QueryResponse struct {
+		Ids []int `json:"ids"`
+	}
+	DeleteRequest struct {
+		Id int `json:"id"`
+	}
+	ErrorResponse struct {
+		Error string `json:"error"`
+		Code  string `json:"code"`
+	}
+)
+
+// errorCodes are the codes of the sqllsh errors in ErrorResponse, with
+// their HTTP status.
+var errorCodes = []struct {
+	err    error
+	code   string
+	status int
+}{
+	{sqllsh.ErrSignatureSizeMismatch, "signature_size_mismatch", http.StatusBadRequest},
+	{sqllsh.ErrCountMismatch, "count_mismatch", http.StatusBadRequest},
+	{sqllsh.ErrEmptyBatch, "empty_batch", http.StatusBadRequest},
+	{sqllsh.ErrInvalidParameter, "invalid_parameter", http.StatusBadRequest},
+	{sqllsh.ErrIDExists, "id_exists", http.StatusConflict},
+	{sqllsh.ErrNotFound, "not_found", http.StatusNotFound},
+	{sqllsh.ErrUnsupported, "unsupported", http.StatusNotImplemented},
+}
+
+// Code returns the code of err in ErrorResponse, "internal" if it is
+// not one of the sqllsh errors that clients can act on.
+func Code(err error) string {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return "internal"
+}
+
+// CodeError returns the sqllsh error of code, or nil if code is not the
+// code of one.
+func CodeError(code string) error {
+	for _, c := range errorCodes {
+		if c.code == code {
+			return c.err
+		}
+	}
+	return nil
+}
+
+// NewHandler returns the handler serving the operations on idx.
+func NewHandler(idx Index) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/insert", post(func(r *http.Request) (interface{}, error) {
+		var req InsertRequest
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		return struct{}{}, idx.Insert(req.Id, req.Signature)
+	}))
+	mux.HandleFunc("/batch_insert", post(func(r *http.Request) (interface{}, error) {
+		var req BatchInsertRequest
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		return struct{}{}, idx.BatchInsert(req.Ids, req.Signatures)
+	}))
+	mux.HandleFunc("/query", post(func(r *http.Request) (interface{}, error) {
+		var req QueryRequest
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		ids, err := idx.QueryIDs(req.Signature)
+		if ids == nil {
+			ids = make([]int, 0)
+		}
+		return QueryResponse{Ids: ids}, err
+	}))
+	mux.HandleFunc("/delete", post(func(r *http.Request) (interface{}, error) {
+		var req DeleteRequest
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		return struct{}{}, idx.Delete(req.Id)
+	}))
+	mux.HandleFunc("/index", post(func(r *http.Request) (interface{}, error) {
+		return struct{}{}, idx.Index()
+	}))
+	return mux
+}
+
+var (
+	// errBadRequest is returned when a request body cannot be decoded.
+	errBadRequest = errors.New("Malformed request")
+	// errTooLarge is returned when a request body is larger than
+	// MaxBodySize.
+	errTooLarge = errors.New("Request too large")
+)
+
+func decode(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return errTooLarge
+		}
+		return errBadRequest
+	}
+	return nil
+}
+
+// post returns the handler of an operation, which only accepts POST
+// requests and writes the result of op as JSON.
+func post(op func(r *http.Request) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed,
+				ErrorResponse{Error: "POST required", Code: "bad_request"})
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, MaxBodySize)
+		res, err := op(r)
+		if err != nil {
+			writeJSON(w, status(err), ErrorResponse{Error: message(err), Code: code(err)})
+			return
+		}
+		writeJSON(w, http.StatusOK, res)
+	}
+}
+
+func code(err error) string {
+	switch err {
+	case errBadRequest:
+		return "bad_request"
+	case errTooLarge:
+		return "too_large"
+	}
+	return Code(err)
+}
+
+// message returns the error message sent to the client, which is
+// generic for internal errors.
+func message(err error) string {
+	if code(err) == "internal" {
+		return "Internal error"
+	}
+	return err.Error()
+}
+
+func status(err error) int {
+	switch err {
+	case errBadRequest:
+		return http.StatusBadRequest
+	case errTooLarge:
+		return http.StatusRequestEntityTooLarge
+	}
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.status
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,113 @@
+package sqllshserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newIndex(t *testing.T) *sqllsh.SqlLsh {
+	lsh, err := sqllsh.NewMemoryLsh(2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lsh.Close() })
+	return lsh
+}
+
+func call(t *testing.T, srv *httptest.Server, path string, req, res interface{}) int {
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(srv.URL+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode
+}
+
+func Test_Handler(t *testing.T) {
+	srv := httptest.NewServer(NewHandler(newIndex(t)))
+	defer srv.Close()
+	var empty struct{}
+	if s := call(t, srv, "/insert", InsertRequest{Id: 1, Signature: sqllsh.Signature{1, 2, 3, 4}}, &empty); s != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", s)
+	}
+	batch := BatchInsertRequest{
+		Ids:        []int{2, 3},
+		Signatures: []sqllsh.Signature{{1, 2, 0, 0}, {5, 6, 7, 8}},
+	}
+	if s := call(t, srv, "/batch_insert", batch, &empty); s != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", s)
+	}
+	var errRes ErrorResponse
+	if s := call(t, srv, "/insert", InsertRequest{Id: 1, Signature: sqllsh.Signature{1, 2, 3, 4}}, &errRes); s != http.StatusConflict || CodeError(errRes.Code) != sqllsh.ErrIDExists {
+		t.Errorf("Expected status 409 with id_exists, got %d with %v", s, errRes)
+	}
+	if s := call(t, srv, "/delete", DeleteRequest{Id: 3}, &empty); s != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", s)
+	}
+	var res QueryResponse
+	if s := call(t, srv, "/query", QueryRequest{Signature: sqllsh.Signature{1, 2, 7, 8}}, &res); s != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", s)
+	}
+	if len(res.Ids) != 2 {
+		t.Errorf("Expected 2 IDs, got %v", res.Ids)
+	}
+	if s := call(t, srv, "/query", QueryRequest{Signature: sqllsh.Signature{1}}, &errRes); s != http.StatusBadRequest || errRes.Code != "signature_size_mismatch" {
+		t.Errorf("Expected status 400 with signature_size_mismatch, got %d with %v", s, errRes)
+	}
+	resp, err := http.Get(srv.URL + "/query")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", resp.StatusCode)
+	}
+}
+
+func Test_HandlerLimits(t *testing.T) {
+	srv := httptest.NewServer(NewHandler(newIndex(t)))
+	defer srv.Close()
+	var res map[string]interface{}
+	if s := call(t, srv, "/query", QueryRequest{Signature: sqllsh.Signature{9, 9, 9, 9}}, &res); s != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", s)
+	}
+	if ids, ok := res["ids"].([]interface{}); !ok || len(ids) != 0 {
+		t.Errorf("Expected an empty list of IDs, got %v", res["ids"])
+	}
+	sig := make(sqllsh.Signature, MaxBodySize/2)
+	var errRes ErrorResponse
+	if s := call(t, srv, "/query", QueryRequest{Signature: sig}, &errRes); s != http.StatusRequestEntityTooLarge || errRes.Code != "too_large" {
+		t.Errorf("Expected status 413 with too_large, got %d with %v", s, errRes)
+	}
+}
+
+type failingIndex struct {
+	*sqllsh.SqlLsh
+}
+
+func (failingIndex) Index() error {
+	return errors.New("pq: password authentication failed for user \"lsh\"")
+}
+
+func Test_HandlerInternalError(t *testing.T) {
+	srv := httptest.NewServer(NewHandler(failingIndex{newIndex(t)}))
+	defer srv.Close()
+	var errRes ErrorResponse
+	if s := call(t, srv, "/index", struct{}{}, &errRes); s != http.StatusInternalServerError || errRes.Code != "internal" || errRes.Error != "Internal error" {
+		t.Errorf("Expected status 500 with a generic message, got %d with %v", s, errRes)
+	}
+}
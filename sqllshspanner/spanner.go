@@ -0,0 +1,79 @@
+// Package sqllshspanner writes the rows of sqllsh indexes on Google
+// Cloud Spanner as mutations, which is faster than inserting them with
+// DML statements.
+package sqllshspanner
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"cloud.google.com/go/spanner"
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+	spannerdriver "github.com/googleapis/go-sql-spanner"
+)
+
+// ErrNotSpanner is returned when the database connection does not use
+// the go-sql-spanner driver.
+var ErrNotSpanner = errors.New("Connection is not a Spanner connection")
+
+// MutationWriter returns a sqllsh.BatchWriter applying the rows as
+// mutations through db, which must use the go-sql-spanner driver.
+// Each batch is applied in one transaction.
+// Mutations cannot skip the rows that already exist, so the batches of
+// an index created with sqllsh.ConflictIgnore fail with
+// sqllsh.ErrUnsupported.
+// To be used with sqllsh.WithBatchWriter:
+//
+//	lsh, err := sqllsh.NewSpannerLsh(k, l, "lshtable", db,
+//		sqllsh.WithBatchWriter(sqllshspanner.MutationWriter(db)))
+func MutationWriter(db *sql.DB) sqllsh.BatchWriter {
+	return func(table string, cols []string, rows [][]interface{}, c sqllsh.Conflict) error {
+		ms, err := mutations(table, cols, rows, c)
+		if err != nil {
+			return err
+		}
+		ctx := context.Background()
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return conn.Raw(func(driverConn interface{}) error {
+			sc, ok := driverConn.(spannerdriver.SpannerConn)
+			if !ok {
+				return ErrNotSpanner
+			}
+			_, err := sc.Apply(ctx, ms)
+			return err
+		})
+	}
+}
+
+// mutations returns one mutation per row, with the values converted to
+// INT64.
+func mutations(table string, cols []string, rows [][]interface{}, c sqllsh.Conflict) ([]*spanner.Mutation, error) {
+	if c != sqllsh.ConflictError && c != sqllsh.ConflictReplace {
+		return nil, sqllsh.ErrUnsupported
+	}
+	ms := make([]*spanner.Mutation, len(rows))
+	for i, row := range rows {
+		vals := make([]interface{}, len(row))
+		for j, v := range row {
+			switch v := v.(type) {
+			case int:
+				vals[j] = int64(v)
+			case uint:
+				vals[j] = int64(v)
+			default:
+				vals[j] = v
+			}
+		}
+		if c == sqllsh.ConflictReplace {
+			ms[i] = spanner.InsertOrUpdate(table, cols, vals)
+		} else {
+			ms[i] = spanner.Insert(table, cols, vals)
+		}
+	}
+	return ms, nil
+}
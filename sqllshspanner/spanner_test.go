@@ -0,0 +1,33 @@
+package sqllshspanner
+
+import (
+	"reflect"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+)
+
+func Test_Mutations(t *testing.T) {
+	cols := []string{"id", "hv_0", "hv_1"}
+	rows := [][]interface{}{{1, uint(2), uint(3)}}
+	ms, err := mutations("lshtable", cols, rows, sqllsh.ConflictError)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := spanner.Insert("lshtable", cols, []interface{}{int64(1), int64(2), int64(3)})
+	if len(ms) != 1 || !reflect.DeepEqual(ms[0], expected) {
+		t.Errorf("Expected %v, got %v", expected, ms)
+	}
+	ms, err = mutations("lshtable", cols, rows, sqllsh.ConflictReplace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = spanner.InsertOrUpdate("lshtable", cols, []interface{}{int64(1), int64(2), int64(3)})
+	if !reflect.DeepEqual(ms[0], expected) {
+		t.Errorf("Expected %v, got %v", expected, ms[0])
+	}
+	if _, err := mutations("lshtable", cols, rows, sqllsh.ConflictIgnore); err != sqllsh.ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
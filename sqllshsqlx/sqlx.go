@@ -0,0 +1,30 @@
+// Package sqllshsqlx creates sqllsh indexes on the connection pools of
+// github.com/jmoiron/sqlx, and reads their entries with sqlx.
+package sqllshsqlx
+
+import (
+	"context"
+
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+	"github.com/jmoiron/sqlx"
+)
+
+// New creates a new LSH index on the connection pool of db, picking the
+// dialect from its driver like sqllsh.NewAutoLsh.
+// The caller is responsible for closing db.
+func New(k, l int, tableName string, db *sqlx.DB, opts ...sqllsh.Option) (*sqllsh.SqlLsh, error) {
+	return sqllsh.NewAutoLsh(k, l, tableName, db.DB, opts...)
+}
+
+// Select reads all the entries of lsh through db, which can be a
+// *sqlx.DB or a *sqlx.Tx, mapping the columns to the fields of
+// sqllsh.Entry with their struct tags.
+// The index must use sqllsh.WithCompactLayout, which stores each
+// Signature in a single column.
+func Select(ctx context.Context, db sqlx.QueryerContext, lsh *sqllsh.SqlLsh) ([]sqllsh.Entry, error) {
+	var entries []sqllsh.Entry
+	if err := sqlx.SelectContext(ctx, db, &entries, lsh.Statements().Scan); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,40 @@
+package sqllshsqlx
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func Test_Sqlx(t *testing.T) {
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	lsh, err := New(2, 3, "lshtable", db, sqllsh.WithCompactLayout())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := []sqllsh.Signature{{1, 2, 3, 4, 5, 6}, {1, 2, 0, 0, 0, 0}}
+	if err := lsh.BatchInsert([]int{0, 1}, sigs); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := Select(context.Background(), db, lsh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(sigs) {
+		t.Fatalf("Expected %d entries, got %d", len(sigs), len(entries))
+	}
+	for _, e := range entries {
+		if !reflect.DeepEqual(e.Signature, sigs[e.Id]) {
+			t.Errorf("Expected %v for %d, got %v", sigs[e.Id], e.Id, e.Signature)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package sqllsh
+
+// Statements are the SQL statements an index runs on its table.
+type Statements struct {
+	CreateTable   string   // Run by the constructor
+	CreateIndexes []string // Run by Index, one per hash table
+	Insert        string   // Run by Insert and BatchInsert
+	Query         string   // Run by Query and QueryIDs
+	Count         string   // Run by CountCandidates
+	Scan          string   // Run by Scan
+	Delete        string   // Run by Delete
+	Purge         string   // Run before inserting with WithSoftDelete, empty otherwise
+}
+
+// Statements returns the SQL statements the index runs, so that they
+// can be reviewed.
+func (lsh *SqlLsh) Statements() Statements {
+	s := Statements{
+		CreateTable:   lsh.createTableStr(),
+		CreateIndexes: make([]string, lsh.l),
+		Insert:        lsh.insertStr(),
+		Query:         lsh.queryStr(),
+		Count:         lsh.countStr(lsh.allBands(), lsh.k),
+		Scan:          lsh.scanStr(),
+		Delete:        lsh.deleteStr(),
+	}
+	for i := range s.CreateIndexes {
+		s.CreateIndexes[i] = lsh.indexStr(i)
+	}
+	if lsh.softDelete {
+		s.Purge = lsh.purgeStr()
+	}
+	return s
+}
+
+// SqliteStatements returns the SQL statements a Sqlite3-backed index
+// created with the same arguments would run, without connecting to a
+// database.
+func SqliteStatements(k, l int, tableName string, opts ...Option) Statements {
+	return newStatements(k, l, tableName, sqliteDialect, opts)
+}
+
+// PostgresStatements returns the SQL statements a PostgreSQL-backed
+// index created with the same arguments would run, without connecting
+// to a database.
+func PostgresStatements(k, l int, tableName string, opts ...Option) Statements {
+	return newStatements(k, l, tableName, postgresDialect, opts)
+}
+
+func newStatements(k, l int, tableName string, d dialect, opts []Option) Statements {
+	lsh := &SqlLsh{
+		k:         k,
+		l:         l,
+		tableName: tableName,
+		dialect:   d,
+	}
+	for _, opt := range opts {
+		opt(lsh)
+	}
+	if adjust, ok := d.variants[lsh.variant]; ok {
+		lsh.dialect = adjust(d)
+	}
+	return lsh.Statements()
+}
@@ -0,0 +1,62 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func Test_Statements(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db, WithSoftDelete())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := lsh.Statements()
+	if !reflect.DeepEqual(s, SqliteStatements(2, 3, "lshtable", WithSoftDelete())) {
+		t.Error("Statements differ from SqliteStatements")
+	}
+	if len(s.CreateIndexes) != 3 || s.Purge == "" {
+		t.Errorf("Unexpected statements %+v", s)
+	}
+	expected := "CREATE INDEX lshtable_ht_2 ON lshtable (hv_4,hv_5)"
+	if s.CreateIndexes[2] != expected {
+		t.Errorf("Expected %q, got %q", expected, s.CreateIndexes[2])
+	}
+	pg := PostgresStatements(2, 3, "lshtable")
+	expected = "DELETE FROM lshtable WHERE id = $1"
+	if pg.Delete != expected {
+		t.Errorf("Expected %q, got %q", expected, pg.Delete)
+	}
+	removeTempFile(t, f)
+}
+
+func Test_QueryArgs(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lsh.Insert(1, Signature{1, 2, 3, 4})
+	lsh.Insert(2, Signature{1, 2, 0, 0})
+	args, err := lsh.QueryArgs(Signature{0, 0, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var id int
+	if err := db.QueryRow(lsh.Statements().Query, args...).Scan(&id); err != nil || id != 1 {
+		t.Errorf("Expected 1, got %d, %v", id, err)
+	}
+	if _, err := lsh.QueryArgs(Signature{1}); err != ErrSignatureSizeMismatch {
+		t.Errorf("Expected ErrSignatureSizeMismatch, got %v", err)
+	}
+	removeTempFile(t, f)
+}
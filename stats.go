@@ -0,0 +1,119 @@
+package sqllsh
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencySamples is the number of most recent operations of each kind
+// kept to compute the percentiles of Stats.
+const latencySamples = 1024
+
+// Stats are the latencies of the recent operations of an index, see
+// WithLatencyStats.
+type Stats struct {
+	Insert      Latency // Insert calls
+	BatchInsert Latency // BatchInsert calls
+	Query       Latency // Query and QueryIDs calls
+}
+
+// Latency summarizes the durations of the most recent successful
+// operations of one kind.
+type Latency struct {
+	Count int64 // Number of operations recorded since the index was created
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+}
+
+// WithLatencyStats records the duration of every successful insert and
+// query, so that their percentiles can be read with Stats.
+// Only the last 1024 durations of each kind are kept, so the cost is a
+// few nanoseconds per operation and a few kilobytes of memory.
+func WithLatencyStats() Option {
+	return func(lsh *SqlLsh) {
+		lsh.latency = &latencyRecorder{rings: map[string]*latencyRing{
+			"insert":       {},
+			"batch insert": {},
+			"query":        {},
+		}}
+	}
+}
+
+// Stats returns the latency percentiles of the recent inserts and
+// queries, or zero Stats if the index was not created with
+// WithLatencyStats.
+func (lsh *SqlLsh) Stats() Stats {
+	if lsh.latency == nil {
+		return Stats{}
+	}
+	return lsh.latency.stats()
+}
+
+// latencyRecorder keeps the recent durations of inserts and queries in
+// one ring buffer per operation.
+type latencyRecorder struct {
+	mu    sync.Mutex
+	rings map[string]*latencyRing // By the Op of the Event, fixed on creation
+}
+
+type latencyRing struct {
+	samples [latencySamples]time.Duration
+	count   int64
+}
+
+func (r *latencyRing) add(d time.Duration) {
+	r.samples[r.count%latencySamples] = d
+	r.count++
+}
+
+func (r *latencyRing) latency() Latency {
+	n := r.count
+	if n > latencySamples {
+		n = latencySamples
+	}
+	sorted := make([]time.Duration, n)
+	copy(sorted, r.samples[:n])
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return Latency{
+		Count: r.count,
+		P50:   Percentile(sorted, 50),
+		P95:   Percentile(sorted, 95),
+		P99:   Percentile(sorted, 99),
+	}
+}
+
+// Percentile returns the p-th percentile of the durations sorted in
+// increasing order, as reported in Latency, or zero if there are none.
+func Percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := (len(sorted)*p+99)/100 - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+// record adds the duration of op if it is an insert or a query.
+func (r *latencyRecorder) record(op string, d time.Duration) {
+	ring, ok := r.rings[op]
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ring.add(d)
+}
+
+func (r *latencyRecorder) stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Stats{
+		Insert:      r.rings["insert"].latency(),
+		BatchInsert: r.rings["batch insert"].latency(),
+		Query:       r.rings["query"].latency(),
+	}
+}
@@ -0,0 +1,76 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func Test_Stats(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db, WithLatencyStats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := lsh.Stats(); s.Insert.Count != 0 || s.BatchInsert.Count != 0 || s.Query.Count != 0 {
+		t.Errorf("Expected no operations, got %+v", s)
+	}
+	sigs := randomSigs(10, 6)
+	if err := lsh.Insert(0, sigs[0]); err != nil {
+		t.Fatal(err)
+	}
+	ids := []int{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	if err := lsh.BatchInsert(ids, sigs[1:]); err != nil {
+		t.Fatal(err)
+	}
+	for _, sig := range sigs {
+		if _, err := lsh.QueryIDs(sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := lsh.Stats()
+	if s.Insert.Count != 1 || s.BatchInsert.Count != 1 || s.Query.Count != 10 {
+		t.Errorf("Expected 1 insert, 1 batch insert and 10 queries, got %+v", s)
+	}
+	if s.Query.P50 <= 0 || s.Query.P50 > s.Query.P95 || s.Query.P95 > s.Query.P99 {
+		t.Errorf("Unexpected query percentiles %+v", s.Query)
+	}
+}
+
+func Test_LatencyRing(t *testing.T) {
+	var r latencyRing
+	for i := 1; i <= 2*latencySamples; i++ {
+		r.add(time.Duration(i))
+	}
+	l := r.latency()
+	// Only the last latencySamples durations are kept
+	if l.Count != 2*latencySamples || l.P50 != latencySamples+latencySamples/2 {
+		t.Errorf("Unexpected latency %+v", l)
+	}
+	if l.P99 != time.Duration(latencySamples+latencySamples*99/100+1) {
+		t.Errorf("Unexpected p99 %v", l.P99)
+	}
+}
+
+func Test_Percentile(t *testing.T) {
+	sorted := make([]time.Duration, 200)
+	for i := range sorted {
+		sorted[i] = time.Duration(i + 1)
+	}
+	for _, c := range []struct {
+		p        int
+		expected time.Duration
+	}{{50, 100}, {95, 190}, {99, 198}, {100, 200}} {
+		if d := Percentile(sorted, c.p); d != c.expected {
+			t.Errorf("Expected %v for p%d, got %v", c.expected, c.p, d)
+		}
+	}
+	if d := Percentile(nil, 50); d != 0 {
+		t.Errorf("Expected 0 without durations, got %v", d)
+	}
+}
@@ -0,0 +1,40 @@
+package sqllsh
+
+// TableKind is the durability of the table of the index.
+type TableKind int
+
+const (
+	// TablePermanent is a regular table. It is supported by all
+	// databases.
+	TablePermanent TableKind = iota
+	// TableUnlogged is a table whose writes skip the write-ahead log,
+	// which makes loading about twice as fast, but the table is emptied
+	// after a crash.
+	TableUnlogged
+	// TableTemporary is a table dropped at the end of the session.
+	// As it is only visible to the connection that created it, the
+	// database connection object must be limited to a single connection
+	// with SetMaxOpenConns(1).
+	TableTemporary
+)
+
+// WithTableKind creates the table of the index, and its metadata table,
+// with the given durability, for ephemeral indexes that do not need to
+// survive a crash.
+// The constructor returns ErrUnsupported if the database does not
+// support the table kind, or if it is used with WithPartitions.
+func WithTableKind(t TableKind) Option {
+	return func(lsh *SqlLsh) {
+		lsh.tableKind = t
+	}
+}
+
+// createTablePrefix returns the start of the CREATE TABLE statements
+// of the index, up to the table name.
+func (lsh *SqlLsh) createTablePrefix() string {
+	s := "CREATE " + lsh.dialect.tableKinds[lsh.tableKind] + "TABLE"
+	if lsh.dialect.probeTables {
+		return s
+	}
+	return s + " IF NOT EXISTS"
+}
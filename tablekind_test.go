@@ -0,0 +1,34 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func Test_TableKindStrs(t *testing.T) {
+	for kind, prefix := range map[TableKind]string{
+		TablePermanent: "CREATE TABLE IF NOT EXISTS lshtable ",
+		TableUnlogged:  "CREATE UNLOGGED TABLE IF NOT EXISTS lshtable ",
+		TableTemporary: "CREATE TEMPORARY TABLE IF NOT EXISTS lshtable ",
+	} {
+		stmts := PostgresStatements(2, 2, "lshtable", WithTableKind(kind))
+		if !strings.HasPrefix(stmts.CreateTable, prefix) {
+			t.Errorf("Expected %q, got %s", prefix, stmts.CreateTable)
+		}
+	}
+}
+
+func Test_TableKindUnsupported(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	_, err = NewSqliteLsh(2, 2, "lshtable", db, WithTableKind(TableUnlogged))
+	if err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
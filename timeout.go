@@ -0,0 +1,95 @@
+package sqllsh
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// WithQueryTimeout stops the collision queries of Query, QueryIDs,
+// QueryIter, QueryBands, QueryPrefix and CountCandidates once they have
+// run for d, so that a Signature colliding with a large part of the
+// table cannot hold a connection indefinitely.
+// The stopped queries fail with an error wrapping
+// context.DeadlineExceeded, and are not retried.
+// On PostgreSQL, each query also runs in a read-only transaction
+// setting statement_timeout with SET LOCAL, so that the server stops
+// the query even if the cancellation sent by the driver is lost.
+func WithQueryTimeout(d time.Duration) Option {
+	return func(lsh *SqlLsh) {
+		lsh.queryTimeout = d
+	}
+}
+
+// querySession is the context, and the transaction setting the timeout
+// on the server if any, of one collision query.
+type querySession struct {
+	lsh    *SqlLsh
+	ctx    context.Context
+	cancel context.CancelFunc
+	tx     *sql.Tx
+}
+
+// beginQuery starts the session of a collision query, which must be
+// ended once its rows are closed.
+func (lsh *SqlLsh) beginQuery() (*querySession, error) {
+	s := &querySession{lsh: lsh, ctx: lsh.db.context(), cancel: func() {}}
+	if lsh.queryTimeout <= 0 {
+		return s, nil
+	}
+	s.ctx, s.cancel = context.WithTimeout(s.ctx, lsh.queryTimeout)
+	if lsh.dialect.timeoutFmt == "" {
+		return s, nil
+	}
+	// The transaction of the caller is left alone, as SET LOCAL would
+	// last until its end
+	db, ok := lsh.readDB().DB.(interface {
+		BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error)
+	})
+	if !ok {
+		return s, nil
+	}
+	tx, err := db.BeginTx(s.ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		s.cancel()
+		return nil, wrapErr("query", err)
+	}
+	ms := lsh.queryTimeout.Milliseconds()
+	if _, err := tx.ExecContext(s.ctx, fmt.Sprintf(lsh.dialect.timeoutFmt, ms)); err != nil {
+		tx.Rollback()
+		s.cancel()
+		return nil, wrapErr("query", err)
+	}
+	s.tx = tx
+	return s, nil
+}
+
+// query runs a read-only query like SqlLsh.read, within the session.
+func (s *querySession) query(stmt *sql.Stmt, query string, args ...interface{}) (*sql.Rows, error) {
+	if stmt == nil || len(s.lsh.replicas) > 0 {
+		query, args = s.lsh.inline(query, args)
+	}
+	if s.tx != nil {
+		if stmt != nil && len(s.lsh.replicas) == 0 {
+			return s.tx.StmtContext(s.ctx, stmt).QueryContext(s.ctx, args...)
+		}
+		return s.tx.QueryContext(s.ctx, query, args...)
+	}
+	if len(s.lsh.replicas) == 0 && stmt != nil {
+		return stmt.QueryContext(s.ctx, args...)
+	}
+	return s.lsh.readDB().QueryContext(s.ctx, query, args...)
+}
+
+// end ends the session, once the rows of its queries are closed.
+// It is safe to call end on a nil session.
+func (s *querySession) end() {
+	if s == nil {
+		return
+	}
+	if s.tx != nil {
+		s.tx.Rollback()
+	}
+	s.cancel()
+}
@@ -0,0 +1,66 @@
+package sqllsh
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_QueryTimeout(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db, WithQueryTimeout(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(100, 6)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	found, err := lsh.QueryIDs(sigs[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsID(found, 3) {
+		t.Errorf("Expected 3 in %v", found)
+	}
+	if n, err := lsh.CountCandidates(sigs[3]); err != nil || n != int64(len(found)) {
+		t.Errorf("Expected %d candidates, got %d, %v", len(found), n, err)
+	}
+	it, err := lsh.QueryIter(sigs[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if it.Err() != nil || n != len(found) {
+		t.Errorf("Expected %d IDs, got %d, %v", len(found), n, it.Err())
+	}
+	// The deadline passes before the queries start
+	lsh, err = NewSqliteLsh(2, 3, "lshtable", db, WithQueryTimeout(time.Nanosecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lsh.QueryIDs(sigs[3]); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if err := lsh.QueryBands(sigs[3], []int{0}, make(chan int, len(sigs))); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if _, err := lsh.CountCandidates(sigs[3]); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package sqllsh
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Tracer starts a span for each operation run by an index, so that the
+// operations show up in distributed traces.
+// See the sqllshotel package for a Tracer using OpenTelemetry.
+type Tracer interface {
+	// Start starts a span for op, a child of the span in ctx if any.
+	// op is the operation of Event with underscores instead of spaces,
+	// such as "batch_insert", and ctx is the one given to WithContext.
+	Start(ctx context.Context, op string) Span
+}
+
+// Span is an operation traced by a Tracer.
+type Span interface {
+	// SetInt sets an attribute of the span.
+	SetInt(key string, value int64)
+	// End ends the span, recording err if it is not nil.
+	End(err error)
+}
+
+// Span attributes set by the index.
+const (
+	AttrK    = "sqllsh.k"    // Hash key size
+	AttrL    = "sqllsh.l"    // Number of hash tables
+	AttrRows = "sqllsh.rows" // Rows of the operation, as in Event
+)
+
+// WithTracer traces the operations of the index with t.
+func WithTracer(t Tracer) Option {
+	return func(lsh *SqlLsh) {
+		lsh.tracer = t
+	}
+}
+
+// observe starts recording op with the logger, the tracer and the
+// latency recorder, and returns the function that ends the recording
+// with the number of rows of the operation and its error.
+func (lsh *SqlLsh) observe(op string) func(rows int64, err error) {
+	start := time.Now()
+	var span Span
+	if lsh.tracer != nil {
+		span = lsh.tracer.Start(lsh.db.context(), strings.ReplaceAll(op, " ", "_"))
+		span.SetInt(AttrK, int64(lsh.k))
+		span.SetInt(AttrL, int64(lsh.l))
+	}
+	return func(rows int64, err error) {
+		if span != nil {
+			span.SetInt(AttrRows, rows)
+			span.End(err)
+		}
+		if lsh.latency != nil && err == nil {
+			lsh.latency.record(op, time.Since(start))
+		}
+		lsh.log(op, rows, start, err)
+	}
+}
+
+// WithContext returns a view of the index running its statements with
+// ctx, so that they are canceled with ctx, and the spans of WithTracer
+// are children of the span in ctx.
+// The view shares the table and the query cache of lsh, and runs its
+// statements without preparing them.
+func (lsh *SqlLsh) WithContext(ctx context.Context) *SqlLsh {
+	view := *lsh
+	view.db.ctx = ctx
+	view.replicas = make([]dbConn, len(lsh.replicas))
+	for i, r := range lsh.replicas {
+		r.ctx = ctx
+		view.replicas[i] = r
+	}
+	view.adHoc = true
+	view.ownDB = false
+	view.insertStmt = nil
+	view.queryStmt = nil
+	view.countStmt = nil
+	view.scanStmt = nil
+	view.deleteStmt = nil
+	view.purgeStmt = nil
+	return &view
+}
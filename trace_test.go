@@ -0,0 +1,93 @@
+package sqllsh
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, op string) Span {
+	s := &testSpan{op: op, ctx: ctx, attrs: make(map[string]int64)}
+	t.spans = append(t.spans, s)
+	return s
+}
+
+type testSpan struct {
+	op    string
+	ctx   context.Context
+	attrs map[string]int64
+	ended bool
+	err   error
+}
+
+func (s *testSpan) SetInt(key string, value int64) {
+	s.attrs[key] = value
+}
+
+func (s *testSpan) End(err error) {
+	s.ended = true
+	s.err = err
+}
+
+func Test_Tracer(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	tracer := &testTracer{}
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db, WithTracer(tracer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(5, 6)
+	lsh.BatchInsert([]int{0, 1, 2, 3, 4}, sigs)
+	lsh.QueryIDs(sigs[0])
+	if len(tracer.spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(tracer.spans))
+	}
+	s := tracer.spans[0]
+	expected := map[string]int64{AttrK: 2, AttrL: 3, AttrRows: 5}
+	if s.op != "batch_insert" || !s.ended || !reflect.DeepEqual(s.attrs, expected) {
+		t.Errorf("Unexpected span %+v", s)
+	}
+	if s := tracer.spans[1]; s.op != "query" || s.attrs[AttrRows] != 1 {
+		t.Errorf("Unexpected span %+v", s)
+	}
+	removeTempFile(t, f)
+}
+
+type traceKey struct{}
+
+func Test_TracerContext(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	tracer := &testTracer{}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithTracer(tracer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), traceKey{}, "parent")
+	if err := lsh.WithContext(ctx).Insert(0, Signature{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if len(tracer.spans) != 1 || tracer.spans[0].ctx.Value(traceKey{}) != "parent" {
+		t.Errorf("Expected the span to start from the context of the caller")
+	}
+	// The statements run with the context too
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := lsh.WithContext(canceled).QueryIDs(Signature{1, 2, 3, 4}); err == nil {
+		t.Error("Expected an error with a canceled context")
+	}
+}
@@ -0,0 +1,70 @@
+package sqllsh
+
+import "database/sql"
+
+// InsertTx is like Insert, but inserts inside the transaction tx,
+// so the Signature can be inserted atomically with other rows of the
+// application.
+// The caller is responsible for committing or rolling back tx.
+// tx must belong to the database connection object of the index.
+// If the query cache is used, results cached before tx is committed
+// may not include the Signature.
+// The listeners of WithInsertListener are not called, since tx may
+// still be rolled back; the notifications of WithNotify are sent in tx.
+func (lsh *SqlLsh) InsertTx(tx *sql.Tx, id int, sig Signature) error {
+	sigs, err := lsh.beforeInsert("insert", []int{id}, []Signature{sig})
+	if err != nil {
+		return err
+	}
+	return lsh.insertTx(tx, id, sigs[0])
+}
+
+// insertTx is InsertTx after the insert hooks.
+func (lsh *SqlLsh) insertTx(tx *sql.Tx, id int, sig Signature) error {
+	if len(sig) != lsh.k*lsh.l {
+		return ErrSignatureSizeMismatch
+	}
+	lsh.bloom.add(lsh.k, sig)
+	if err := lsh.insertRow(tx, id, sig); err != nil {
+		return wrapErr("insert", err)
+	}
+	if err := lsh.afterInsert(tx, []int{id}, []Signature{sig}); err != nil {
+		return wrapErr("insert", err)
+	}
+	lsh.invalidate([]int{id}, []Signature{sig})
+	return nil
+}
+
+// BatchInsertTx is like BatchInsert, but inserts inside the
+// transaction tx, ignoring WithCommitSize.
+// See InsertTx for the requirements on tx.
+func (lsh *SqlLsh) BatchInsertTx(tx *sql.Tx, ids []int, sigs []Signature) error {
+	sigs, err := lsh.beforeInsert("batch insert", ids, sigs)
+	if err != nil {
+		return err
+	}
+	if err := validateBatch(ids, sigs, lsh.k*lsh.l); err != nil {
+		return err
+	}
+	lsh.bloom.add(lsh.k, sigs...)
+	for i := range sigs {
+		if err := lsh.insertRow(tx, ids[i], sigs[i]); err != nil {
+			return wrapErr("batch insert", err)
+		}
+	}
+	if err := lsh.afterInsert(tx, ids, sigs); err != nil {
+		return wrapErr("batch insert", err)
+	}
+	lsh.invalidate(ids, sigs)
+	return nil
+}
+
+// DeleteTx is like Delete, but deletes inside the transaction tx.
+// See InsertTx for the requirements on tx.
+func (lsh *SqlLsh) DeleteTx(tx *sql.Tx, id int) error {
+	if _, err := lsh.exec(tx, lsh.deleteStmt, lsh.deleteStr(), id); err != nil {
+		return wrapErr("delete", err)
+	}
+	lsh.sharedCache().invalidateID(id)
+	return nil
+}
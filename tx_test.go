@@ -0,0 +1,67 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_InsertTx(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Error(err)
+	}
+	if _, err := db.Exec("CREATE TABLE docs (id INTEGER PRIMARY KEY, body TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(3, 4)
+	// Rolled back together with the document
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("INSERT INTO docs VALUES (0, 'a')"); err != nil {
+		t.Error(err)
+	}
+	if err := lsh.InsertTx(tx, 0, sigs[0]); err != nil {
+		t.Error(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Error(err)
+	}
+	ids, err := lsh.QueryIDs(sigs[0])
+	if err != nil {
+		t.Error(err)
+	}
+	if len(ids) != 0 {
+		t.Error("Rolled back signature found")
+	}
+	// Committed together with the document
+	tx, err = db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.BatchInsertTx(tx, []int{1, 2}, sigs[1:]); err != nil {
+		t.Error(err)
+	}
+	if err := lsh.DeleteTx(tx, 2); err != nil {
+		t.Error(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Error(err)
+	}
+	for i, n := range []int{0, 1, 0} {
+		ids, err := lsh.QueryIDs(sigs[i])
+		if err != nil {
+			t.Error(err)
+		}
+		if len(ids) != n {
+			t.Errorf("Expected %d IDs for signature %d, got %d", n, i, len(ids))
+		}
+	}
+	removeTempFile(t, f)
+}
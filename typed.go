@@ -0,0 +1,239 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+)
+
+// ID is the type of the identifiers of a TypedLsh.
+type ID interface {
+	int64 | uint64 | string
+}
+
+// TypedLsh is an index with identifiers of type T, on top of an SqlLsh,
+// so applications do not need to map their identifiers to int.
+// int64 IDs are stored as they are, and uint64 IDs as the int64 with
+// the same bits.
+// string IDs are kept in the table <tableName>_keys with the integer
+// key stored in the index for them, which is their 64-bit FNV-1a hash,
+// or the next free key if another ID has the same hash, so that
+// different IDs never share a key.
+// The underlying SqlLsh must only be used through the TypedLsh.
+type TypedLsh[T ID] struct {
+	lsh *SqlLsh
+}
+
+// NewTypedLsh returns a TypedLsh using lsh, creating the table of the
+// IDs if T is string.
+// On 32-bit platforms only int64 and uint64 IDs fitting in an int are
+// supported, the others are rejected with ErrInvalidParameter.
+func NewTypedLsh[T ID](lsh *SqlLsh) (*TypedLsh[T], error) {
+	t := &TypedLsh[T]{lsh: lsh}
+	var zero T
+	if _, ok := interface{}(zero).(string); ok {
+		if lsh.dialect.ddl != nil {
+			return nil, ErrUnsupported
+		}
+		_, err := lsh.db.Exec(fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s (id %s PRIMARY KEY, name VARCHAR(255) NOT NULL UNIQUE)",
+			t.keysTable(), lsh.dialect.intType))
+		if err != nil {
+			return nil, wrapErr("create table", err)
+		}
+	}
+	return t, nil
+}
+
+// SqlLsh returns the underlying index, for the methods that do not
+// take or return IDs, such as Index.
+func (t *TypedLsh[T]) SqlLsh() *SqlLsh {
+	return t.lsh
+}
+
+// Insert appends a new Signature with id to the table.
+func (t *TypedLsh[T]) Insert(id T, sig Signature) error {
+	return t.BatchInsert([]T{id}, []Signature{sig})
+}
+
+// BatchInsert appends a list of Signatures to the table in one
+// transaction, see SqlLsh.BatchInsert.
+func (t *TypedLsh[T]) BatchInsert(ids []T, sigs []Signature) error {
+	if len(ids) != len(sigs) {
+		return ErrCountMismatch
+	}
+	tx, err := t.lsh.db.Begin()
+	if err != nil {
+		return wrapErr("batch insert", err)
+	}
+	keys := make([]int, len(ids))
+	for i, id := range ids {
+		if keys[i], err = t.putKey(tx.Tx, id); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := t.lsh.BatchInsertTx(tx.Tx, keys, sigs); err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return wrapErr("batch insert", err)
+	}
+	return nil
+}
+
+// Query returns the IDs of the candidates of sig.
+func (t *TypedLsh[T]) Query(sig Signature) ([]T, error) {
+	keys, err := t.lsh.QueryIDs(sig)
+	if err != nil {
+		return nil, err
+	}
+	return t.decode(keys)
+}
+
+// Delete removes the Signature with id from the table, and a string id
+// from the table of the IDs in the same transaction.
+func (t *TypedLsh[T]) Delete(id T) error {
+	name, ok := interface{}(id).(string)
+	if !ok {
+		key, err := t.encode(id)
+		if err != nil {
+			return err
+		}
+		return t.lsh.Delete(key)
+	}
+	tx, err := t.lsh.db.Begin()
+	if err != nil {
+		return wrapErr("delete", err)
+	}
+	key, err := t.lookupKey(tx.Tx, name)
+	if err == sql.ErrNoRows {
+		return wrapErr("delete", tx.Rollback())
+	}
+	if err != nil {
+		tx.Rollback()
+		return wrapErr("delete", err)
+	}
+	if err := t.lsh.DeleteTx(tx.Tx, key); err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = %s",
+		t.keysTable(), t.lsh.dialect.varFmt(0)), key)
+	if err != nil {
+		tx.Rollback()
+		return wrapErr("delete", err)
+	}
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return wrapErr("delete", err)
+	}
+	return nil
+}
+
+// encode returns the int stored for an int64 or uint64 id, or
+// ErrInvalidParameter if it does not fit in an int.
+func (t *TypedLsh[T]) encode(id T) (int, error) {
+	var v int64
+	switch id := interface{}(id).(type) {
+	case int64:
+		v = id
+	case uint64:
+		v = int64(id)
+	}
+	if int64(int(v)) != v {
+		return 0, ErrInvalidParameter
+	}
+	return int(v), nil
+}
+
+// decode returns the IDs stored as keys.
+func (t *TypedLsh[T]) decode(keys []int) ([]T, error) {
+	ids := make([]T, 0, len(keys))
+	var zero T
+	switch interface{}(zero).(type) {
+	case int64:
+		for _, key := range keys {
+			ids = append(ids, interface{}(int64(key)).(T))
+		}
+		return ids, nil
+	case uint64:
+		for _, key := range keys {
+			ids = append(ids, interface{}(uint64(int64(key))).(T))
+		}
+		return ids, nil
+	}
+	err := t.lsh.inChunks(len(keys), func(i int) interface{} { return keys[i] },
+		func(in string, args []interface{}) error {
+			rows, err := t.lsh.readDB().Query(fmt.Sprintf("SELECT name FROM %s WHERE id IN (%s)",
+				t.keysTable(), in), args...)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var name string
+				if err := rows.Scan(&name); err != nil {
+					return err
+				}
+				ids = append(ids, interface{}(name).(T))
+			}
+			return rows.Err()
+		})
+	if err != nil {
+		return nil, wrapErr("query", err)
+	}
+	return ids, nil
+}
+
+// keyProbes is the number of keys tried for a string ID, from its hash,
+// before giving up with ErrIDCollision.
+const keyProbes = 16
+
+// putKey returns the key stored for id, recording it inside tx for a
+// string ID without one.
+func (t *TypedLsh[T]) putKey(tx *sql.Tx, id T) (int, error) {
+	name, ok := interface{}(id).(string)
+	if !ok {
+		return t.encode(id)
+	}
+	key, err := t.lookupKey(tx, name)
+	if err != sql.ErrNoRows {
+		return key, wrapErr("batch insert", err)
+	}
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	key = int(int64(h.Sum64()))
+	for i := 0; i < keyProbes; i, key = i+1, key+1 {
+		var existing string
+		err := tx.QueryRow(fmt.Sprintf("SELECT name FROM %s WHERE id = %s",
+			t.keysTable(), t.lsh.dialect.varFmt(0)), key).Scan(&existing)
+		if err == nil {
+			// Taken by another ID with the same hash
+			continue
+		}
+		if err != sql.ErrNoRows {
+			return 0, wrapErr("batch insert", err)
+		}
+		_, err = tx.Exec(fmt.Sprintf("INSERT INTO %s (id, name) VALUES (%s, %s)",
+			t.keysTable(), t.lsh.dialect.varFmt(0), t.lsh.dialect.varFmt(1)), key, name)
+		return key, wrapErr("batch insert", err)
+	}
+	return 0, ErrIDCollision
+}
+
+// lookupKey returns the key of the string ID name read inside tx, or
+// sql.ErrNoRows if it has none.
+func (t *TypedLsh[T]) lookupKey(tx *sql.Tx, name string) (int, error) {
+	var key int
+	err := tx.QueryRow(fmt.Sprintf("SELECT id FROM %s WHERE name = %s",
+		t.keysTable(), t.lsh.dialect.varFmt(0)), name).Scan(&key)
+	return key, err
+}
+
+func (t *TypedLsh[T]) keysTable() string {
+	return t.lsh.tableName + "_keys"
+}
@@ -0,0 +1,131 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func Test_TypedLsh(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typed, err := NewTypedLsh[string](lsh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := Signature{1, 2, 3, 4, 5, 6}
+	if err := typed.BatchInsert([]string{"a", "b"}, []Signature{sig, sig}); err != nil {
+		t.Fatal(err)
+	}
+	if err := typed.Insert("c", Signature{0, 0, 0, 0, 0, 0}); err != nil {
+		t.Fatal(err)
+	}
+	found, err := typed.Query(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(found)
+	if !reflect.DeepEqual(found, []string{"a", "b"}) {
+		t.Errorf("Expected [a b], got %v", found)
+	}
+	if err := typed.Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+	found, err = typed.Query(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(found, []string{"b"}) {
+		t.Errorf("Expected [b], got %v", found)
+	}
+	removeTempFile(t, f)
+}
+
+func Test_TypedLshUint64(t *testing.T) {
+	lsh, err := NewMemoryLsh(2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lsh.Close()
+	typed, err := NewTypedLsh[uint64](lsh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := Signature{1, 2, 3, 4, 5, 6}
+	if err := typed.Insert(math.MaxUint64, sig); err != nil {
+		t.Fatal(err)
+	}
+	found, err := typed.Query(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(found, []uint64{math.MaxUint64}) {
+		t.Errorf("Expected [%d], got %v", uint64(math.MaxUint64), found)
+	}
+}
+
+func Test_TypedLshKeys(t *testing.T) {
+	f := creatTempFile(t)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Error(err)
+	}
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typed, err := NewTypedLsh[string](lsh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Take the key of "b" with another ID
+	h := fnv.New64a()
+	h.Write([]byte("b"))
+	key := int(int64(h.Sum64()))
+	if _, err := db.Exec("INSERT INTO lshtable_keys (id, name) VALUES (?, ?)", key, "x"); err != nil {
+		t.Fatal(err)
+	}
+	sig := Signature{1, 2, 3, 4, 5, 6}
+	if err := typed.BatchInsert([]string{"a", "b"}, []Signature{sig, sig}); err != nil {
+		t.Fatal(err)
+	}
+	found, err := typed.Query(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(found)
+	if !reflect.DeepEqual(found, []string{"a", "b"}) {
+		t.Errorf("Expected [a b], got %v", found)
+	}
+	if err := typed.Delete("b"); err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM lshtable_keys WHERE name = 'b'").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("Expected no key for b, got %d", count)
+	}
+	found, err = typed.Query(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(found, []string{"a"}) {
+		t.Errorf("Expected [a], got %v", found)
+	}
+	if err := typed.Delete("missing"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	removeTempFile(t, f)
+}
@@ -0,0 +1,56 @@
+package sqllsh
+
+import "math"
+
+// FromUint64 returns the Signature of a MinHash sketch of 64-bit hash
+// values, such as the fixed-length sketches of one-permutation hashing
+// with densification or SuperMinHash produced by other Go packages.
+// The sketch must have at least k*l hash values, and only the first
+// k*l are used, so a sketch can be shared by indexes of different
+// sizes.
+// The most significant bit of each hash value is cleared, as database
+// drivers cannot store unsigned integers above the signed 64-bit
+// range, so two hash values differing only by that bit collide.
+func (lsh *SqlLsh) FromUint64(sketch []uint64) (Signature, error) {
+	return sketchSignature(0, sketch, lsh.k*lsh.l)
+}
+
+// FromUint64s is like FromUint64 for many sketches.
+// The error of a short sketch is a *SignatureSizeError giving its
+// position.
+func (lsh *SqlLsh) FromUint64s(sketches [][]uint64) ([]Signature, error) {
+	sigs := make([]Signature, len(sketches))
+	for i, sketch := range sketches {
+		sig, err := sketchSignature(i, sketch, lsh.k*lsh.l)
+		if err != nil {
+			return nil, err
+		}
+		sigs[i] = sig
+	}
+	return sigs, nil
+}
+
+func sketchSignature(i int, sketch []uint64, size int) (Signature, error) {
+	if len(sketch) < size {
+		return nil, &SignatureSizeError{Index: i, Size: len(sketch), Want: size}
+	}
+	sig := make(Signature, size)
+	for j := range sig {
+		sig[j] = uint(sketch[j] & math.MaxInt64)
+	}
+	return sig, nil
+}
+
+// Bands splits sig into its l hash keys of k hash values, in the order
+// of the hash tables.
+// The hash keys share the memory of sig.
+func (lsh *SqlLsh) Bands(sig Signature) ([]Signature, error) {
+	if len(sig) != lsh.k*lsh.l {
+		return nil, ErrSignatureSizeMismatch
+	}
+	bands := make([]Signature, lsh.l)
+	for i := range bands {
+		bands[i] = sig[lsh.k*i : lsh.k*(i+1) : lsh.k*(i+1)]
+	}
+	return bands, nil
+}
@@ -0,0 +1,54 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func Test_FromUint64(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sketch := []uint64{math.MaxUint64, 1, 2, 3, 4}
+	sig, err := lsh.FromUint64(sketch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sig, Signature{math.MaxInt64, 1, 2, 3}) {
+		t.Errorf("Unexpected Signature %v", sig)
+	}
+	// The hash values above the signed range can be stored
+	if err := lsh.Insert(1, sig); err != nil {
+		t.Fatal(err)
+	}
+	found, err := lsh.QueryIDs(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsID(found, 1) {
+		t.Errorf("Expected 1 in %v", found)
+	}
+	_, err = lsh.FromUint64s([][]uint64{sketch, {1, 2}})
+	var sizeErr *SignatureSizeError
+	if !errors.As(err, &sizeErr) || sizeErr.Index != 1 {
+		t.Errorf("Expected a SignatureSizeError at 1, got %v", err)
+	}
+	bands, err := lsh.Bands(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(bands, []Signature{{math.MaxInt64, 1}, {2, 3}}) {
+		t.Errorf("Unexpected hash keys %v", bands)
+	}
+}
@@ -0,0 +1,146 @@
+package sqllsh
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Validate checks that the database is reachable, that the table has
+// the hash value columns of the index with integer types and no NULL
+// values, and that the index of every hash table exists, so that
+// services can check the index at startup instead of failing on the
+// first query.
+// It returns ErrTableExists if the hash value columns do not match k
+// and l, ErrSchemaMismatch if a hash value column is not an integer,
+// a NullValueError if a row has a NULL hash value, and ErrIndexMissing
+// if Index has not been run.
+// Looking for NULL values reads the whole table.
+// The indexes are not checked on databases without a catalog query.
+func (lsh *SqlLsh) Validate() error {
+	if err := lsh.db.Ping(); err != nil {
+		return wrapErr("validate", err)
+	}
+	if err := lsh.checkTable(); err != nil {
+		return err
+	}
+	if err := lsh.checkColumnTypes(); err != nil {
+		return err
+	}
+	if err := lsh.checkNulls(); err != nil {
+		return err
+	}
+	return lsh.checkIndexes()
+}
+
+// checkNulls returns a NullValueError for the first row found with a
+// NULL hash value, if any.
+func (lsh *SqlLsh) checkNulls() error {
+	cols := lsh.hashCols()
+	if lsh.packed && !lsh.keysOnly {
+		cols = append([]string{"sig"}, cols...)
+	}
+	conds := make([]string, len(cols))
+	for i, col := range cols {
+		conds[i] = col + " IS NULL"
+	}
+	query := fmt.Sprintf("SELECT id, %s FROM %s WHERE %s", strings.Join(cols, ","),
+		lsh.tableName, strings.Join(conds, " OR "))
+	if lsh.dialect.limitFmt != "" {
+		query += fmt.Sprintf(lsh.dialect.limitFmt, 1)
+	}
+	rows, err := lsh.db.Query(query)
+	if err != nil {
+		return wrapErr("validate", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return wrapErr("validate", rows.Err())
+	}
+	var id int
+	values := make([]interface{}, len(cols))
+	dest := []interface{}{&id}
+	for i := range values {
+		dest = append(dest, &values[i])
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return wrapErr("validate", err)
+	}
+	for i, v := range values {
+		if v == nil {
+			return &NullValueError{Id: id, Column: cols[i]}
+		}
+	}
+	return nil
+}
+
+// checkColumnTypes checks that the hash value columns are integers, if
+// the driver reports the column types.
+func (lsh *SqlLsh) checkColumnTypes() error {
+	rows, err := lsh.db.Query(fmt.Sprintf("SELECT %s FROM %s WHERE 1 = 0",
+		strings.Join(lsh.hashCols(), ","), lsh.tableName))
+	if err != nil {
+		return wrapErr("validate", err)
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return wrapErr("validate", err)
+	}
+	for _, t := range types {
+		name := strings.ToUpper(t.DatabaseTypeName())
+		if name != "" && !strings.Contains(name, "INT") && !strings.HasPrefix(name, "NUMBER") {
+			return ErrSchemaMismatch
+		}
+	}
+	return nil
+}
+
+// checkIndexes checks that the index of every hash table exists.
+func (lsh *SqlLsh) checkIndexes() error {
+	if lsh.dialect.indexesQuery == "" {
+		return nil
+	}
+	found, err := lsh.indexNames()
+	if err != nil {
+		return wrapErr("validate", err)
+	}
+	for i := 0; i < lsh.l; i++ {
+		if !found[strings.ToLower(lsh.indexName(i))] {
+			return ErrIndexMissing
+		}
+	}
+	return nil
+}
+
+// indexNames returns the lower case names of the indexes of the table.
+func (lsh *SqlLsh) indexNames() (map[string]bool, error) {
+	return lsh.indexNamesIn(lsh.db)
+}
+
+// indexNamesIn is like indexNames, running the query with q.
+func (lsh *SqlLsh) indexNamesIn(q rowQuerier) (map[string]bool, error) {
+	rows, err := q.Query(lsh.dialect.indexesQuery, lsh.tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	found := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		found[strings.ToLower(name)] = true
+	}
+	return found, rows.Err()
+}
+
+// hashCols returns the columns holding the hash keys of all the hash
+// tables.
+func (lsh *SqlLsh) hashCols() []string {
+	var cols []string
+	for i := 0; i < lsh.l; i++ {
+		cols = append(cols, lsh.bandCols(i)...)
+	}
+	return cols
+}
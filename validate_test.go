@@ -0,0 +1,91 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func Test_Validate(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	lsh, err := NewSqliteLsh(2, 3, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Validate(); err != ErrIndexMissing {
+		t.Errorf("Expected ErrIndexMissing, got %v", err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Validate(); err != nil {
+		t.Error(err)
+	}
+	if _, err := db.Exec("CREATE TABLE texttable (id INTEGER PRIMARY KEY, hv_0 TEXT, hv_1 TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	text := &SqlLsh{k: 1, l: 2, tableName: "texttable", db: dbConn{DB: db}, dialect: sqliteDialect}
+	if err := text.Validate(); err != ErrSchemaMismatch {
+		t.Errorf("Expected ErrSchemaMismatch, got %v", err)
+	}
+	other := &SqlLsh{k: 2, l: 2, tableName: "lshtable", db: dbConn{DB: db}, dialect: sqliteDialect}
+	if err := other.Validate(); err != ErrTableExists {
+		t.Errorf("Expected ErrTableExists, got %v", err)
+	}
+}
+
+func Test_NullHashValue(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// A table created with nullable hash value columns
+	if _, err := db.Exec(`CREATE TABLE lshtable (id INTEGER PRIMARY KEY,
+		hv_0 INTEGER, hv_1 INTEGER, hv_2 INTEGER, hv_3 INTEGER)`); err != nil {
+		t.Fatal(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Insert(1, Signature{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Validate(); err != nil {
+		t.Error(err)
+	}
+	if _, err := db.Exec("INSERT INTO lshtable VALUES (2, 1, NULL, 3, 4)"); err != nil {
+		t.Fatal(err)
+	}
+	var nullErr *NullValueError
+	err = lsh.Validate()
+	if !errors.As(err, &nullErr) || nullErr.Id != 2 || nullErr.Column != "hv_1" {
+		t.Errorf("Expected NULL in hv_1 of 2, got %v", err)
+	}
+	if _, err := lsh.Get(2); !errors.Is(err, ErrNullHashValue) {
+		t.Errorf("Expected ErrNullHashValue, got %v", err)
+	}
+	// The generated columns are NOT NULL
+	other, err := NewSqliteLsh(2, 2, "other", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO other VALUES (2, 1, NULL, 3, 4)"); err == nil {
+		t.Error("Expected the NULL to be rejected")
+	}
+	if err := other.Insert(1, nil); err != ErrSignatureSizeMismatch {
+		t.Errorf("Expected ErrSignatureSizeMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package sqllsh
+
+// PostgresVariant is a database using the PostgreSQL protocol and SQL,
+// which needs some statements of the index to be adjusted.
+type PostgresVariant int
+
+const (
+	// PostgresDefault is PostgreSQL itself, or a database fully
+	// compatible with it such as AlloyDB.
+	PostgresDefault PostgresVariant = iota
+	// PostgresYugabyte is YugabyteDB, which builds indexes online
+	// outside of transactions by default, only has LSM indexes,
+	// cannot rebuild indexes in place, and has no notifications.
+	PostgresYugabyte
+)
+
+// WithPostgresVariant adjusts a PostgreSQL-backed index for a
+// compatible database.
+// The constructor returns ErrUnsupported if the index does not use
+// PostgreSQL.
+func WithPostgresVariant(v PostgresVariant) Option {
+	return func(lsh *SqlLsh) {
+		lsh.variant = v
+	}
+}
+
+// yugabyteDialect adjusts the PostgreSQL dialect d for YugabyteDB.
+// Indexes are built online by default, which cannot be done in a
+// transaction block, so Index uses NONCONCURRENTLY, which is faster on
+// the freshly loaded tables Index is meant for.
+func yugabyteDialect(d dialect) dialect {
+	d.createIndexFmt = "CREATE INDEX NONCONCURRENTLY %s ON %s"
+	d.indexMethods = map[IndexType]string{IndexBTree: ""}
+	d.tableKinds = map[TableKind]string{TableTemporary: "TEMPORARY "}
+	d.reindexFmt = ""
+	d.notifyFmt = ""
+	d.variants = nil
+	return d
+}
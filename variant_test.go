@@ -0,0 +1,33 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func Test_PostgresVariant(t *testing.T) {
+	stmts := PostgresStatements(2, 2, "lshtable", WithPostgresVariant(PostgresYugabyte))
+	expected := "CREATE INDEX NONCONCURRENTLY lshtable_ht_1 ON lshtable (hv_2,hv_3)"
+	if stmts.CreateIndexes[1] != expected {
+		t.Errorf("Expected %q, got %q", expected, stmts.CreateIndexes[1])
+	}
+	// The Postgres dialect itself is unchanged
+	expected = "CREATE INDEX lshtable_ht_1 ON lshtable USING BTREE (hv_2,hv_3)"
+	if s := PostgresStatements(2, 2, "lshtable").CreateIndexes[1]; s != expected {
+		t.Errorf("Expected %q, got %q", expected, s)
+	}
+}
+
+func Test_PostgresVariantUnsupported(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	_, err = NewSqliteLsh(2, 2, "lshtable", db, WithPostgresVariant(PostgresYugabyte))
+	if err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
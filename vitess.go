@@ -0,0 +1,65 @@
+package sqllsh
+
+import "context"
+
+// vitessDialect is the MySQL dialect for Vitess and PlanetScale, which
+// run DDL statements outside of transactions, possibly as online
+// schema changes, and limit the size of transactions.
+var vitessDialect = dialect{
+	varFmt:         mysqlDialect.varFmt,
+	intType:        mysqlDialect.intType,
+	blobType:       mysqlDialect.blobType,
+	limitFmt:       mysqlDialect.limitFmt,
+	createIndexFmt: "ALTER TABLE %[2]s ADD INDEX %[1]s",
+	dropIndexFmt:   "ALTER TABLE %[2]s DROP INDEX %[1]s",
+	indexMethods:   mysqlDialect.indexMethods,
+	analyzeFmt:     mysqlDialect.analyzeFmt,
+	explainPrefix:  "EXPLAIN ",
+	plan:           PlanUnion,
+	limitDelete:    true,
+	maxBatch:       1000,
+	multiRowValues: 10000,
+	conflictClause: onDuplicateKeyClause,
+	ddl:            execDDL,
+	indexesQuery:   mysqlIndexesQuery,
+	tablesQuery:    mysqlTablesQuery,
+	maxParams:      65535,
+	maxIdent:       64,
+	implicitCommit: true,
+}
+
+// NewVitessLsh creates a new LSH index on a Vitess or PlanetScale
+// database, which uses the MySQL protocol.
+// The table and the indexes are created by one statement at a time
+// outside of transactions, so AddHashTables, Reshape and BulkLoad are
+// not supported.
+// The indexes are added with ALTER TABLE, so with an online DDL
+// strategy set in @@ddl_strategy, Index returns once the schema changes
+// are submitted, and Validate returns ErrIndexMissing until they are
+// complete.
+// Unless WithCommitSize is used, BatchInsert commits every 1000 rows,
+// with many rows per statement, to stay below the transaction limits
+// of Vitess, see BatchInsert.
+// The caller is responsible for closing the database connection
+// object.
+func NewVitessLsh(k, l int, tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	lsh, err := newSqlLsh(k, l, tableName, db, vitessDialect, opts)
+	return lsh, err
+}
+
+// OpenVitessLsh opens an existing Vitess-backed LSH index with its
+// recorded parameters, like OpenSqliteLsh.
+func OpenVitessLsh(tableName string, db DB, opts ...Option) (*SqlLsh, error) {
+	return openSqlLsh(tableName, db, vitessDialect, opts)
+}
+
+// execDDL runs the DDL statements one at a time outside of a
+// transaction.
+func execDDL(db DB, stmts []string) error {
+	for _, stmt := range stmts {
+		if _, err := db.ExecContext(context.Background(), stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
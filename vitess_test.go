@@ -0,0 +1,17 @@
+package sqllsh
+
+import "testing"
+
+func Test_VitessStatements(t *testing.T) {
+	lsh := &SqlLsh{k: 2, l: 2, tableName: "lshtable", dialect: vitessDialect}
+	expected := "ALTER TABLE lshtable ADD INDEX lshtable_ht_1 (hv_2,hv_3)"
+	if s := lsh.indexStr(1); s != expected {
+		t.Errorf("Expected %q, got %q", expected, s)
+	}
+	if n := lsh.batchSize(10000); n != 1000 {
+		t.Errorf("Expected 1000 rows per transaction, got %d", n)
+	}
+	if n := lsh.multiRowSize(); n != 2000 {
+		t.Errorf("Expected 2000 rows per insert, got %d", n)
+	}
+}
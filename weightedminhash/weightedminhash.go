@@ -0,0 +1,139 @@
+// Package weightedminhash computes weighted MinHash signatures with the
+// Improved Consistent Weighted Sampling (ICWS) of Ioffe, "Improved
+// Consistent Sampling, Weighted Minhash and L1 Sketching" (2010), for
+// indexing weighted sets, such as TF-weighted documents, in a sqllsh
+// index.
+// The probability that two signatures have the same value at a given
+// position is the weighted Jaccard similarity of their weighted sets,
+// the sum of the minimum weights over the sum of the maximum weights.
+package weightedminhash
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"math"
+
+	sqllsh "github.com/ekzhu/go-sql-lsh"
+)
+
+var (
+	// ErrEmpty is returned for a weighted set without positive weights.
+	ErrEmpty = errors.New("weighted set has no positive weight")
+	// ErrNegativeWeight is returned for a weighted set with a negative
+	// or NaN weight.
+	ErrNegativeWeight = errors.New("weighted set has a negative weight")
+)
+
+// Sample is the ICWS sample of a weighted set for one hash function:
+// the feature K it selects and the quantized weight T.
+// Two weighted sets collide on a hash function if they have the same
+// Sample for it.
+type Sample struct {
+	K uint64
+	T int64
+}
+
+// Hasher computes weighted MinHash signatures of a fixed size.
+// The random variables of each hash function and feature are derived
+// from the seed, so the features can be any uint64, such as token
+// hashes, and need not be known in advance.
+// Signatures are only comparable if computed with the same size and
+// seed.
+type Hasher struct {
+	size int
+	seed uint64
+}
+
+// New returns a Hasher of signatures with size hash values, which must
+// be k*l for a sqllsh index with hash keys of size k and l hash tables.
+func New(size int, seed uint64) *Hasher {
+	return &Hasher{size: size, seed: seed}
+}
+
+// Samples returns the ICWS samples of the weighted set, which maps
+// features to their weights.
+// Features with a zero weight are left out.
+func (h *Hasher) Samples(weights map[uint64]float64) ([]Sample, error) {
+	samples := make([]Sample, h.size)
+	minA := make([]float64, h.size)
+	for i := range minA {
+		minA[i] = math.Inf(1)
+	}
+	empty := true
+	for k, w := range weights {
+		if w < 0 || math.IsNaN(w) {
+			return nil, ErrNegativeWeight
+		}
+		if w == 0 {
+			continue
+		}
+		empty = false
+		lnW := math.Log(w)
+		for i := range samples {
+			r, lnC, beta := h.variables(i, k)
+			t := math.Floor(lnW/r + beta)
+			lnY := (t - beta) * r
+			lnA := lnC - lnY - r
+			if lnA < minA[i] {
+				minA[i] = lnA
+				samples[i] = Sample{K: k, T: int64(t)}
+			}
+		}
+	}
+	if empty {
+		return nil, ErrEmpty
+	}
+	return samples, nil
+}
+
+// Signature returns the weighted MinHash signature of the weighted set,
+// with each Sample encoded as one hash value by Encode.
+func (h *Hasher) Signature(weights map[uint64]float64) (sqllsh.Signature, error) {
+	samples, err := h.Samples(weights)
+	if err != nil {
+		return nil, err
+	}
+	return Encode(samples), nil
+}
+
+// Encode returns the Signature with one hash value per Sample: a 63-bit
+// FNV-1a hash of K and T, so that the values fit the signed 64-bit
+// columns of the index.
+// Different Samples may have the same hash value with a negligible
+// probability.
+func Encode(samples []Sample) sqllsh.Signature {
+	sig := make(sqllsh.Signature, len(samples))
+	buf := make([]byte, 16)
+	for i, s := range samples {
+		binary.BigEndian.PutUint64(buf, s.K)
+		binary.BigEndian.PutUint64(buf[8:], uint64(s.T))
+		f := fnv.New64a()
+		f.Write(buf)
+		sig[i] = uint(f.Sum64() >> 1)
+	}
+	return sig
+}
+
+// variables returns the random variables of hash function i for the
+// feature k: r and c following Gamma(2, 1), as r and ln c, and beta
+// following Uniform(0, 1).
+func (h *Hasher) variables(i int, k uint64) (float64, float64, float64) {
+	state := splitmix(splitmix(h.seed^uint64(i)) ^ k)
+	u := func() float64 {
+		state = splitmix(state)
+		return (float64(state>>11) + 0.5) / (1 << 53)
+	}
+	// A Gamma(2, 1) variable is the sum of two exponential ones
+	r := -math.Log(u()) - math.Log(u())
+	c := -math.Log(u()) - math.Log(u())
+	return r, math.Log(c), u()
+}
+
+// splitmix returns the next state of the SplitMix64 generator.
+func splitmix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
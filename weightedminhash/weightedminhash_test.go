@@ -0,0 +1,74 @@
+package weightedminhash
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func Test_Samples(t *testing.T) {
+	h := New(1024, 42)
+	a := map[uint64]float64{1: 3, 2: 1, 3: 0.5, 4: 2}
+	b := map[uint64]float64{1: 1, 2: 1, 3: 1.5, 5: 2}
+	// The sum of the minimums over the sum of the maximums
+	expected := (1 + 1 + 0.5) / (3 + 1 + 1.5 + 2 + 2)
+	sa, err := h.Samples(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb, err := h.Samples(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equal := 0
+	for i := range sa {
+		if sa[i] == sb[i] {
+			equal++
+		}
+	}
+	if estimate := float64(equal) / float64(len(sa)); math.Abs(estimate-expected) > 0.05 {
+		t.Errorf("Expected an estimate close to %f, got %f", expected, estimate)
+	}
+	// Scaling the weights keeps the features, with other quantized
+	// weights
+	scaled := make(map[uint64]float64)
+	for k, w := range a {
+		scaled[k] = 2 * w
+	}
+	ss, err := h.Samples(scaled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(sa, ss) {
+		t.Error("Expected different samples for scaled weights")
+	}
+	again, err := New(1024, 42).Samples(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sa, again) {
+		t.Error("Expected the same samples for the same seed")
+	}
+}
+
+func Test_Signature(t *testing.T) {
+	h := New(6, 1)
+	sig, err := h.Signature(map[uint64]float64{1: 1, 7: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != 6 {
+		t.Errorf("Expected 6 hash values, got %d", len(sig))
+	}
+	for _, v := range sig {
+		if uint64(v) > math.MaxInt64 {
+			t.Errorf("Expected a 63-bit hash value, got %d", v)
+		}
+	}
+	if _, err := h.Signature(map[uint64]float64{1: 0}); err != ErrEmpty {
+		t.Errorf("Expected ErrEmpty, got %v", err)
+	}
+	if _, err := h.Signature(map[uint64]float64{1: -1}); err != ErrNegativeWeight {
+		t.Errorf("Expected ErrNegativeWeight, got %v", err)
+	}
+}
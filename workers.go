@@ -0,0 +1,157 @@
+package sqllsh
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WithInsertWorkers makes BatchInsert and BulkLoad split the batch into
+// chunks inserted by n goroutines, each chunk in its own transaction on
+// its own connection, since a single connection cannot keep a large
+// database busy.
+// The chunks have the size given by WithCommitSize, or the batch is
+// split evenly between the workers.
+// A failed BatchInsert is then no longer all or nothing: the
+// *PartialInsertError returned counts the rows of the chunks committed
+// before the first failed one, but chunks after it may also have been
+// committed.
+// A BulkLoad interrupted with workers must be resumed with the same
+// number of workers and commit size, since each chunk is checkpointed
+// on its own.
+// Progress may be reported from several goroutines.
+// The constructor returns ErrUnsupported if n is more than 1 and the DB
+// is a *sql.Tx or a *sql.Conn.
+func WithInsertWorkers(n int) Option {
+	return func(lsh *SqlLsh) {
+		lsh.workers = n
+	}
+}
+
+// runChunks calls insert concurrently on lsh.workers goroutines for the
+// chunks of size rows of a batch of total rows, taking the chunks in
+// order and skipping those for which skip returns true.
+// No chunk is started after an error.
+// It returns the number of rows of the chunks done before the first
+// failed or skipped one, and the error of the first failed chunk.
+func (lsh *SqlLsh) runChunks(total, size int, skip func(begin int) bool,
+	insert func(begin, end int) error) (int, error) {
+	n := (total + size - 1) / size
+	errs := make([]error, n)
+	ran := make([]bool, n)
+	var next int64 = -1
+	var failed int32
+	var wg sync.WaitGroup
+	for w := 0; w < lsh.workers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				c := int(atomic.AddInt64(&next, 1))
+				if c >= n || atomic.LoadInt32(&failed) != 0 {
+					return
+				}
+				begin, end := c*size, (c+1)*size
+				if end > total {
+					end = total
+				}
+				if skip != nil && skip(begin) {
+					continue
+				}
+				ran[c] = true
+				if errs[c] = insert(begin, end); errs[c] != nil {
+					atomic.StoreInt32(&failed, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	committed := 0
+	for c := 0; c < n && ran[c] && errs[c] == nil; c++ {
+		committed = (c + 1) * size
+	}
+	if committed > total {
+		committed = total
+	}
+	for _, err := range errs {
+		if err != nil {
+			return committed, err
+		}
+	}
+	return committed, nil
+}
+
+// insertParallel is insertBatch with WithInsertWorkers.
+func (lsh *SqlLsh) insertParallel(ids []int, sigs []Signature, start time.Time) error {
+	size := lsh.batchSize((len(sigs) + lsh.workers - 1) / lsh.workers)
+	var done int64
+	committed, err := lsh.runChunks(len(sigs), size, nil, func(begin, end int) error {
+		err := lsh.retry("batch insert", func() error {
+			return lsh.batchInsert(ids, sigs, begin, end, start)
+		})
+		if err == nil {
+			lsh.report("batch insert", int(atomic.AddInt64(&done, int64(end-begin))),
+				len(sigs), start)
+		}
+		return err
+	})
+	if err != nil && committed > 0 {
+		return &PartialInsertError{Committed: committed, Err: err}
+	}
+	return err
+}
+
+// loadParallel is BulkLoad with WithInsertWorkers, checkpointing each
+// chunk under the name <name>@<position of its first row>.
+func (lsh *SqlLsh) loadParallel(name string, ids []int, sigs []Signature, start time.Time) error {
+	loaded, err := lsh.loadedChunks(name)
+	if err != nil {
+		return wrapErr("bulk load", err)
+	}
+	var done int64
+	for _, n := range loaded {
+		done += int64(n)
+	}
+	size := lsh.batchSize(bulkLoadBatchSize)
+	_, err = lsh.runChunks(len(sigs), size, func(begin int) bool {
+		_, ok := loaded[begin]
+		return ok
+	}, func(begin, end int) error {
+		if err := lsh.loadBatch(fmt.Sprintf("%s@%d", name, begin), ids, sigs, begin, end); err != nil {
+			return err
+		}
+		lsh.report("bulk load", int(atomic.AddInt64(&done, int64(end-begin))), len(sigs), start)
+		return nil
+	})
+	return err
+}
+
+// loadedChunks returns the number of rows of each chunk of the parallel
+// load name already committed, by the position of its first row.
+func (lsh *SqlLsh) loadedChunks(name string) (map[int]int, error) {
+	rows, err := lsh.db.Query(fmt.Sprintf("SELECT name, loaded FROM %s", lsh.checkpointTable()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	loaded := make(map[int]int)
+	for rows.Next() {
+		var chunk string
+		var end int
+		if err := rows.Scan(&chunk, &end); err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(chunk, name+"@") {
+			continue
+		}
+		begin, err := strconv.Atoi(chunk[len(name)+1:])
+		if err != nil {
+			continue
+		}
+		loaded[begin] = end - begin
+	}
+	return loaded, rows.Err()
+}
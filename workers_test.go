@@ -0,0 +1,99 @@
+package sqllsh
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func Test_InsertWorkers(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithInsertWorkers(4), WithCommitSize(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(1050, 4)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{0, 517, 1049} {
+		found, err := lsh.QueryIDs(sigs[id])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !containsID(found, id) {
+			t.Errorf("Expected %d in %v", id, found)
+		}
+	}
+	// The first chunk fails, so no rows are counted as committed
+	err = lsh.BatchInsert([]int{0, 2000}, randomSigs(2, 4))
+	if !errors.Is(err, ErrIDExists) {
+		t.Errorf("Expected ErrIDExists, got %v", err)
+	}
+	if _, ok := err.(*PartialInsertError); ok {
+		t.Errorf("Expected no committed rows, got %v", err)
+	}
+}
+
+func Test_BulkLoadWorkers(t *testing.T) {
+	f := creatTempFile(t)
+	defer removeTempFile(t, f)
+	db, err := sql.Open("sqlite3", f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lsh, err := NewSqliteLsh(2, 2, "lshtable", db, WithInsertWorkers(3), WithCommitSize(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(1000, 4)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	if err := lsh.BulkLoad("load", ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	// Simulate an interruption that lost two chunks
+	for _, q := range []string{
+		"DELETE FROM lshtable WHERE (id >= 300 AND id < 400) OR id >= 900",
+		"DELETE FROM lshtable_checkpoint WHERE name IN ('load@300', 'load@900')",
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := lsh.BulkLoad("load", ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM lshtable").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != len(sigs) {
+		t.Errorf("Expected %d entries, got %d", len(sigs), count)
+	}
+}
+
+func Test_RunChunks(t *testing.T) {
+	lsh := &SqlLsh{workers: 1}
+	fail := errors.New("fail")
+	committed, err := lsh.runChunks(25, 10, nil, func(begin, end int) error {
+		if begin == 20 {
+			return fail
+		}
+		return nil
+	})
+	if committed != 20 || err != fail {
+		t.Errorf("Expected 20 rows and the error, got %d and %v", committed, err)
+	}
+}
@@ -0,0 +1,75 @@
+//go:build yugabyte
+
+package sqllsh
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+)
+
+// Test_YugabyteLsh runs against the YugabyteDB database given by the
+// connection string in SQLLSH_YUGABYTE_DSN, such as
+// "postgres://yugabyte@127.0.0.1:5433/yugabyte?sslmode=disable".
+func Test_YugabyteLsh(t *testing.T) {
+	dsn := os.Getenv("SQLLSH_YUGABYTE_DSN")
+	if dsn == "" {
+		t.Skip("SQLLSH_YUGABYTE_DSN is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, table := range []string{"lshtable", "lshtable_meta"} {
+		if _, err := db.Exec("DROP TABLE IF EXISTS " + table); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lsh, err := NewPostgresLsh(2, 3, "lshtable", db, WithPostgresVariant(PostgresYugabyte),
+		WithConflict(ConflictReplace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := randomSigs(1000, 6)
+	ids := make([]int, len(sigs))
+	for i := range ids {
+		ids[i] = i
+	}
+	if err := lsh.BatchInsert(ids, sigs); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Index(); err != nil {
+		t.Fatal(err)
+	}
+	if err := lsh.Validate(); err != nil {
+		t.Error(err)
+	}
+	found, err := lsh.QueryIDs(sigs[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsID(found, 3) {
+		t.Errorf("Expected 3 in %v", found)
+	}
+	it, err := lsh.ScanIter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for it.Next() {
+		e := it.Value()
+		if !sameSig(e.Signature, sigs[e.Id]) {
+			t.Errorf("Expected %v for %d, got %v", sigs[e.Id], e.Id, e.Signature)
+		}
+		n++
+	}
+	if err := it.Err(); err != nil || n != len(sigs) {
+		t.Errorf("Expected %d entries, got %d, %v", len(sigs), n, err)
+	}
+	if err := lsh.Reindex(); err != nil {
+		t.Error(err)
+	}
+}